@@ -46,6 +46,16 @@ type InstallationSpec struct {
 	// +optional
 	Registry string `json:"registry,omitempty"`
 
+	// RegistryMirrors is an ordered list of registries that mirror Registry. Each value must end with a slash
+	// character (`/`). Mirrors are ignored when Registry is not set.
+	// +optional
+	RegistryMirrors []string `json:"registryMirrors,omitempty"`
+
+	// PreferredRegistryMirror selects one of RegistryMirrors to pull all component images from instead of Registry,
+	// for example while Registry is unavailable. When not set, images are pulled from Registry.
+	// +optional
+	PreferredRegistryMirror string `json:"preferredRegistryMirror,omitempty"`
+
 	// ImagePath allows for the path part of an image to be specified. If specified
 	// then the specified value will be used as the image path for each image. If not specified
 	// or empty, the default for each image will be used.
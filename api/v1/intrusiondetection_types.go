@@ -24,15 +24,531 @@ import (
 // IntrusionDetectionSpec defines the desired state of Tigera intrusion detection capabilities.
 type IntrusionDetectionSpec struct {
 	// ComponentResources can be used to customize the resource requirements for each component.
-	// Only DeepPacketInspection is supported for this spec.
+	// DeepPacketInspection and IntrusionDetectionController are supported for this spec.
 	// +optional
 	ComponentResources []IntrusionDetectionComponentResource `json:"componentResources,omitempty"`
 
+	// PruneUnrecognizedComponentResources removes the ComponentResources entries of components that the operator
+	// doesn't recognize, e.g. of a component that was renamed or removed. Otherwise they are left in place and reported
+	// in the UnrecognizedComponentResources status condition.
+	// Default: false
+	// +optional
+	PruneUnrecognizedComponentResources *bool `json:"pruneUnrecognizedComponentResources,omitempty"`
+
+	// ComponentPodAnnotations adds annotations to the pods of individual components, e.g. sidecar.istio.io/inject to
+	// control the Istio sidecar injection of each component. The annotations that the operator sets itself take
+	// precedence. DeepPacketInspection and IntrusionDetectionController are supported for this spec.
+	// +optional
+	ComponentPodAnnotations []IntrusionDetectionComponentPodAnnotations `json:"componentPodAnnotations,omitempty"`
+
+	// CopiedSecretMetadata adds labels and annotations to the secrets that the operator copies into the namespace of
+	// the intrusion detection components, e.g. so that secret scanning or rotation tooling can find them. The labels
+	// and annotations that the operator sets itself take precedence.
+	// +optional
+	CopiedSecretMetadata *Metadata `json:"copiedSecretMetadata,omitempty"`
+
 	// AnomalyDetection is now deprecated, and configuring it has no effect.
 	// +optional
 	AnomalyDetection AnomalyDetectionSpec `json:"anomalyDetection,omitempty"`
+
+	// DPITerminationGracePeriodSeconds is the optional duration in seconds the DeepPacketInspection pods need to
+	// terminate gracefully, giving them time to flush captured traffic on shutdown.
+	// If unset, the Kubernetes default of 30 seconds applies.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	DPITerminationGracePeriodSeconds *int64 `json:"dpiTerminationGracePeriodSeconds,omitempty"`
+
+	// DPISeccompProfile is the seccomp profile of the DeepPacketInspection pods. Set a Localhost profile when the
+	// runtime default profile blocks system calls that packet capture needs on the nodes.
+	// Default: RuntimeDefault
+	// +optional
+	DPISeccompProfile *corev1.SeccompProfile `json:"dpiSeccompProfile,omitempty"`
+
+	// DPILogSeverity is the logging level of DeepPacketInspection. When unset, DeepPacketInspection logs at its
+	// built-in level.
+	// +optional
+	// +kubebuilder:validation:Enum=Trace;Debug;Info;Warn;Error;Fatal
+	DPILogSeverity *LogLevel `json:"dpiLogSeverity,omitempty"`
+
+	// DPISysctls are the sysctls set in the security context of the DeepPacketInspection pods. Only the sysctls that
+	// Kubernetes considers safe outside the network namespace are accepted: since the pods use the host network, the
+	// kubelet rejects the sysctls of the network namespace, and the operator does not set sysctls on the nodes.
+	// +optional
+	DPISysctls []corev1.Sysctl `json:"dpiSysctls,omitempty"`
+
+	// DPIRuntimeClassName is the name of the RuntimeClass the DeepPacketInspection pods run with. Set this on clusters
+	// that sandbox pods by default, since DeepPacketInspection needs raw access to the host network.
+	// If unset, the pods use the default runtime of the cluster.
+	// +optional
+	DPIRuntimeClassName *string `json:"dpiRuntimeClassName,omitempty"`
+
+	// DPIPriorityClassName is the name of the PriorityClass of the DeepPacketInspection pods, independent of the
+	// priority of the intrusion detection Deployments. Set this to keep packet capture running on nodes that come under
+	// resource pressure. The PriorityClass must exist.
+	// If unset, the pods have the default priority of the cluster.
+	// +optional
+	DPIPriorityClassName *string `json:"dpiPriorityClassName,omitempty"`
+
+	// DPICPUPinning runs DeepPacketInspection with the Guaranteed QoS class, so that the static policy of the kubelet CPU
+	// manager pins it to dedicated CPUs, and adds the annotations that disable CPU load balancing and CPU quota for its
+	// pods on runtimes that honor them, such as CRI-O with a performance profile RuntimeClass set in
+	// DPIRuntimeClassName. The CPU requests and limits of DeepPacketInspection must then be equal whole numbers of
+	// CPUs. Its memory request is raised to its memory limit.
+	// Default: false
+	// +optional
+	DPICPUPinning *bool `json:"dpiCPUPinning,omitempty"`
+
+	// DPIWaitForTypha adds an init container to the DeepPacketInspection pods that waits until Typha accepts
+	// connections, so that DeepPacketInspection doesn't crash loop on new clusters while Typha is starting up. Disable
+	// this on clusters that run without Typha.
+	// Default: true
+	// +optional
+	DPIWaitForTypha *bool `json:"dpiWaitForTypha,omitempty"`
+
+	// FSGroup is the supplemental group that owns the volumes mounted into the intrusion-detection-controller and
+	// installer pods, so that their non-root containers can read them on clusters where volumes are owned by root.
+	// Default: 10001, the group the containers run as
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	FSGroup *int64 `json:"fsGroup,omitempty"`
+
+	// PodSecurityStandard is the level of the Pod Security Standards that the namespace of the intrusion detection
+	// components enforces, and that the operator checks the intrusion detection pods against before it renders them.
+	// Syslog forwarding of security events needs Privileged, since the intrusion-detection-controller then writes the
+	// events to a host path as root. Restricted also limits ControllerVolumes to the volume types that restricted pods
+	// may use. DeepPacketInspection runs in a namespace of its own, which is not affected.
+	// Default: Restricted, or Privileged when syslog forwarding of security events is enabled
+	// +optional
+	// +kubebuilder:validation:Enum=Privileged;Baseline;Restricted
+	PodSecurityStandard *PodSecurityStandard `json:"podSecurityStandard,omitempty"`
+
+	// DNSPolicy is the DNS policy of the intrusion-detection-controller and installer pods. Set it to None to resolve
+	// names with DNSConfig alone.
+	// Default: ClusterFirst
+	// +optional
+	// +kubebuilder:validation:Enum=ClusterFirstWithHostNet;ClusterFirst;Default;None
+	DNSPolicy corev1.DNSPolicy `json:"dnsPolicy,omitempty"`
+
+	// DNSConfig is merged into the DNS configuration that DNSPolicy generates for the intrusion-detection-controller
+	// and installer pods, e.g. to lower ndots so that the names of an external Elasticsearch are not first looked up in
+	// every search domain.
+	// +optional
+	DNSConfig *corev1.PodDNSConfig `json:"dnsConfig,omitempty"`
+
+	// ControllerReplicas is the number of intrusion-detection-controller replicas.
+	// Default: 1
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ControllerReplicas *int32 `json:"controllerReplicas,omitempty"`
+
+	// SkipPodDisruptionBudget stops the operator from rendering a PodDisruptionBudget for the
+	// intrusion-detection-controller. A PodDisruptionBudget that keeps one replica available during voluntary
+	// disruptions, such as node drains, is otherwise rendered whenever there is more than one replica. With a single
+	// replica, none is rendered so that node drains are never blocked.
+	// Default: false
+	// +optional
+	SkipPodDisruptionBudget *bool `json:"skipPodDisruptionBudget,omitempty"`
+
+	// SkipInstallerJob disables the Job that installs the intrusion detection indices and templates into Elasticsearch.
+	// Set this when the indices are provisioned outside of the operator. Any existing installer Job is removed. The
+	// installer is also removed when the operator is configured for an external Elasticsearch.
+	// Default: false
+	// +optional
+	SkipInstallerJob *bool `json:"skipInstallerJob,omitempty"`
+
+	// SkipIndexCreation stops the installer Job from creating the initial intrusion detection indices, while it still
+	// sets up their rollover policies unless SkipIndexRollover is also set. Set this when the indices are created
+	// outside of the operator.
+	// Default: false
+	// +optional
+	SkipIndexCreation *bool `json:"skipIndexCreation,omitempty"`
+
+	// SkipIndexRollover stops the installer Job from setting up the rollover and lifecycle policies of the intrusion
+	// detection indices, while it still creates the indices unless SkipIndexCreation is also set. When both are set,
+	// the installer has nothing to do and is removed, as with SkipInstallerJob.
+	// Default: false
+	// +optional
+	SkipIndexRollover *bool `json:"skipIndexRollover,omitempty"`
+
+	// IndexLifecycle configures the phases of the lifecycle policies that the installer Job sets up for the intrusion
+	// detection indices, e.g. to move them to the warm and cold data tiers on a schedule of its own. The phases and
+	// actions that are not configured keep the installer defaults. It has no effect when SkipIndexRollover is set.
+	// +optional
+	IndexLifecycle *IntrusionDetectionIndexLifecycle `json:"indexLifecycle,omitempty"`
+
+	// InstallerJobTTLSecondsAfterFinished is how long the installer Job is kept after it has finished before it is
+	// deleted. The installer retries until it succeeds, so a failing Job is never finished and is kept for debugging.
+	// Once the installer Job has completed, the operator doesn't recreate it after it is deleted until the
+	// configuration of the installer changes.
+	// Default: 86400
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	InstallerJobTTLSecondsAfterFinished *int32 `json:"installerJobTTLSecondsAfterFinished,omitempty"`
+
+	// InstallerSchedule runs the installer periodically as a CronJob on the given cron schedule, instead of once as a
+	// Job, so that indices and templates that were removed from Elasticsearch are re-created. Each run is kept for
+	// InstallerJobTTLSecondsAfterFinished, and a run is skipped while the previous one has not finished.
+	// Default: the installer runs once as a Job.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	InstallerSchedule string `json:"installerSchedule,omitempty"`
+
+	// ScaleDPIResourceDefaults sets the default DeepPacketInspection resource requirements to a share of the allocatable
+	// resources of the smallest Linux node, within fixed bounds, instead of using fixed values. The defaults follow
+	// the nodes and are not saved in ComponentResources. It only has an effect when ComponentResources has no
+	// DeepPacketInspection entry.
+	// Default: false
+	// +optional
+	ScaleDPIResourceDefaults *bool `json:"scaleDPIResourceDefaults,omitempty"`
+
+	// DPIPacketBufferSize is the size of the buffer used by DeepPacketInspection to capture packets, expressed as
+	// a quantity of bytes, e.g. 64Mi. If unset, the DeepPacketInspection default is used.
+	// +optional
+	DPIPacketBufferSize string `json:"dpiPacketBufferSize,omitempty"`
+
+	// DPIInterfaceRegex is a regular expression that selects the names of the interfaces DeepPacketInspection
+	// captures packets on, e.g. ^eth[0-9]+$. If unset, packets are captured on all interfaces.
+	// +optional
+	DPIInterfaceRegex *string `json:"dpiInterfaceRegex,omitempty"`
+
+	// DPIBPFFilter is a BPF filter expression, in the syntax of tcpdump, that restricts the packets
+	// DeepPacketInspection captures, e.g. "tcp port 80 or tcp port 443". If unset, all packets are captured.
+	// +optional
+	DPIBPFFilter *string `json:"dpiBPFFilter,omitempty"`
+
+	// DPIWorkerThreads is the number of threads each DeepPacketInspection pod processes captured packets with. Raise
+	// it on nodes with a high throughput, along with the CPU resources of DeepPacketInspection. If unset, the
+	// DeepPacketInspection default is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	DPIWorkerThreads *int32 `json:"dpiWorkerThreads,omitempty"`
+
+	// DPINamespaceSelector restricts the DeepPacketInspection resources that the operator acts on to those in the
+	// namespaces it selects, e.g. to the namespaces of the tenants that DeepPacketInspection is enabled for. The
+	// DeepPacketInspection pods are only deployed when one of the selected resources exists. If unset, the
+	// DeepPacketInspection resources in all namespaces are considered.
+	// +optional
+	DPINamespaceSelector *metav1.LabelSelector `json:"dpiNamespaceSelector,omitempty"`
+
+	// DPIProfiles run DeepPacketInspection with settings of their own on the nodes that each of them selects, e.g. with
+	// more worker threads on core nodes than on edge nodes. Each profile is rendered as a DaemonSet of its own, and the
+	// default DeepPacketInspection DaemonSet, with the settings of the IntrusionDetection, runs on the nodes that no
+	// profile selects. A node should be selected by one profile at most.
+	// +optional
+	DPIProfiles []DPIProfile `json:"dpiProfiles,omitempty"`
+
+	// ImmutableFieldChangePolicy controls what the operator does when an update to one of the intrusion detection
+	// resources changes a field that cannot be modified, such as a Job selector. Error reports the failed update,
+	// while Recreate deletes the resource and creates it again with the desired state. A resource is only recreated if
+	// it wasn't modified since the operator read it, and never if deleting it would lose data, as for a
+	// PersistentVolumeClaim. It is created again once the deleted resource is gone, which may take a later reconcile
+	// when finalizers hold it back.
+	// Default: Error
+	// +optional
+	// +kubebuilder:validation:Enum=Error;Recreate
+	ImmutableFieldChangePolicy *ImmutableFieldChangePolicy `json:"immutableFieldChangePolicy,omitempty"`
+
+	// ExternalElasticsearchCABundle references a key in a ConfigMap in the tigera-operator namespace that holds one or
+	// more PEM encoded CA certificates. Intrusion detection trusts these certificates when it connects to an
+	// Elasticsearch or Kibana that is managed outside of the operator. It has no effect otherwise.
+	// +optional
+	ExternalElasticsearchCABundle *corev1.ConfigMapKeySelector `json:"externalElasticsearchCABundle,omitempty"`
+
+	// InstallerCABundles references keys in ConfigMaps in the tigera-operator namespace that each hold one or more PEM
+	// encoded CA certificates. The installer trusts these certificates in addition to the operator managed CA bundle,
+	// e.g. for a service between the installer and Elasticsearch that presents a certificate signed by another CA.
+	// +optional
+	InstallerCABundles []corev1.ConfigMapKeySelector `json:"installerCABundles,omitempty"`
+
+	// PreferIPv6 makes the intrusion-detection-controller and the installer prefer the IPv6 addresses of Elasticsearch
+	// and Kibana when their names resolve to both IPv4 and IPv6 addresses, e.g. on dual-stack clusters that reach an
+	// IPv6-only external Elasticsearch. By default the IPv4 addresses are preferred.
+	// Default: false
+	// +optional
+	PreferIPv6 *bool `json:"preferIPv6,omitempty"`
+
+	// DPITyphaCABundle references a key in a ConfigMap in the tigera-operator namespace that holds one or more PEM
+	// encoded CA certificates. DeepPacketInspection trusts these certificates, instead of the operator managed CA
+	// bundle, when it connects to Typha. Set this when Typha presents a certificate that is signed by a custom CA.
+	// +optional
+	DPITyphaCABundle *corev1.ConfigMapKeySelector `json:"dpiTyphaCABundle,omitempty"`
+
+	// ElasticsearchVersion pins the intrusion detection installer to a version of Elasticsearch, as a major.minor or
+	// major.minor.patch version, e.g. 7.17. The installer only applies the index templates that are compatible with
+	// this version, and the operator does not run it if the operator managed Elasticsearch is running a different
+	// version. If unset, the installer detects the version of the running Elasticsearch.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+\.[0-9]+(\.[0-9]+)?$`
+	ElasticsearchVersion string `json:"elasticsearchVersion,omitempty"`
+
+	// MissingSecretGracePeriodSeconds is how long the operator waits for the Elasticsearch user secrets in the
+	// tigera-operator namespace to be created before it reports them as missing, e.g. while a tool that syncs them from
+	// an external secret store catches up. Within the grace period, intrusion detection is reported as progressing
+	// rather than degraded.
+	// Default: 0
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	MissingSecretGracePeriodSeconds *int32 `json:"missingSecretGracePeriodSeconds,omitempty"`
+
+	// LicenseLossGracePeriodSeconds is how long the operator keeps running intrusion detection components after the
+	// license stops granting the intrusion detection feature, e.g. during a brief license sync problem, before it removes
+	// them. Within the grace period, intrusion detection is reported as degraded. The time the feature was lost is
+	// kept in status.licenseFeatureLostTime, so that the grace period carries over operator restarts.
+	// Default: 0
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	LicenseLossGracePeriodSeconds *int32 `json:"licenseLossGracePeriodSeconds,omitempty"`
+
+	// ValidateElasticsearchCredentials makes the operator test the credentials in the Elasticsearch user secrets
+	// against Elasticsearch before it rolls them out to the intrusion detection components, e.g. after they are
+	// rotated. If Elasticsearch does not accept them, intrusion detection is reported as degraded and the running
+	// components are left unchanged. Not supported on managed clusters.
+	// Default: false
+	// +optional
+	ValidateElasticsearchCredentials *bool `json:"validateElasticsearchCredentials,omitempty"`
+
+	// CheckElasticsearchConnectivity makes the operator check that Elasticsearch, or the external Elasticsearch when one
+	// is configured, can be reached with the credentials of the intrusion-detection-controller, and report the outcome
+	// in the ElasticsearchReachable condition of the IntrusionDetection. The check runs at most once per sync period of
+	// the operator, or every 5 minutes when it has none, and whenever the IntrusionDetection changes. An unreachable
+	// Elasticsearch does not degrade intrusion detection. Not supported on managed clusters.
+	// Default: false
+	// +optional
+	CheckElasticsearchConnectivity *bool `json:"checkElasticsearchConnectivity,omitempty"`
+
+	// ElasticsearchQueryTimeout is how long the intrusion-detection-controller waits for an Elasticsearch query to
+	// complete, as a duration, e.g. 90s. Raise it when queries over large datasets time out. If unset, the
+	// intrusion-detection-controller default is used.
+	// +optional
+	ElasticsearchQueryTimeout string `json:"elasticsearchQueryTimeout,omitempty"`
+
+	// ElasticsearchBulkSize is the number of documents the intrusion-detection-controller writes to Elasticsearch in
+	// each bulk request. Raise it on clusters with a high volume of events to improve the indexing throughput. If
+	// unset, the intrusion-detection-controller default is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10000
+	ElasticsearchBulkSize *int32 `json:"elasticsearchBulkSize,omitempty"`
+
+	// ServiceAccounts references existing ServiceAccounts for the intrusion detection workloads to run as, e.g. so that
+	// they can be tied to cloud provider IAM roles. The operator does not create its own ServiceAccount for a workload
+	// that references an existing one. The referenced ServiceAccounts must exist.
+	// +optional
+	ServiceAccounts *IntrusionDetectionServiceAccounts `json:"serviceAccounts,omitempty"`
+
+	// AutomountServiceAccountTokens controls whether the API token of their ServiceAccount is mounted into the pods of
+	// the intrusion detection workloads. A workload that talks to the API server without a mounted token needs one
+	// from a volume of its own, e.g. a projected volume in ControllerVolumes.
+	// +optional
+	AutomountServiceAccountTokens *IntrusionDetectionAutomountServiceAccountTokens `json:"automountServiceAccountTokens,omitempty"`
+
+	// ControllerEnv is a list of additional environment variables to set on the intrusion-detection-controller
+	// container. Variables that are managed by the operator take precedence over entries with the same name.
+	// +optional
+	ControllerEnv []corev1.EnvVar `json:"controllerEnv,omitempty"`
+
+	// ControllerExtraArgs is a list of arguments that are passed, in order, to the entrypoint of the
+	// intrusion-detection-controller container, e.g. to enable debug flags. The entrypoint itself is not changed, but
+	// like any container args, the list replaces the default arguments (CMD) of the image, so it must repeat the ones
+	// that are still needed. Flags for settings that are managed by the operator through the environment of the
+	// container, e.g. --linseed-url for LINSEED_URL, are rejected.
+	// +optional
+	ControllerExtraArgs []string `json:"controllerExtraArgs,omitempty"`
+
+	// ControllerVolumes is a list of additional volumes for the intrusion-detection-controller pod, e.g. a
+	// PersistentVolumeClaim that holds a GeoIP database. The names must not conflict with the volumes that are
+	// managed by the operator.
+	// +optional
+	ControllerVolumes []corev1.Volume `json:"controllerVolumes,omitempty"`
+
+	// ControllerVolumeMounts is a list of additional volume mounts for the intrusion-detection-controller container.
+	// The names and mount paths must not conflict with the volume mounts that are managed by the operator.
+	// +optional
+	ControllerVolumeMounts []corev1.VolumeMount `json:"controllerVolumeMounts,omitempty"`
+
+	// ControllerGOMAXPROCSFromCPULimit sets GOMAXPROCS on the intrusion-detection-controller container to its CPU
+	// limit, rounded down to a whole number of CPUs, so that the Go runtime doesn't schedule more threads than the
+	// limit allows. It has no effect when the container has no CPU limit.
+	// Default: true
+	// +optional
+	ControllerGOMAXPROCSFromCPULimit *bool `json:"controllerGOMAXPROCSFromCPULimit,omitempty"`
+
+	// ControllerMetricsTLS makes the intrusion-detection-controller serve its metrics endpoint over TLS, with a
+	// certificate that the operator issues, rather than in cleartext. It is only supported on the default
+	// IntrusionDetection.
+	// Default: false
+	// +optional
+	ControllerMetricsTLS *bool `json:"controllerMetricsTLS,omitempty"`
+
+	// ControllerTerminationGracePeriodSeconds is how long the intrusion-detection-controller pods are given to shut
+	// down, e.g. during a rolling update, so that they can flush their in-flight detection state to Elasticsearch. It
+	// includes the time that ControllerPreStop takes.
+	// If unset, the Kubernetes default of 30 seconds is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	ControllerTerminationGracePeriodSeconds *int64 `json:"controllerTerminationGracePeriodSeconds,omitempty"`
+
+	// ControllerPreStop is a hook that is run in the intrusion-detection-controller container before it is sent the
+	// termination signal, e.g. a command that waits for the controller to flush its state to Elasticsearch.
+	// +optional
+	ControllerPreStop *corev1.LifecycleHandler `json:"controllerPreStop,omitempty"`
+
+	// ProvidedCertificates makes the operator use the key pairs in the intrusion-detection-tls,
+	// deep-packet-inspection-tls and, when ControllerMetricsTLS is set, intrusion-detection-metrics-tls secrets of the
+	// tigera-operator namespace, e.g. as issued by cert-manager, rather than generate and rotate them itself. The
+	// operator waits for the secrets that don't exist, and reports a certificate whose DNS names don't include the names
+	// its component is reached at as degraded.
+	// Default: false
+	// +optional
+	ProvidedCertificates *bool `json:"providedCertificates,omitempty"`
+
+	// ImagePullPolicy is the pull policy of the intrusion detection containers, e.g. Always when mutable tags are used.
+	// Default: IfNotPresent
+	// +optional
+	// +kubebuilder:validation:Enum=Always;IfNotPresent;Never
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
+
+	// ImageOverrides replaces the images of individual intrusion detection components, e.g. for a hotfix, without an
+	// ImageSet that lists every image. It maps the image name, as used by the ImageSet, to the full image reference to
+	// use instead, including its registry and a tag or digest, e.g.
+	// tigera/intrusion-detection-controller: quay.io/tigera/intrusion-detection-controller:v3.17.1-hotfix.
+	// The supported image names are tigera/intrusion-detection-controller, tigera/intrusion-detection-job-installer,
+	// tigera/webhooks-processor and tigera/deep-packet-inspection. An image that is pinned by the ImageSet takes
+	// precedence over its override, which takes precedence over the image derived from the Installation.
+	// +optional
+	ImageOverrides map[string]string `json:"imageOverrides,omitempty"`
+
+	// ImagePathRemappings moves the images of individual intrusion detection components to another path in the
+	// registry of the Installation, for mirrors that reorganize their repositories rather than only their registry
+	// host. It maps the image name, as used by the ImageSet, to the path of the image in the mirror, without a registry,
+	// tag or digest, e.g. tigera/intrusion-detection-controller: mirror/security/idc. A remapped path replaces the
+	// imagePath and imagePrefix of the Installation, and keeps the version of the image or its digest in the ImageSet.
+	// The supported image names are the same as for ImageOverrides, and an override takes precedence over a remapping.
+	// +optional
+	ImagePathRemappings map[string]string `json:"imagePathRemappings,omitempty"`
+
+	// AdditionalDetectionRuleConfigMaps is a list of names of ConfigMaps that hold custom detection rules. Each one is
+	// mounted read-only into the intrusion-detection-controller container at /etc/tigera/detection-rules/<name>.
+	// A ConfigMap in the tigera-operator namespace is copied into the intrusion detection namespace; otherwise it must
+	// exist in the intrusion detection namespace.
+	// +optional
+	AdditionalDetectionRuleConfigMaps []string `json:"additionalDetectionRuleConfigMaps,omitempty"`
+
+	// FindingsWebhook makes the intrusion-detection-controller forward the security events it finds to an external
+	// webhook, e.g. of a SIEM, in addition to storing them in Elasticsearch.
+	// +optional
+	FindingsWebhook *IntrusionDetectionFindingsWebhook `json:"findingsWebhook,omitempty"`
+}
+
+// IntrusionDetectionFindingsWebhook configures the webhook that security events are forwarded to.
+type IntrusionDetectionFindingsWebhook struct {
+	// URL is the http or https URL that the security events are posted to.
+	URL string `json:"url"`
+
+	// AuthSecret references the key of a Secret in the tigera-operator namespace whose value is sent as the
+	// Authorization header of the requests to the webhook, e.g. Bearer <token>. The Secret is copied into the
+	// intrusion detection namespace. If unset, the requests are not authenticated.
+	// +optional
+	AuthSecret *corev1.SecretKeySelector `json:"authSecret,omitempty"`
+}
+
+// IntrusionDetectionServiceAccounts holds the names of existing ServiceAccounts for the intrusion detection workloads.
+// An empty name means the operator creates and uses its own ServiceAccount for that workload.
+type IntrusionDetectionServiceAccounts struct {
+	// Controller is the name of a ServiceAccount in the tigera-intrusion-detection namespace for the
+	// intrusion-detection-controller.
+	// +optional
+	Controller string `json:"controller,omitempty"`
+
+	// Installer is the name of a ServiceAccount in the tigera-intrusion-detection namespace for the installer Job.
+	// +optional
+	Installer string `json:"installer,omitempty"`
+
+	// DeepPacketInspection is the name of a ServiceAccount in the tigera-dpi namespace for DeepPacketInspection.
+	// +optional
+	DeepPacketInspection string `json:"deepPacketInspection,omitempty"`
+}
+
+// IntrusionDetectionAutomountServiceAccountTokens sets automountServiceAccountToken on the pods of the intrusion
+// detection workloads. A workload that is not set keeps the setting of its ServiceAccount, which mounts the token
+// unless the ServiceAccount disables it.
+type IntrusionDetectionAutomountServiceAccountTokens struct {
+	// Controller sets automountServiceAccountToken on the intrusion-detection-controller pods.
+	// +optional
+	Controller *bool `json:"controller,omitempty"`
+
+	// Installer sets automountServiceAccountToken on the pods of the installer Job.
+	// +optional
+	Installer *bool `json:"installer,omitempty"`
+
+	// DeepPacketInspection sets automountServiceAccountToken on the DeepPacketInspection pods.
+	// +optional
+	DeepPacketInspection *bool `json:"deepPacketInspection,omitempty"`
+}
+
+// IntrusionDetectionIndexLifecycle configures the phases of the lifecycle policies of the intrusion detection indices.
+// Ages and sizes are given in Elasticsearch units, e.g. 7d or 50gb. The ages must increase from the rollover of the hot
+// phase to the warm, cold and delete phases.
+type IntrusionDetectionIndexLifecycle struct {
+	// RolloverMaxAge is the age at which the index that is written to in the hot phase is rolled over to a new one.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(d|h|m|s|ms)$`
+	RolloverMaxAge string `json:"rolloverMaxAge,omitempty"`
+
+	// RolloverMaxSize is the size at which the index that is written to in the hot phase is rolled over to a new one.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(b|kb|mb|gb|tb|pb)$`
+	RolloverMaxSize string `json:"rolloverMaxSize,omitempty"`
+
+	// Warm configures the warm phase.
+	// +optional
+	Warm *IndexLifecyclePhase `json:"warm,omitempty"`
+
+	// Cold configures the cold phase.
+	// +optional
+	Cold *IndexLifecyclePhase `json:"cold,omitempty"`
+
+	// DeleteMinAge is the age at which indices are deleted.
+	// +optional
+	// +kubebuilder:validation:Pattern=`^[0-9]+(d|h|m|s|ms)$`
+	DeleteMinAge string `json:"deleteMinAge,omitempty"`
+}
+
+// IndexLifecyclePhase configures when indices enter a phase of their lifecycle, and the actions applied to them then.
+type IndexLifecyclePhase struct {
+	// MinAge is the age at which indices enter the phase.
+	// +kubebuilder:validation:Pattern=`^[0-9]+(d|h|m|s|ms)$`
+	MinAge string `json:"minAge"`
+
+	// Replicas is the number of replicas of the indices in the phase.
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// ForceMergeMaxSegments force merges the shards of the indices that enter the phase down to this number of
+	// segments.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ForceMergeMaxSegments *int32 `json:"forceMergeMaxSegments,omitempty"`
 }
 
+type ImmutableFieldChangePolicy string
+
+const (
+	ImmutableFieldChangePolicyError    ImmutableFieldChangePolicy = "Error"
+	ImmutableFieldChangePolicyRecreate ImmutableFieldChangePolicy = "Recreate"
+)
+
+// PodSecurityStandard is a level of the Kubernetes Pod Security Standards.
+type PodSecurityStandard string
+
+const (
+	PodSecurityStandardPrivileged PodSecurityStandard = "Privileged"
+	PodSecurityStandardBaseline   PodSecurityStandard = "Baseline"
+	PodSecurityStandardRestricted PodSecurityStandard = "Restricted"
+)
+
 type AnomalyDetectionSpec struct {
 
 	// StorageClassName is now deprecated, and configuring it has no effect.
@@ -49,14 +565,56 @@ type IntrusionDetectionStatus struct {
 	// Ready, Progressing, Degraded or other customer types.
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
+
+	// Dependencies lists the Secrets and ConfigMaps that intrusion detection needs, as of the latest reconcile, and
+	// whether each of them exists. Check it to find out exactly which dependency a stuck reconcile is waiting on.
+	// +optional
+	Dependencies []IntrusionDetectionDependency `json:"dependencies,omitempty"`
+
+	// Summary is a human-readable summary of the health of intrusion detection as of the latest reconcile, e.g.
+	// "2/2 components ready, Elasticsearch reachable, license OK".
+	// +optional
+	Summary string `json:"summary,omitempty"`
+
+	// InstallerJobCompletedHash is the hash of the configuration that the installer Job last completed with. The
+	// operator doesn't recreate a deleted installer Job with this configuration, so that the installer doesn't run
+	// again until its configuration changes.
+	// +optional
+	InstallerJobCompletedHash string `json:"installerJobCompletedHash,omitempty"`
+
+	// LicenseFeatureLostTime is when the license stopped granting the intrusion detection feature while the components
+	// were running. It is cleared once the license grants the feature again.
+	// +optional
+	LicenseFeatureLostTime *metav1.Time `json:"licenseFeatureLostTime,omitempty"`
+}
+
+// IntrusionDetectionDependency is a Secret or ConfigMap that intrusion detection needs.
+type IntrusionDetectionDependency struct {
+	// Kind is the kind of the dependency.
+	// +kubebuilder:validation:Enum=Secret;ConfigMap
+	Kind string `json:"kind"`
+
+	// Namespace is the namespace of the dependency.
+	Namespace string `json:"namespace"`
+
+	// Name is the name of the dependency.
+	Name string `json:"name"`
+
+	// Present is whether the dependency exists.
+	Present bool `json:"present"`
 }
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster
 
-// IntrusionDetection installs the components required for Tigera intrusion detection. At most one instance
-// of this resource is supported. It must be named "tigera-secure".
+// IntrusionDetection installs the components required for Tigera intrusion detection. The default instance must be
+// named "tigera-secure" and also manages the cluster wide components. Any other instance installs an intrusion
+// detection stack into a namespace of its own, tigera-intrusion-detection-<name>, and reports in a TigeraStatus of its
+// own, intrusion-detection-<name>. Such an instance waits for the default instance to exist and to create the key
+// pairs that it uses. It shares the Elasticsearch users and indices of the default instance, so their data is not
+// isolated from each other. DeepPacketInspection, the GlobalAlertTemplates and the cluster wide roles are only managed
+// by the default instance, and spec.controllerMetricsTLS is only supported on it.
 type IntrusionDetection struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
@@ -79,18 +637,64 @@ type IntrusionDetectionList struct {
 type IntrusionDetectionComponentName string
 
 const (
-	ComponentNameDeepPacketInspection IntrusionDetectionComponentName = "DeepPacketInspection"
+	ComponentNameDeepPacketInspection         IntrusionDetectionComponentName = "DeepPacketInspection"
+	ComponentNameIntrusionDetectionController IntrusionDetectionComponentName = "IntrusionDetectionController"
 )
 
 // The ComponentResource struct associates a ResourceRequirements with a component by name
 type IntrusionDetectionComponentResource struct {
 	// ComponentName is an enum which identifies the component
-	// +kubebuilder:validation:Enum=DeepPacketInspection
+	// +kubebuilder:validation:Enum=DeepPacketInspection;IntrusionDetectionController
 	ComponentName IntrusionDetectionComponentName `json:"componentName"`
 	// ResourceRequirements allows customization of limits and requests for compute resources such as cpu and memory.
 	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements"`
 }
 
+// DPIProfile is a DeepPacketInspection configuration for the nodes that its node selector selects. The settings that
+// it doesn't set are taken from the IntrusionDetection.
+type DPIProfile struct {
+	// Name identifies the profile. Its DaemonSet is named tigera-dpi-<name>.
+	// +kubebuilder:validation:MaxLength=52
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	Name string `json:"name"`
+
+	// NodeSelector selects the nodes the profile applies to by their labels.
+	// +kubebuilder:validation:MinProperties=1
+	NodeSelector map[string]string `json:"nodeSelector"`
+
+	// ResourceRequirements of the DeepPacketInspection container, instead of those in ComponentResources.
+	// +optional
+	ResourceRequirements *corev1.ResourceRequirements `json:"resourceRequirements,omitempty"`
+
+	// WorkerThreads is used instead of DPIWorkerThreads.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=64
+	WorkerThreads *int32 `json:"workerThreads,omitempty"`
+
+	// InterfaceRegex is used instead of DPIInterfaceRegex.
+	// +optional
+	InterfaceRegex *string `json:"interfaceRegex,omitempty"`
+
+	// BPFFilter is used instead of DPIBPFFilter.
+	// +optional
+	BPFFilter *string `json:"bpfFilter,omitempty"`
+
+	// LogSeverity is used instead of DPILogSeverity.
+	// +optional
+	// +kubebuilder:validation:Enum=Trace;Debug;Info;Warn;Error;Fatal
+	LogSeverity *LogLevel `json:"logSeverity,omitempty"`
+}
+
+// IntrusionDetectionComponentPodAnnotations associates pod annotations with a component by name.
+type IntrusionDetectionComponentPodAnnotations struct {
+	// ComponentName is an enum which identifies the component
+	// +kubebuilder:validation:Enum=DeepPacketInspection;IntrusionDetectionController
+	ComponentName IntrusionDetectionComponentName `json:"componentName"`
+	// Annotations are added to the pod template of the component.
+	Annotations map[string]string `json:"annotations"`
+}
+
 func init() {
 	SchemeBuilder.Register(&IntrusionDetection{}, &IntrusionDetectionList{})
 }
@@ -112,6 +112,29 @@ type Indices struct {
 	// Replicas defines how many replicas each index will have. See https://www.elastic.co/guide/en/elasticsearch/reference/current/scalability.html
 	// +optional
 	Replicas *int32 `json:"replicas,omitempty"`
+
+	// TotalFieldsLimit sets index.mapping.total_fields.limit, the maximum number of fields in an index, on the indices
+	// that the installers create. High-cardinality flow logs may need more fields than the Elasticsearch default.
+	// If unset, the Elasticsearch default is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	TotalFieldsLimit *int32 `json:"totalFieldsLimit,omitempty"`
+
+	// DepthLimit sets index.mapping.depth.limit, the maximum depth of a field, on the indices that the installers create.
+	// If unset, the Elasticsearch default is used.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	DepthLimit *int32 `json:"depthLimit,omitempty"`
+
+	// Prefix is prepended to the names of the indices, so that several clusters can write to a shared Elasticsearch
+	// without their indices colliding. It is passed to every component that reads or writes the indices: Linseed, the
+	// manager, and the intrusion detection controller and installer. It must be lowercase and may only contain letters,
+	// digits, '.', '_' and '-', and must start with a letter or digit.
+	// If unset, the default index names are used.
+	// +optional
+	// +kubebuilder:validation:MaxLength=100
+	// +kubebuilder:validation:Pattern=`^[a-z0-9][a-z0-9._-]*$`
+	Prefix string `json:"prefix,omitempty"`
 }
 
 // Retention defines how long data is retained in an Elasticsearch cluster before it is cleared.
@@ -208,6 +231,29 @@ func (ls LogStorage) Replicas() int {
 	return int(*ls.Spec.Indices.Replicas)
 }
 
+// IndexMappingLimits returns the index.mapping.total_fields.limit and index.mapping.depth.limit settings for the
+// indices, or zero for those that are not set.
+func (ls LogStorage) IndexMappingLimits() (totalFields int, depth int) {
+	if ls.Spec.Indices == nil {
+		return 0, 0
+	}
+	if ls.Spec.Indices.TotalFieldsLimit != nil {
+		totalFields = int(*ls.Spec.Indices.TotalFieldsLimit)
+	}
+	if ls.Spec.Indices.DepthLimit != nil {
+		depth = int(*ls.Spec.Indices.DepthLimit)
+	}
+	return totalFields, depth
+}
+
+// IndexPrefix returns the prefix of the index names, or an empty string if the default index names are used.
+func (ls LogStorage) IndexPrefix() string {
+	if ls.Spec.Indices == nil {
+		return ""
+	}
+	return ls.Spec.Indices.Prefix
+}
+
 func init() {
 	SchemeBuilder.Register(&LogStorage{}, &LogStorageList{})
 }
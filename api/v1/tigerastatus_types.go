@@ -23,6 +23,7 @@ import (
 const (
 	TigeraStatusReady    = "Ready"
 	TigeraStatusDegraded = "Degraded"
+	TigeraStatusPaused   = "Paused"
 )
 
 // TigeraStatusSpec defines the desired state of TigeraStatus
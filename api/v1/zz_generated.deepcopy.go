@@ -1804,6 +1804,53 @@ func (in *ComponentResource) DeepCopy() *ComponentResource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DPIProfile) DeepCopyInto(out *DPIProfile) {
+	*out = *in
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ResourceRequirements != nil {
+		in, out := &in.ResourceRequirements, &out.ResourceRequirements
+		*out = new(corev1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.WorkerThreads != nil {
+		in, out := &in.WorkerThreads, &out.WorkerThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.InterfaceRegex != nil {
+		in, out := &in.InterfaceRegex, &out.InterfaceRegex
+		*out = new(string)
+		**out = **in
+	}
+	if in.BPFFilter != nil {
+		in, out := &in.BPFFilter, &out.BPFFilter
+		*out = new(string)
+		**out = **in
+	}
+	if in.LogSeverity != nil {
+		in, out := &in.LogSeverity, &out.LogSeverity
+		*out = new(LogLevel)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DPIProfile.
+func (in *DPIProfile) DeepCopy() *DPIProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(DPIProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EGWDeploymentContainer) DeepCopyInto(out *EGWDeploymentContainer) {
 	*out = *in
@@ -2392,6 +2439,31 @@ func (in *Index) DeepCopy() *Index {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IndexLifecyclePhase) DeepCopyInto(out *IndexLifecyclePhase) {
+	*out = *in
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ForceMergeMaxSegments != nil {
+		in, out := &in.ForceMergeMaxSegments, &out.ForceMergeMaxSegments
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IndexLifecyclePhase.
+func (in *IndexLifecyclePhase) DeepCopy() *IndexLifecyclePhase {
+	if in == nil {
+		return nil
+	}
+	out := new(IndexLifecyclePhase)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Indices) DeepCopyInto(out *Indices) {
 	*out = *in
@@ -2400,6 +2472,16 @@ func (in *Indices) DeepCopyInto(out *Indices) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.TotalFieldsLimit != nil {
+		in, out := &in.TotalFieldsLimit, &out.TotalFieldsLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DepthLimit != nil {
+		in, out := &in.DepthLimit, &out.DepthLimit
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Indices.
@@ -2474,6 +2556,11 @@ func (in *InstallationList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *InstallationSpec) DeepCopyInto(out *InstallationSpec) {
 	*out = *in
+	if in.RegistryMirrors != nil {
+		in, out := &in.RegistryMirrors, &out.RegistryMirrors
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ImagePullSecrets != nil {
 		in, out := &in.ImagePullSecrets, &out.ImagePullSecrets
 		*out = make([]corev1.LocalObjectReference, len(*in))
@@ -2657,6 +2744,58 @@ func (in *IntrusionDetection) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionAutomountServiceAccountTokens) DeepCopyInto(out *IntrusionDetectionAutomountServiceAccountTokens) {
+	*out = *in
+	if in.Controller != nil {
+		in, out := &in.Controller, &out.Controller
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Installer != nil {
+		in, out := &in.Installer, &out.Installer
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DeepPacketInspection != nil {
+		in, out := &in.DeepPacketInspection, &out.DeepPacketInspection
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionAutomountServiceAccountTokens.
+func (in *IntrusionDetectionAutomountServiceAccountTokens) DeepCopy() *IntrusionDetectionAutomountServiceAccountTokens {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionAutomountServiceAccountTokens)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionComponentPodAnnotations) DeepCopyInto(out *IntrusionDetectionComponentPodAnnotations) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionComponentPodAnnotations.
+func (in *IntrusionDetectionComponentPodAnnotations) DeepCopy() *IntrusionDetectionComponentPodAnnotations {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionComponentPodAnnotations)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntrusionDetectionComponentResource) DeepCopyInto(out *IntrusionDetectionComponentResource) {
 	*out = *in
@@ -2677,6 +2816,66 @@ func (in *IntrusionDetectionComponentResource) DeepCopy() *IntrusionDetectionCom
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionDependency) DeepCopyInto(out *IntrusionDetectionDependency) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionDependency.
+func (in *IntrusionDetectionDependency) DeepCopy() *IntrusionDetectionDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionFindingsWebhook) DeepCopyInto(out *IntrusionDetectionFindingsWebhook) {
+	*out = *in
+	if in.AuthSecret != nil {
+		in, out := &in.AuthSecret, &out.AuthSecret
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionFindingsWebhook.
+func (in *IntrusionDetectionFindingsWebhook) DeepCopy() *IntrusionDetectionFindingsWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionFindingsWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionIndexLifecycle) DeepCopyInto(out *IntrusionDetectionIndexLifecycle) {
+	*out = *in
+	if in.Warm != nil {
+		in, out := &in.Warm, &out.Warm
+		*out = new(IndexLifecyclePhase)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cold != nil {
+		in, out := &in.Cold, &out.Cold
+		*out = new(IndexLifecyclePhase)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionIndexLifecycle.
+func (in *IntrusionDetectionIndexLifecycle) DeepCopy() *IntrusionDetectionIndexLifecycle {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionIndexLifecycle)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntrusionDetectionList) DeepCopyInto(out *IntrusionDetectionList) {
 	*out = *in
@@ -2709,6 +2908,21 @@ func (in *IntrusionDetectionList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *IntrusionDetectionServiceAccounts) DeepCopyInto(out *IntrusionDetectionServiceAccounts) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionServiceAccounts.
+func (in *IntrusionDetectionServiceAccounts) DeepCopy() *IntrusionDetectionServiceAccounts {
+	if in == nil {
+		return nil
+	}
+	out := new(IntrusionDetectionServiceAccounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
 	*out = *in
@@ -2719,7 +2933,283 @@ func (in *IntrusionDetectionSpec) DeepCopyInto(out *IntrusionDetectionSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PruneUnrecognizedComponentResources != nil {
+		in, out := &in.PruneUnrecognizedComponentResources, &out.PruneUnrecognizedComponentResources
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ComponentPodAnnotations != nil {
+		in, out := &in.ComponentPodAnnotations, &out.ComponentPodAnnotations
+		*out = make([]IntrusionDetectionComponentPodAnnotations, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.CopiedSecretMetadata != nil {
+		in, out := &in.CopiedSecretMetadata, &out.CopiedSecretMetadata
+		*out = new(Metadata)
+		(*in).DeepCopyInto(*out)
+	}
 	out.AnomalyDetection = in.AnomalyDetection
+	if in.DPITerminationGracePeriodSeconds != nil {
+		in, out := &in.DPITerminationGracePeriodSeconds, &out.DPITerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.DPISeccompProfile != nil {
+		in, out := &in.DPISeccompProfile, &out.DPISeccompProfile
+		*out = new(corev1.SeccompProfile)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DPILogSeverity != nil {
+		in, out := &in.DPILogSeverity, &out.DPILogSeverity
+		*out = new(LogLevel)
+		**out = **in
+	}
+	if in.DPISysctls != nil {
+		in, out := &in.DPISysctls, &out.DPISysctls
+		*out = make([]corev1.Sysctl, len(*in))
+		copy(*out, *in)
+	}
+	if in.DPIRuntimeClassName != nil {
+		in, out := &in.DPIRuntimeClassName, &out.DPIRuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.DPIPriorityClassName != nil {
+		in, out := &in.DPIPriorityClassName, &out.DPIPriorityClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.DPICPUPinning != nil {
+		in, out := &in.DPICPUPinning, &out.DPICPUPinning
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DPIWaitForTypha != nil {
+		in, out := &in.DPIWaitForTypha, &out.DPIWaitForTypha
+		*out = new(bool)
+		**out = **in
+	}
+	if in.FSGroup != nil {
+		in, out := &in.FSGroup, &out.FSGroup
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PodSecurityStandard != nil {
+		in, out := &in.PodSecurityStandard, &out.PodSecurityStandard
+		*out = new(PodSecurityStandard)
+		**out = **in
+	}
+	if in.DNSConfig != nil {
+		in, out := &in.DNSConfig, &out.DNSConfig
+		*out = new(corev1.PodDNSConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControllerReplicas != nil {
+		in, out := &in.ControllerReplicas, &out.ControllerReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SkipPodDisruptionBudget != nil {
+		in, out := &in.SkipPodDisruptionBudget, &out.SkipPodDisruptionBudget
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SkipInstallerJob != nil {
+		in, out := &in.SkipInstallerJob, &out.SkipInstallerJob
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SkipIndexCreation != nil {
+		in, out := &in.SkipIndexCreation, &out.SkipIndexCreation
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SkipIndexRollover != nil {
+		in, out := &in.SkipIndexRollover, &out.SkipIndexRollover
+		*out = new(bool)
+		**out = **in
+	}
+	if in.IndexLifecycle != nil {
+		in, out := &in.IndexLifecycle, &out.IndexLifecycle
+		*out = new(IntrusionDetectionIndexLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstallerJobTTLSecondsAfterFinished != nil {
+		in, out := &in.InstallerJobTTLSecondsAfterFinished, &out.InstallerJobTTLSecondsAfterFinished
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ScaleDPIResourceDefaults != nil {
+		in, out := &in.ScaleDPIResourceDefaults, &out.ScaleDPIResourceDefaults
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DPIInterfaceRegex != nil {
+		in, out := &in.DPIInterfaceRegex, &out.DPIInterfaceRegex
+		*out = new(string)
+		**out = **in
+	}
+	if in.DPIBPFFilter != nil {
+		in, out := &in.DPIBPFFilter, &out.DPIBPFFilter
+		*out = new(string)
+		**out = **in
+	}
+	if in.DPIWorkerThreads != nil {
+		in, out := &in.DPIWorkerThreads, &out.DPIWorkerThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DPINamespaceSelector != nil {
+		in, out := &in.DPINamespaceSelector, &out.DPINamespaceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.DPIProfiles != nil {
+		in, out := &in.DPIProfiles, &out.DPIProfiles
+		*out = make([]DPIProfile, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ImmutableFieldChangePolicy != nil {
+		in, out := &in.ImmutableFieldChangePolicy, &out.ImmutableFieldChangePolicy
+		*out = new(ImmutableFieldChangePolicy)
+		**out = **in
+	}
+	if in.ExternalElasticsearchCABundle != nil {
+		in, out := &in.ExternalElasticsearchCABundle, &out.ExternalElasticsearchCABundle
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstallerCABundles != nil {
+		in, out := &in.InstallerCABundles, &out.InstallerCABundles
+		*out = make([]corev1.ConfigMapKeySelector, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreferIPv6 != nil {
+		in, out := &in.PreferIPv6, &out.PreferIPv6
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DPITyphaCABundle != nil {
+		in, out := &in.DPITyphaCABundle, &out.DPITyphaCABundle
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MissingSecretGracePeriodSeconds != nil {
+		in, out := &in.MissingSecretGracePeriodSeconds, &out.MissingSecretGracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.LicenseLossGracePeriodSeconds != nil {
+		in, out := &in.LicenseLossGracePeriodSeconds, &out.LicenseLossGracePeriodSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ValidateElasticsearchCredentials != nil {
+		in, out := &in.ValidateElasticsearchCredentials, &out.ValidateElasticsearchCredentials
+		*out = new(bool)
+		**out = **in
+	}
+	if in.CheckElasticsearchConnectivity != nil {
+		in, out := &in.CheckElasticsearchConnectivity, &out.CheckElasticsearchConnectivity
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ElasticsearchBulkSize != nil {
+		in, out := &in.ElasticsearchBulkSize, &out.ElasticsearchBulkSize
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ServiceAccounts != nil {
+		in, out := &in.ServiceAccounts, &out.ServiceAccounts
+		*out = new(IntrusionDetectionServiceAccounts)
+		**out = **in
+	}
+	if in.AutomountServiceAccountTokens != nil {
+		in, out := &in.AutomountServiceAccountTokens, &out.AutomountServiceAccountTokens
+		*out = new(IntrusionDetectionAutomountServiceAccountTokens)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ControllerEnv != nil {
+		in, out := &in.ControllerEnv, &out.ControllerEnv
+		*out = make([]corev1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ControllerExtraArgs != nil {
+		in, out := &in.ControllerExtraArgs, &out.ControllerExtraArgs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ControllerVolumes != nil {
+		in, out := &in.ControllerVolumes, &out.ControllerVolumes
+		*out = make([]corev1.Volume, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ControllerVolumeMounts != nil {
+		in, out := &in.ControllerVolumeMounts, &out.ControllerVolumeMounts
+		*out = make([]corev1.VolumeMount, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ControllerGOMAXPROCSFromCPULimit != nil {
+		in, out := &in.ControllerGOMAXPROCSFromCPULimit, &out.ControllerGOMAXPROCSFromCPULimit
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ControllerMetricsTLS != nil {
+		in, out := &in.ControllerMetricsTLS, &out.ControllerMetricsTLS
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ControllerTerminationGracePeriodSeconds != nil {
+		in, out := &in.ControllerTerminationGracePeriodSeconds, &out.ControllerTerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.ControllerPreStop != nil {
+		in, out := &in.ControllerPreStop, &out.ControllerPreStop
+		*out = new(corev1.LifecycleHandler)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ProvidedCertificates != nil {
+		in, out := &in.ProvidedCertificates, &out.ProvidedCertificates
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ImageOverrides != nil {
+		in, out := &in.ImageOverrides, &out.ImageOverrides
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ImagePathRemappings != nil {
+		in, out := &in.ImagePathRemappings, &out.ImagePathRemappings
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.AdditionalDetectionRuleConfigMaps != nil {
+		in, out := &in.AdditionalDetectionRuleConfigMaps, &out.AdditionalDetectionRuleConfigMaps
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.FindingsWebhook != nil {
+		in, out := &in.FindingsWebhook, &out.FindingsWebhook
+		*out = new(IntrusionDetectionFindingsWebhook)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionSpec.
@@ -2742,6 +3232,15 @@ func (in *IntrusionDetectionStatus) DeepCopyInto(out *IntrusionDetectionStatus)
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Dependencies != nil {
+		in, out := &in.Dependencies, &out.Dependencies
+		*out = make([]IntrusionDetectionDependency, len(*in))
+		copy(*out, *in)
+	}
+	if in.LicenseFeatureLostTime != nil {
+		in, out := &in.LicenseFeatureLostTime, &out.LicenseFeatureLostTime
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new IntrusionDetectionStatus.
@@ -21,6 +21,7 @@ import (
 	"net/url"
 	"os"
 	goruntime "runtime"
+	"strconv"
 	"strings"
 	"time"
 
@@ -73,7 +74,7 @@ var (
 
 // bootstrapConfigMapName is the name of the ConfigMap that contains cluster-wide
 // configuration for the operator loaded at startup.
-const bootstrapConfigMapName = "operator-bootstrap-config"
+const bootstrapConfigMapName = utils.BootstrapConfigMapName
 
 func init() {
 	// +kubebuilder:scaffold:scheme
@@ -104,6 +105,10 @@ func main() {
 	var sgSetup bool
 	var manageCRDs bool
 	var preDelete bool
+	var intrusionDetectionSyncPeriod time.Duration
+	var intrusionDetectionRequeueJitter float64
+	var intrusionDetectionQPS float64
+	var intrusionDetectionBurst int
 
 	flag.BoolVar(&enableLeaderElection, "enable-leader-election", true,
 		"Enable leader election for controller manager. "+
@@ -124,6 +129,14 @@ func main() {
 		"Operator should manage the projectcalico.org and operator.tigera.io CRDs.")
 	flag.BoolVar(&preDelete, "pre-delete", false,
 		"Run helm pre-deletion hook logic, then exit.")
+	flag.DurationVar(&intrusionDetectionSyncPeriod, "intrusion-detection-sync-period", 0,
+		"How often the intrusion detection controller resyncs in addition to reacting to watch events. Zero disables the periodic resync.")
+	flag.Float64Var(&intrusionDetectionRequeueJitter, "intrusion-detection-requeue-jitter", 0.1,
+		"The maximum fraction by which the intrusion detection controller randomly lengthens its requeue delays. Zero disables the jitter.")
+	flag.Float64Var(&intrusionDetectionQPS, "intrusion-detection-qps", 0,
+		"The number of requests per second the intrusion detection controller makes to the API server. Zero shares the client of the other controllers.")
+	flag.IntVar(&intrusionDetectionBurst, "intrusion-detection-burst", 0,
+		"The burst of requests the intrusion detection controller may make to the API server above its QPS. Zero uses the client-go default.")
 
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
@@ -254,9 +267,7 @@ func main() {
 		// for the LicenseKey. We should test again in the future to see if the cache issue is fixed
 		// and we can remove this. Here is a link to the upstream issue
 		// https://github.com/kubernetes-sigs/controller-runtime/issues/1316
-		ClientDisableCacheFor: []client.Object{
-			&v3.LicenseKey{},
-		},
+		ClientDisableCacheFor: options.UncachedObjects(),
 		// NetworkPolicy is served through the Tigera API Server, which currently restricts List and Watch
 		// operations on NetworkPolicy to a single tier only, specified via label or field selector. If no
 		// selector is specified, List and Watch return policies from the 'default' tier. The manager cache
@@ -406,6 +417,12 @@ func main() {
 		kubernetesVersion = &common.VersionInfo{Major: 1, Minor: 18}
 	}
 
+	intrusionDetectionMaxConcurrentReconciles, err := envInt("INTRUSION_DETECTION_MAX_CONCURRENT_RECONCILES", 1)
+	if err != nil {
+		setupLog.Error(err, "Invalid INTRUSION_DETECTION_MAX_CONCURRENT_RECONCILES")
+		os.Exit(1)
+	}
+
 	// Laod the operator's bootstrap configmap, if it exists.
 	bootConfig, err := clientset.CoreV1().ConfigMaps(common.OperatorNamespace()).Get(ctx, bootstrapConfigMapName, metav1.GetOptions{})
 	if err != nil {
@@ -432,6 +449,13 @@ func main() {
 		ShutdownContext:     ctx,
 		MultiTenant:         multiTenant,
 		ElasticExternal:     utils.UseExternalElastic(bootConfig),
+
+		IntrusionDetectionSyncPeriod:    intrusionDetectionSyncPeriod,
+		IntrusionDetectionRequeueJitter: intrusionDetectionRequeueJitter,
+
+		IntrusionDetectionMaxConcurrentReconciles: intrusionDetectionMaxConcurrentReconciles,
+		IntrusionDetectionQPS:                     float32(intrusionDetectionQPS),
+		IntrusionDetectionBurst:                   intrusionDetectionBurst,
 	}
 
 	// Before we start any controllers, make sure our options are valid.
@@ -478,6 +502,19 @@ func setKubernetesServiceEnv(kubeconfigFile string) error {
 	return nil
 }
 
+// envInt returns the integer value of the given environment variable, or def if it is not set.
+func envInt(name string, def int) (int, error) {
+	v, ok := os.LookupEnv(name)
+	if !ok || v == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("%s must be an integer: %w", name, err)
+	}
+	return i, nil
+}
+
 // metricsAddr processes user-specified metrics host and port and sets
 // default values accordingly.
 func metricsAddr() string {
@@ -566,6 +603,18 @@ func executePreDeleteHook(ctx context.Context, c client.Client) error {
 
 // verifyConfiguration verifies that the final configuration of the operator is correct before starting any controllers.
 func verifyConfiguration(ctx context.Context, cs kubernetes.Interface, opts options.AddOptions) error {
+	if opts.IntrusionDetectionRequeueJitter < 0 {
+		return fmt.Errorf("the intrusion detection requeue jitter must not be negative, got %v", opts.IntrusionDetectionRequeueJitter)
+	}
+	if opts.IntrusionDetectionMaxConcurrentReconciles < 0 {
+		return fmt.Errorf("the intrusion detection max concurrent reconciles must not be negative, got %d", opts.IntrusionDetectionMaxConcurrentReconciles)
+	}
+	if opts.IntrusionDetectionQPS < 0 {
+		return fmt.Errorf("the intrusion detection QPS must not be negative, got %v", opts.IntrusionDetectionQPS)
+	}
+	if opts.IntrusionDetectionBurst < 0 {
+		return fmt.Errorf("the intrusion detection burst must not be negative, got %d", opts.IntrusionDetectionBurst)
+	}
 	if opts.ElasticExternal {
 		// There should not be an internal-es cert
 		if _, err := cs.CoreV1().Secrets(render.ElasticsearchNamespace).Get(ctx, render.TigeraElasticsearchInternalCertSecret, metav1.GetOptions{}); err != nil {
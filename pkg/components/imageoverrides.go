@@ -0,0 +1,118 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"fmt"
+	"regexp"
+
+	operator "github.com/tigera/operator/api/v1"
+)
+
+// imageNamePattern matches the slash separated path components of an image name, without its registry.
+const imageNamePattern = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*)*`
+
+// referenceRegexp matches a full image reference, i.e. an optional registry host with an optional port, followed by
+// the image name and a tag, a digest or both. It follows the grammar of the distribution reference format.
+var referenceRegexp = regexp.MustCompile(`^` +
+	// The registry host and port.
+	`(?:(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?/)?` +
+	// The slash separated path components of the image name.
+	imageNamePattern +
+	// The tag and the digest.
+	`(?::[\w][\w.-]{0,127})?(?:@[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,})?` +
+	`$`)
+
+// imageNameRegexp matches an image name without a registry, tag or digest.
+var imageNameRegexp = regexp.MustCompile(`^` + imageNamePattern + `$`)
+
+// referenceTagOrDigestRegexp matches the end of an image reference that has a tag or a digest.
+var referenceTagOrDigestRegexp = regexp.MustCompile(`(?::[\w][\w.-]{0,127}|@[^@/]+)$`)
+
+// ValidateReference returns an error if the given string is not a full image reference with a tag or a digest, such
+// as quay.io/tigera/intrusion-detection-controller:v3.17.1.
+func ValidateReference(ref string) error {
+	if !referenceRegexp.MatchString(ref) {
+		return fmt.Errorf("%q is not a valid image reference", ref)
+	}
+	if !referenceTagOrDigestRegexp.MatchString(ref) {
+		return fmt.Errorf("image reference %q must have a tag or a digest", ref)
+	}
+	return nil
+}
+
+// ValidateImageName returns an error if the given string is not an image name without a registry, tag or digest, such
+// as mirror/security/idc.
+func ValidateImageName(name string) error {
+	if !imageNameRegexp.MatchString(name) {
+		return fmt.Errorf("%q is not a valid image name", name)
+	}
+	return nil
+}
+
+// GetReferenceWithOverrides returns the fully qualified image to use, like GetReference, unless the overrides, which
+// are keyed by image name (e.g., tigera/intrusion-detection-controller), replace the image of the component with a full
+// image reference. An image that the ImageSet pins to a digest takes precedence over an override, which in turn takes
+// precedence over the default image.
+func GetReferenceWithOverrides(c component, registry, imagePath, imagePrefix string, is *operator.ImageSet, overrides map[string]string) (string, error) {
+	return GetReferenceWithRemappings(c, registry, imagePath, imagePrefix, is, overrides, nil)
+}
+
+// GetReferenceWithRemappings returns the fully qualified image to use, like GetReferenceWithOverrides, except that the
+// remappings, which are keyed by image name, can move the image of the component to another path in the registry
+// (e.g., tigera/intrusion-detection-controller to mirror/security/idc) for mirrors that reorganize their repositories.
+// A remapped path replaces the image path and prefix of the Installation, and keeps the version or the digest pinned by
+// the ImageSet. An override takes precedence over a remapping.
+func GetReferenceWithRemappings(c component, registry, imagePath, imagePrefix string, is *operator.ImageSet, overrides, remappings map[string]string) (string, error) {
+	if ref, ok := overrides[c.Image]; ok && !imageSetContains(is, c) {
+		return ref, nil
+	}
+	name, ok := remappings[c.Image]
+	if !ok {
+		return GetReference(c, registry, imagePath, imagePrefix, is)
+	}
+
+	registry = resolveRegistry(c, registry)
+	ref := fmt.Sprintf("%s%s:%s", registry, name, c.Version)
+	if is != nil {
+		digest, ok := imageSetDigest(is, c)
+		if !ok {
+			return "", fmt.Errorf("ImageSet did not contain image %s", c.Image)
+		}
+		ref = fmt.Sprintf("%s%s@%s", registry, name, digest)
+	}
+	if err := ValidateReference(ref); err != nil {
+		return "", fmt.Errorf("the remapped image of %s is invalid: %w", c.Image, err)
+	}
+	return ref, nil
+}
+
+func imageSetContains(is *operator.ImageSet, c component) bool {
+	_, ok := imageSetDigest(is, c)
+	return ok
+}
+
+// imageSetDigest returns the digest that the ImageSet pins the image of the component to, if any.
+func imageSetDigest(is *operator.ImageSet, c component) (string, bool) {
+	if is == nil {
+		return "", false
+	}
+	for _, img := range is.Spec.Images {
+		if img.Image == c.Image {
+			return img.Digest, true
+		}
+	}
+	return "", false
+}
@@ -126,3 +126,136 @@ var _ = Describe("test GetReference", func() {
 		)
 	})
 })
+
+var _ = Describe("test GetReferenceWithOverrides", func() {
+	overrides := map[string]string{"tigera/intrusion-detection-controller": "hotfix.io/tigera/intrusion-detection-controller:v1-hotfix"}
+	pinned := &op.ImageSet{
+		Spec: op.ImageSetSpec{
+			Images: []op.Image{{Image: "tigera/intrusion-detection-controller", Digest: "sha256:idshash"}},
+		},
+	}
+	unpinned := &op.ImageSet{
+		Spec: op.ImageSetSpec{
+			Images: []op.Image{{Image: "tigera/cnx-node", Digest: "sha256:tigeracnxnodehash"}},
+		},
+	}
+
+	DescribeTable("should prefer a pinned image over an override over the default",
+		func(overrides map[string]string, is *op.ImageSet, expected string) {
+			Expect(GetReferenceWithOverrides(ComponentIntrusionDetectionController, "quay.io/", "", "", is, overrides)).To(Equal(expected))
+		},
+		Entry("the default image without an override", nil, nil,
+			fmt.Sprintf("quay.io/tigera/intrusion-detection-controller:%s", ComponentIntrusionDetectionController.Version)),
+		Entry("the override of the image", overrides, nil, "hotfix.io/tigera/intrusion-detection-controller:v1-hotfix"),
+		Entry("the override of an image that the ImageSet doesn't pin", overrides, unpinned, "hotfix.io/tigera/intrusion-detection-controller:v1-hotfix"),
+		Entry("the image pinned by the ImageSet", overrides, pinned, "quay.io/tigera/intrusion-detection-controller@sha256:idshash"),
+	)
+
+	It("should ignore the overrides of other images", func() {
+		Expect(GetReferenceWithOverrides(ComponentTigeraNode, "quay.io/", "", "", nil, overrides)).To(
+			Equal(fmt.Sprintf("quay.io/tigera/cnx-node:%s", ComponentTigeraNode.Version)))
+	})
+
+	It("should fail when the ImageSet pins neither the image nor has an override for it", func() {
+		_, err := GetReferenceWithOverrides(ComponentTigeraNode, "quay.io/", "", "", pinned, overrides)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("test GetReferenceWithRemappings", func() {
+	remappings := map[string]string{"tigera/intrusion-detection-controller": "mirror/security/idc"}
+
+	DescribeTable("should render",
+		func(registry, imagePath string, remappings map[string]string, is *op.ImageSet, expected string) {
+			Expect(GetReferenceWithRemappings(ComponentIntrusionDetectionController, registry, imagePath, "", is, nil, remappings)).To(Equal(expected))
+		},
+		Entry("a mirror that only changes the registry host", "mirror.io/", "", nil, nil,
+			fmt.Sprintf("mirror.io/tigera/intrusion-detection-controller:%s", ComponentIntrusionDetectionController.Version)),
+		Entry("a mirror that changes the registry host and the image path", "mirror.io/", "userpath", nil, nil,
+			fmt.Sprintf("mirror.io/userpath/intrusion-detection-controller:%s", ComponentIntrusionDetectionController.Version)),
+		Entry("a remapped image path", "mirror.io/", "", remappings, nil,
+			fmt.Sprintf("mirror.io/mirror/security/idc:%s", ComponentIntrusionDetectionController.Version)),
+		Entry("a remapped image path instead of the image path of the Installation", "mirror.io/", "userpath", remappings, nil,
+			fmt.Sprintf("mirror.io/mirror/security/idc:%s", ComponentIntrusionDetectionController.Version)),
+		Entry("a remapped image path in the default registry", "", "", remappings, nil,
+			fmt.Sprintf("%smirror/security/idc:%s", TigeraRegistry, ComponentIntrusionDetectionController.Version)),
+		Entry("a remapped image path with the digest pinned by an ImageSet", "mirror.io/", "", remappings,
+			&op.ImageSet{Spec: op.ImageSetSpec{Images: []op.Image{{Image: "tigera/intrusion-detection-controller", Digest: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"}}}},
+			"mirror.io/mirror/security/idc@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+	)
+
+	It("should prefer an override over a remapping", func() {
+		overrides := map[string]string{"tigera/intrusion-detection-controller": "hotfix.io/tigera/intrusion-detection-controller:v1-hotfix"}
+		Expect(GetReferenceWithRemappings(ComponentIntrusionDetectionController, "mirror.io/", "", "", nil, overrides, remappings)).To(
+			Equal("hotfix.io/tigera/intrusion-detection-controller:v1-hotfix"))
+	})
+
+	It("should fail when the remapped reference doesn't parse", func() {
+		_, err := GetReferenceWithRemappings(ComponentIntrusionDetectionController, "mirror.io/", "", "", nil, nil,
+			map[string]string{"tigera/intrusion-detection-controller": "Mirror/IDC"})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail when the ImageSet doesn't pin a remapped image", func() {
+		_, err := GetReferenceWithRemappings(ComponentIntrusionDetectionController, "mirror.io/", "", "", &op.ImageSet{}, nil, remappings)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+var _ = Describe("test ValidateImageName", func() {
+	DescribeTable("should accept",
+		func(name string) {
+			Expect(ValidateImageName(name)).To(Succeed())
+		},
+		Entry("a single path component", "idc"),
+		Entry("several path components", "mirror/security/idc"),
+	)
+
+	DescribeTable("should reject",
+		func(name string) {
+			Expect(ValidateImageName(name)).NotTo(Succeed())
+		},
+		Entry("an empty name", ""),
+		Entry("a name with a tag", "mirror/security/idc:v3.17.1"),
+		Entry("an upper case name", "mirror/security/IDC"),
+		Entry("a leading slash", "/mirror/security/idc"),
+	)
+})
+
+var _ = Describe("test ValidateReference", func() {
+	DescribeTable("should accept",
+		func(ref string) {
+			Expect(ValidateReference(ref)).To(Succeed())
+		},
+		Entry("an image with a tag", "tigera/intrusion-detection-controller:v3.17.1"),
+		Entry("a registry, image and tag", "quay.io/tigera/intrusion-detection-controller:v3.17.1"),
+		Entry("a registry with a port", "registry.local:5000/tigera/intrusion-detection-controller:hotfix"),
+		Entry("a digest", "quay.io/tigera/intrusion-detection-controller@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+		Entry("a tag and a digest", "quay.io/tigera/intrusion-detection-controller:v3.17.1@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"),
+	)
+
+	DescribeTable("should reject",
+		func(ref string) {
+			Expect(ValidateReference(ref)).NotTo(Succeed())
+		},
+		Entry("an empty reference", ""),
+		Entry("an image without a tag or digest", "quay.io/tigera/intrusion-detection-controller"),
+		Entry("an upper case image name", "quay.io/tigera/Intrusion-Detection-Controller:v3.17.1"),
+		Entry("whitespace", "quay.io/tigera/intrusion-detection-controller :v3.17.1"),
+		Entry("a malformed digest", "quay.io/tigera/intrusion-detection-controller@sha256:xyz"),
+		Entry("an empty tag", "quay.io/tigera/intrusion-detection-controller:"),
+	)
+})
+
+var _ = Describe("test SelectRegistry", func() {
+	DescribeTable("should select",
+		func(registry string, mirrors []string, preferred, expected string) {
+			Expect(SelectRegistry(registry, mirrors, preferred)).To(Equal(expected))
+		},
+		Entry("no registry", "", nil, "", ""),
+		Entry("a single registry", "primary.io/", nil, "", "primary.io/"),
+		Entry("the registry when no mirror is preferred", "primary.io/", []string{"mirror1.io/", "mirror2.io/"}, "", "primary.io/"),
+		Entry("the preferred mirror", "primary.io/", []string{"mirror1.io/", "mirror2.io/"}, "mirror2.io/", "mirror2.io/"),
+		Entry("the registry when the preferred mirror is not a mirror", "primary.io/", []string{"mirror1.io/"}, "other.io/", "primary.io/"),
+	)
+})
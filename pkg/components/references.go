@@ -35,6 +35,32 @@ const UseDefault = "UseDefault"
 
 // GetReference returns the fully qualified image to use, including registry and version.
 func GetReference(c component, registry, imagePath, imagePrefix string, is *operator.ImageSet) (string, error) {
+	registry = resolveRegistry(c, registry)
+
+	image := c.Image
+	if imagePrefix != "" && imagePrefix != UseDefault {
+		image = insertPrefix(image, imagePrefix)
+	}
+	if imagePath != "" && imagePath != UseDefault {
+		image = ReplaceImagePath(image, imagePath)
+	}
+
+	if is == nil {
+		return fmt.Sprintf("%s%s:%s", registry, image, c.Version), nil
+	}
+
+	for _, img := range is.Spec.Images {
+		if img.Image == c.Image {
+			return fmt.Sprintf("%s%s@%s", registry, image, img.Digest), nil
+		}
+	}
+
+	return "", fmt.Errorf("ImageSet did not contain image %s", c.Image)
+}
+
+// resolveRegistry returns the given registry, or the default registry of the component when the user did not supply
+// one.
+func resolveRegistry(c component, registry string) string {
 	// If a user did not supply a registry, use the default registry
 	// based on component
 	if registry == "" || registry == UseDefault {
@@ -72,26 +98,7 @@ func GetReference(c component, registry, imagePath, imagePrefix string, is *oper
 			registry = c.Registry
 		}
 	}
-
-	image := c.Image
-	if imagePrefix != "" && imagePrefix != UseDefault {
-		image = insertPrefix(image, imagePrefix)
-	}
-	if imagePath != "" && imagePath != UseDefault {
-		image = ReplaceImagePath(image, imagePath)
-	}
-
-	if is == nil {
-		return fmt.Sprintf("%s%s:%s", registry, image, c.Version), nil
-	}
-
-	for _, img := range is.Spec.Images {
-		if img.Image == c.Image {
-			return fmt.Sprintf("%s%s@%s", registry, image, img.Digest), nil
-		}
-	}
-
-	return "", fmt.Errorf("ImageSet did not contain image %s", c.Image)
+	return registry
 }
 
 func ReplaceImagePath(image, imagePath string) string {
@@ -0,0 +1,29 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+// SelectRegistry returns the registry that images are rendered from: the preferred mirror when it is one of the
+// mirrors of the registry, and the registry otherwise.
+func SelectRegistry(registry string, mirrors []string, preferred string) string {
+	if preferred == "" {
+		return registry
+	}
+	for _, mirror := range mirrors {
+		if mirror == preferred {
+			return mirror
+		}
+	}
+	return registry
+}
@@ -18,6 +18,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/x509"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -48,12 +49,47 @@ func ErrInvalidCertDNSNames(secretName, secretNamespace string) error {
 	return fmt.Errorf("certificate %s/%s has the wrong DNS names", secretNamespace, secretName)
 }
 
+var (
+	// ErrKeyPairNotFound is matched, with errors.Is, by the errors for a key pair that is required but does not exist.
+	ErrKeyPairNotFound = errors.New("key pair not found")
+
+	// ErrInvalidCertData is matched, with errors.Is, by the errors for a secret whose certificate or key can't be used,
+	// because it is missing, can't be parsed or is not valid at this date.
+	ErrInvalidCertData = errors.New("invalid certificate data")
+)
+
+// certError is an error about a key pair or certificate that matches one of the sentinel errors above. Errors that
+// are returned by the API server, such as a denied read of a secret, are returned as they are so that they can be
+// checked with the k8s.io/apimachinery/pkg/api/errors functions.
+type certError struct {
+	sentinel error
+	msg      string
+	cause    error
+}
+
+func (e *certError) Error() string {
+	return e.msg
+}
+
+func (e *certError) Is(target error) bool {
+	return target == e.sentinel
+}
+
+func (e *certError) Unwrap() error {
+	return e.cause
+}
+
 func errNoPrivateKeyPEM(secretName, secretNamespace string) error {
-	return fmt.Errorf("key pair %s/%s is missing a private key", secretNamespace, secretName)
+	return &certError{sentinel: ErrInvalidCertData, msg: fmt.Sprintf("key pair %s/%s is missing a private key", secretNamespace, secretName)}
 }
 
 func errNoCertificatePEM(secretName, secretNamespace string) error {
-	return fmt.Errorf("certificate PEM is missing for %s/%s ", secretNamespace, secretName)
+	return &certError{sentinel: ErrInvalidCertData, msg: fmt.Sprintf("certificate PEM is missing for %s/%s ", secretNamespace, secretName)}
+}
+
+// errInvalidCertificatePEM marks an error parsing the certificate PEM as ErrInvalidCertData, keeping its message.
+func errInvalidCertificatePEM(err error) error {
+	return &certError{sentinel: ErrInvalidCertData, msg: err.Error(), cause: err}
 }
 
 type certificateManager struct {
@@ -190,7 +226,7 @@ func Create(cli client.Client, installation *operatorv1.InstallationSpec, cluste
 			if !cm.allowCACreation {
 				// Most controllers should NOT allow CA creation. For single-tenant, this is handled at cluster startup by the secret controller.
 				// For multi-tenant clusters, each tenant has its own CA that is created by the tenant controller.
-				return nil, fmt.Errorf("CA secret %s/%s does not exist yet and is not allowed for this call", ns, caSecretName)
+				return nil, &certError{sentinel: ErrKeyPairNotFound, msg: fmt.Sprintf("CA secret %s/%s does not exist yet and is not allowed for this call", ns, caSecretName)}
 			}
 			// No existing CA data - we need to generate a new one.
 			cm.log.Info("Generating a new CA", "namespace", ns)
@@ -209,7 +245,7 @@ func Create(cli client.Client, installation *operatorv1.InstallationSpec, cluste
 			privateKeyPEM, certificatePEM = caSecret.Data[corev1.TLSPrivateKeyKey], caSecret.Data[corev1.TLSCertKey]
 			cryptoCA, err = crypto.GetCAFromBytes(certificatePEM, privateKeyPEM)
 			if err != nil {
-				return nil, err
+				return nil, errInvalidCertificatePEM(err)
 			}
 		}
 	}
@@ -218,7 +254,7 @@ func Create(cli client.Client, installation *operatorv1.InstallationSpec, cluste
 	// instance based on it.
 	x509Cert, err := certificatemanagement.ParseCertificate(certificatePEM)
 	if err != nil {
-		return nil, err
+		return nil, errInvalidCertificatePEM(err)
 	}
 
 	// Fill in remaining fields.
@@ -366,7 +402,7 @@ func (cm *certificateManager) getKeyPair(cli client.Client, secretName, secretNa
 	}
 	x509Cert, err := certificatemanagement.ParseCertificate(certPEM)
 	if err != nil {
-		return nil, nil, err
+		return nil, nil, errInvalidCertificatePEM(err)
 	}
 
 	// Get specific usages to check for certs that are utilized for mTLS with Linseed
@@ -390,7 +426,7 @@ func (cm *certificateManager) getKeyPair(cli client.Client, secretName, secretNa
 		}
 
 		if timeInvalid {
-			return nil, nil, fmt.Errorf("secret %s/%s is not valid at this date", secretNamespace, secretName)
+			return nil, nil, &certError{sentinel: ErrInvalidCertData, msg: fmt.Sprintf("secret %s/%s is not valid at this date", secretNamespace, secretName)}
 		}
 		return nil, nil, newCertExtKeyUsageError(secretName, secretNamespace, requiredKeyUsages)
 	}
@@ -20,6 +20,7 @@ package certificatemanager_test
 import (
 	"context"
 	"crypto/x509"
+	"errors"
 	"runtime"
 	"strings"
 	"time"
@@ -45,10 +46,12 @@ import (
 	apps "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	k8sruntime "k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 var _ = Describe("Test CertificateManagement suite", func() {
@@ -335,6 +338,50 @@ var _ = Describe("Test CertificateManagement suite", func() {
 		})
 	})
 
+	Describe("test error types", func() {
+		It("should return ErrKeyPairNotFound when the CA does not exist and may not be created", func() {
+			_, err := certificatemanager.Create(cli, installation, clusterDomain, "test-namespace")
+			Expect(errors.Is(err, certificatemanager.ErrKeyPairNotFound)).To(BeTrue())
+			Expect(errors.Is(err, certificatemanager.ErrInvalidCertData)).To(BeFalse())
+		})
+
+		It("should return ErrInvalidCertData for a secret without a private key", func() {
+			Expect(cli.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: appSecretName, Namespace: appNs},
+				Data:       map[string][]byte{corev1.TLSCertKey: byoSecret.Data["cert.crt"]},
+			})).NotTo(HaveOccurred())
+			_, err := certificateManager.GetOrCreateKeyPair(cli, appSecretName, appNs, appDNSNames)
+			Expect(errors.Is(err, certificatemanager.ErrInvalidCertData)).To(BeTrue())
+		})
+
+		It("should return ErrInvalidCertData for a secret with a certificate that can't be parsed", func() {
+			Expect(cli.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: appSecretName, Namespace: appNs},
+				Data: map[string][]byte{
+					corev1.TLSCertKey:       []byte("not a certificate"),
+					corev1.TLSPrivateKeyKey: byoSecret.Data["key.key"],
+				},
+			})).NotTo(HaveOccurred())
+			_, err := certificateManager.GetCertificate(cli, appSecretName, appNs)
+			Expect(errors.Is(err, certificatemanager.ErrInvalidCertData)).To(BeTrue())
+			Expect(errors.Is(err, certificatemanager.ErrKeyPairNotFound)).To(BeFalse())
+		})
+
+		It("should return ErrInvalidCertData for a byo secret that has expired", func() {
+			Expect(cli.Create(ctx, expiredBYOSecret)).NotTo(HaveOccurred())
+			_, err := certificateManager.GetOrCreateKeyPair(cli, expiredBYOSecret.Name, expiredBYOSecret.Namespace, appDNSNames)
+			Expect(errors.Is(err, certificatemanager.ErrInvalidCertData)).To(BeTrue())
+		})
+
+		It("should return the API server error when reading the secret is denied", func() {
+			forbidden := &forbiddenClient{Client: cli}
+			_, err := certificateManager.GetOrCreateKeyPair(forbidden, appSecretName, appNs, appDNSNames)
+			Expect(kerrors.IsForbidden(err)).To(BeTrue())
+			Expect(errors.Is(err, certificatemanager.ErrKeyPairNotFound)).To(BeFalse())
+			Expect(errors.Is(err, certificatemanager.ErrInvalidCertData)).To(BeFalse())
+		})
+	})
+
 	Describe("test KeyPair interface", func() {
 		It("should not be possible to modify its internal secret", func() {
 			By("creating a key pair")
@@ -648,6 +695,52 @@ var _ = Describe("Test CertificateManagement suite", func() {
 			}))
 		})
 	})
+
+	Describe("test expiring certificates", func() {
+		It("should return the certificates that expire within the given period", func() {
+			Expect(cli.Create(ctx, byoSecret)).NotTo(HaveOccurred())
+			byoCert, err := certificateManager.GetCertificate(cli, appSecretName, appNs)
+			Expect(err).NotTo(HaveOccurred())
+			keyPair, err := certificateManager.GetOrCreateKeyPair(cli, appSecretName2, appNs, appDNSNames)
+			Expect(err).NotTo(HaveOccurred())
+
+			expiring := certificatemanager.ExpiringCertificates(24*time.Hour, byoCert, keyPair)
+			Expect(expiring).To(HaveLen(1))
+			Expect(expiring[0].GetName()).To(Equal(appSecretName))
+			Expect(expiring[0].NotAfter).To(BeTemporally("~", time.Now().Add(time.Hour), time.Minute))
+
+			Expect(certificatemanager.ExpiringCertificates(time.Minute, byoCert, keyPair)).To(BeEmpty())
+		})
+	})
+
+	Describe("test certificate expiry metrics", func() {
+		It("should set a gauge for a generated component certificate", func() {
+			keyPair, err := certificateManager.GetOrCreateKeyPair(cli, appSecretName, appNs, appDNSNames)
+			Expect(err).NotTo(HaveOccurred())
+			x509Cert, err := certificatemanagement.ParseCertificate(keyPair.GetCertificatePEM())
+			Expect(err).NotTo(HaveOccurred())
+
+			certificatemanager.RecordCertificateExpiry(keyPair)
+
+			families, err := metrics.Registry.Gather()
+			Expect(err).NotTo(HaveOccurred())
+			var found bool
+			for _, family := range families {
+				if family.GetName() != certificatemanager.CertificateExpiryMetricName {
+					continue
+				}
+				for _, m := range family.GetMetric() {
+					for _, label := range m.GetLabel() {
+						if label.GetName() == "name" && label.GetValue() == appSecretName {
+							found = true
+							Expect(m.GetGauge().GetValue()).To(BeNumerically("==", x509Cert.NotAfter.Unix()))
+						}
+					}
+				}
+			}
+			Expect(found).To(BeTrue())
+		})
+	})
 })
 
 func x509FromSecret(secret *corev1.Secret) (*x509.Certificate, error) {
@@ -658,3 +751,12 @@ func x509FromSecret(secret *corev1.Secret) (*x509.Certificate, error) {
 	}
 	return x509Cert, nil
 }
+
+// forbiddenClient denies every read of a secret.
+type forbiddenClient struct {
+	client.Client
+}
+
+func (c *forbiddenClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return kerrors.NewForbidden(corev1.Resource("secrets"), key.Name, errors.New("access denied"))
+}
@@ -0,0 +1,49 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificatemanager
+
+import (
+	"time"
+
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// ExpiringCertificate is a certificate that expires within the period passed to ExpiringCertificates.
+type ExpiringCertificate struct {
+	certificatemanagement.CertificateInterface
+	NotAfter time.Time
+}
+
+// ExpiringCertificates returns the certificates that expire within the given period from now, so that rotation
+// problems can be surfaced before TLS handshakes start to fail. Certificates without PEM data, such as key pairs
+// that are issued by certificate management, are skipped.
+func ExpiringCertificates(within time.Duration, certificates ...certificatemanagement.CertificateInterface) []ExpiringCertificate {
+	var expiring []ExpiringCertificate
+	deadline := time.Now().Add(within)
+	for _, cert := range certificates {
+		if cert == nil || len(cert.GetCertificatePEM()) == 0 {
+			continue
+		}
+		x509Cert, err := certificatemanagement.ParseCertificate(cert.GetCertificatePEM())
+		if err != nil {
+			log.V(2).Info("Unable to parse certificate to check its expiry", "name", cert.GetName(), "err", err)
+			continue
+		}
+		if x509Cert.NotAfter.Before(deadline) {
+			expiring = append(expiring, ExpiringCertificate{CertificateInterface: cert, NotAfter: x509Cert.NotAfter})
+		}
+	}
+	return expiring
+}
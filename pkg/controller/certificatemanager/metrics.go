@@ -0,0 +1,51 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package certificatemanager
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+const CertificateExpiryMetricName = "tigera_operator_certificate_expiry_timestamp_seconds"
+
+// certificateExpiry exposes the notAfter timestamp of component certificates so that alerting rules can fire
+// before a certificate expires.
+var certificateExpiry = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: CertificateExpiryMetricName,
+	Help: "The time at which a component certificate expires, in seconds since the epoch.",
+}, []string{"name", "namespace"})
+
+func init() {
+	metrics.Registry.MustRegister(certificateExpiry)
+}
+
+// RecordCertificateExpiry updates the certificate expiry gauge for each of the given certificates. Certificates without
+// PEM data, such as key pairs that are issued by certificate management, are skipped.
+func RecordCertificateExpiry(certificates ...certificatemanagement.CertificateInterface) {
+	for _, cert := range certificates {
+		if cert == nil || len(cert.GetCertificatePEM()) == 0 {
+			continue
+		}
+		x509Cert, err := certificatemanagement.ParseCertificate(cert.GetCertificatePEM())
+		if err != nil {
+			log.V(2).Info("Unable to parse certificate for expiry metric", "name", cert.GetName(), "err", err)
+			continue
+		}
+		certificateExpiry.WithLabelValues(cert.GetName(), cert.GetNamespace()).Set(float64(x509Cert.NotAfter.Unix()))
+	}
+}
@@ -452,6 +452,14 @@ func fillDefaults(instance *operator.Installation) error {
 		// Make sure registry, except for the special case "UseDefault", always ends with a slash.
 		instance.Spec.Registry = fmt.Sprintf("%s/", instance.Spec.Registry)
 	}
+	for i, mirror := range instance.Spec.RegistryMirrors {
+		if !strings.HasSuffix(mirror, "/") {
+			instance.Spec.RegistryMirrors[i] = fmt.Sprintf("%s/", mirror)
+		}
+	}
+	if len(instance.Spec.PreferredRegistryMirror) != 0 && !strings.HasSuffix(instance.Spec.PreferredRegistryMirror, "/") {
+		instance.Spec.PreferredRegistryMirror = fmt.Sprintf("%s/", instance.Spec.PreferredRegistryMirror)
+	}
 
 	if len(instance.Spec.Variant) == 0 {
 		// Default to installing Calico.
@@ -944,6 +952,10 @@ func (r *ReconcileInstallation) Reconcile(ctx context.Context, request reconcile
 		}
 	}
 
+	// Render from the preferred mirror of the registry. This is resolved after the defaults have been written back
+	// so that the mirror is never stored as the registry in the Installation spec.
+	utils.ResolveRegistryMirror(&instance.Spec)
+
 	if err = r.updateCRDs(ctx, instance.Spec.Variant, reqLogger); err != nil {
 		return reconcile.Result{}, err
 	}
@@ -27,6 +27,7 @@ import (
 	csinodedriver "github.com/tigera/operator/pkg/common/validation/csi-node-driver"
 	kubecontrollers "github.com/tigera/operator/pkg/common/validation/kube-controllers"
 	typha "github.com/tigera/operator/pkg/common/validation/typha"
+	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/k8sapi"
 	"github.com/tigera/operator/pkg/render"
 	appsv1 "k8s.io/api/apps/v1"
@@ -439,6 +440,23 @@ func validateCustomResource(instance *operatorv1.Installation) error {
 		}
 	}
 
+	// Verify the preferred registry mirror, if specified, is one of the mirrors of the registry.
+	if preferred := instance.Spec.PreferredRegistryMirror; preferred != "" {
+		if len(instance.Spec.Registry) == 0 || instance.Spec.Registry == components.UseDefault {
+			return fmt.Errorf("Installation spec.PreferredRegistryMirror requires spec.Registry to be set")
+		}
+		found := false
+		for _, mirror := range instance.Spec.RegistryMirrors {
+			if mirror == preferred {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("Installation spec.PreferredRegistryMirror %s is not one of spec.RegistryMirrors", preferred)
+		}
+	}
+
 	// Verify CNILogging to not exist for non-calico cni
 	if cni := instance.Spec.CNI.Type; cni != operatorv1.PluginCalico {
 		if instance.Spec.Logging != nil && instance.Spec.Logging.CNI != nil {
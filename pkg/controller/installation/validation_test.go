@@ -322,6 +322,29 @@ var _ = Describe("Installation validation tests", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should allow a preferred registry mirror that is one of the mirrors", func() {
+		instance.Spec.Registry = "primary.io/"
+		instance.Spec.RegistryMirrors = []string{"mirror1.io/", "mirror2.io/"}
+		instance.Spec.PreferredRegistryMirror = "mirror2.io/"
+		err := validateCustomResource(instance)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should not allow a preferred registry mirror that is not one of the mirrors", func() {
+		instance.Spec.Registry = "primary.io/"
+		instance.Spec.RegistryMirrors = []string{"mirror1.io/"}
+		instance.Spec.PreferredRegistryMirror = "mirror2.io/"
+		err := validateCustomResource(instance)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should not allow a preferred registry mirror without a registry", func() {
+		instance.Spec.RegistryMirrors = []string{"mirror1.io/"}
+		instance.Spec.PreferredRegistryMirror = "mirror1.io/"
+		err := validateCustomResource(instance)
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should not allow a relative path in FlexVolumePath", func() {
 		instance.Spec.FlexVolumePath = "foo/bar/baz"
 		err := validateCustomResource(instance)
@@ -0,0 +1,60 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// absenceTracker records since when something that each IntrusionDetection needs has been missing, so that something
+// that is expected to come back soon, e.g. secrets that a tool syncs from an external store, is given a grace period
+// before the controller acts on its absence. A nil absenceTracker gives no grace period.
+type absenceTracker struct {
+	lock  sync.Mutex
+	since map[string]time.Time
+}
+
+func newAbsenceTracker() *absenceTracker {
+	return &absenceTracker{since: map[string]time.Time{}}
+}
+
+// missing records that what is tracked is missing for the given IntrusionDetection and returns how long it has been
+// missing for.
+func (t *absenceTracker) missing(name string) time.Duration {
+	if t == nil {
+		return time.Duration(math.MaxInt64)
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	since, ok := t.since[name]
+	if !ok {
+		since = time.Now()
+		t.since[name] = since
+	}
+	return time.Since(since)
+}
+
+// found records that what is tracked is present for the given IntrusionDetection.
+func (t *absenceTracker) found(name string) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.since, name)
+}
@@ -0,0 +1,115 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+// UnrecognizedComponentResourcesConditionType is the type of the IntrusionDetection status condition that lists the
+// ComponentResources entries of components that the operator doesn't recognize. It is only set while there are any.
+const UnrecognizedComponentResourcesConditionType = "UnrecognizedComponentResources"
+
+// recognizedComponentNames are the components that ComponentResources entries can be given for.
+var recognizedComponentNames = map[operatorv1.IntrusionDetectionComponentName]bool{
+	operatorv1.ComponentNameDeepPacketInspection:         true,
+	operatorv1.ComponentNameIntrusionDetectionController: true,
+}
+
+// unrecognizedComponentResources returns the names of the components in ComponentResources that the operator doesn't
+// recognize. The CRD only admits the current names, so these are left over from components that were renamed or
+// removed since the entries were written.
+func unrecognizedComponentResources(ids *operatorv1.IntrusionDetection) []string {
+	var names []string
+	for _, cr := range ids.Spec.ComponentResources {
+		if !recognizedComponentNames[cr.ComponentName] {
+			names = append(names, string(cr.ComponentName))
+		}
+	}
+	return names
+}
+
+// handleUnrecognizedComponentResources removes the ComponentResources entries of unrecognized components when
+// PruneUnrecognizedComponentResources is set, and otherwise reports them in the UnrecognizedComponentResources
+// condition.
+func (r *ReconcileIntrusionDetection) handleUnrecognizedComponentResources(ctx context.Context, ids *operatorv1.IntrusionDetection) error {
+	names := unrecognizedComponentResources(ids)
+	if len(names) > 0 && ids.Spec.PruneUnrecognizedComponentResources != nil && *ids.Spec.PruneUnrecognizedComponentResources {
+		logf.FromContext(ctx).Info("Pruning the ComponentResources of unrecognized components", "components", names)
+		if err := r.pruneUnrecognizedComponentResources(ctx, ids); err != nil {
+			return err
+		}
+		names = nil
+	}
+
+	current := meta.FindStatusCondition(ids.Status.Conditions, UnrecognizedComponentResourcesConditionType)
+	if len(names) == 0 {
+		if current == nil {
+			return nil
+		}
+		return r.updateStatus(ctx, ids, func(s *operatorv1.IntrusionDetectionStatus) {
+			meta.RemoveStatusCondition(&s.Conditions, UnrecognizedComponentResourcesConditionType)
+		})
+	}
+
+	condition := metav1.Condition{
+		Type:   UnrecognizedComponentResourcesConditionType,
+		Status: metav1.ConditionTrue,
+		Reason: "UnrecognizedComponents",
+		Message: fmt.Sprintf("spec.componentResources has entries for unrecognized components, which are ignored: %s. "+
+			"Remove them, or set spec.pruneUnrecognizedComponentResources to have them removed", strings.Join(names, ", ")),
+		ObservedGeneration: ids.Generation,
+	}
+	if current != nil && current.Status == condition.Status && current.Message == condition.Message &&
+		current.ObservedGeneration == condition.ObservedGeneration {
+		return nil
+	}
+	return r.updateStatus(ctx, ids, func(s *operatorv1.IntrusionDetectionStatus) {
+		meta.SetStatusCondition(&s.Conditions, condition)
+	})
+}
+
+// pruneUnrecognizedComponentResources removes the ComponentResources entries of unrecognized components from the
+// IntrusionDetection resource.
+func (r *ReconcileIntrusionDetection) pruneUnrecognizedComponentResources(ctx context.Context, ids *operatorv1.IntrusionDetection) error {
+	refresh := false
+	return utils.RetryOnTransientError(func() error {
+		if refresh {
+			if err := r.client.Get(ctx, client.ObjectKeyFromObject(ids), ids); err != nil {
+				return err
+			}
+		}
+		refresh = true
+
+		var resources []operatorv1.IntrusionDetectionComponentResource
+		for _, cr := range ids.Spec.ComponentResources {
+			if recognizedComponentNames[cr.ComponentName] {
+				resources = append(resources, cr)
+			}
+		}
+		ids.Spec.ComponentResources = resources
+		return r.client.Update(ctx, ids)
+	})
+}
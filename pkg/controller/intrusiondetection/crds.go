@@ -0,0 +1,48 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
+)
+
+// requiredCRDs are the kinds of the Calico Enterprise CRDs that the reconcile reads, along with a list of each.
+var requiredCRDs = []struct {
+	kind string
+	list func() client.ObjectList
+}{
+	{v3.KindDeepPacketInspection, func() client.ObjectList { return &v3.DeepPacketInspectionList{} }},
+	{v3.KindLicenseKey, func() client.ObjectList { return &v3.LicenseKeyList{} }},
+}
+
+// missingCRD returns the kind of the first of the required CRDs that is not installed, along with the error that
+// reading it failed with. Any other error is returned without a kind.
+func (r *ReconcileIntrusionDetection) missingCRD(ctx context.Context) (string, error) {
+	for _, crd := range requiredCRDs {
+		if err := r.client.List(ctx, crd.list()); err != nil {
+			if meta.IsNoMatchError(err) || runtime.IsNotRegisteredError(err) {
+				return crd.kind, err
+			}
+			return "", err
+		}
+	}
+	return "", nil
+}
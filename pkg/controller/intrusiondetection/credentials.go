@@ -0,0 +1,288 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/controller/certificatemanager"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/logstorage"
+	"github.com/tigera/operator/pkg/render/logstorage/esgateway"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+)
+
+// credentialValidationTimeout is how long the operator waits for Elasticsearch to answer when it tests credentials.
+const credentialValidationTimeout = 5 * time.Second
+
+// defaultConnectivityCheckInterval is how often Elasticsearch is checked for the ElasticsearchReachable condition when
+// the controller has no sync period.
+const defaultConnectivityCheckInterval = 5 * time.Minute
+
+// ElasticsearchReachableConditionType is the type of the IntrusionDetection status condition that tells whether
+// Elasticsearch can be reached with the credentials of the intrusion-detection-controller, along with the reasons it
+// is set with.
+const (
+	ElasticsearchReachableConditionType = "ElasticsearchReachable"
+
+	ElasticsearchReachableReason            = "Reachable"
+	ElasticsearchUnreachableReason          = "Unreachable"
+	ElasticsearchAuthenticationFailedReason = "AuthenticationFailed"
+)
+
+// errInvalidCredentials is wrapped by the errors for credentials that are missing or that Elasticsearch rejects, as
+// opposed to an Elasticsearch that cannot be reached.
+var errInvalidCredentials = errors.New("invalid credentials")
+
+// credentialValidator tests the given credentials against the Elasticsearch at the given endpoint, and returns an error
+// if Elasticsearch cannot be reached or does not accept them.
+type credentialValidator func(ctx context.Context, endpoint string, roots *x509.CertPool, username, password string) error
+
+// validateElasticsearchCredentials is the default credentialValidator. It authenticates against the _authenticate API
+// of Elasticsearch, which succeeds for any valid user regardless of its privileges.
+func validateElasticsearchCredentials(ctx context.Context, endpoint string, roots *x509.CertPool, username, password string) error {
+	ctx, cancel := context.WithTimeout(ctx, credentialValidationTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/_security/_authenticate", nil)
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(username, password)
+
+	cli := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, MinVersion: tls.VersionTLS12}}}
+	resp, err := cli.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to reach Elasticsearch: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusUnauthorized:
+		return fmt.Errorf("Elasticsearch rejected the credentials of user %q: %w", username, errInvalidCredentials)
+	case resp.StatusCode >= 300:
+		return fmt.Errorf("Elasticsearch returned %s when authenticating user %q", resp.Status, username)
+	}
+	return nil
+}
+
+// elasticsearchEndpoint returns the endpoint that the operator checks Elasticsearch at, along with the certificates it
+// trusts there. The internal Elasticsearch is reached through es-gateway, with the certificate of es-gateway. An
+// external Elasticsearch is reached through the Service that the cluster provisioner points at it, as es-gateway does,
+// and may be signed by a public CA.
+func (r *ReconcileIntrusionDetection) elasticsearchEndpoint(external bool, esgwCertificate certificatemanagement.CertificateInterface, certificateManager certificatemanager.CertificateManager, externalCerts []certificatemanagement.CertificateInterface, reqLogger logr.Logger) (string, *x509.CertPool) {
+	if !external {
+		return relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain), certPool(x509.NewCertPool(), esgwCertificate)
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	if cert, err := certificateManager.GetCertificate(r.client, logstorage.ExternalESPublicCertName, common.OperatorNamespace()); err != nil {
+		reqLogger.Error(err, "Failed to read the public certificate of the external Elasticsearch")
+	} else if cert != nil {
+		roots = certPool(roots, cert)
+	}
+	return esgateway.ElasticsearchHTTPSEndpoint, certPool(roots, externalCerts...)
+}
+
+// validateCredentials tests the credentials of each of the Elasticsearch user secrets against the Elasticsearch at the
+// given endpoint. Credentials that Elasticsearch accepted are only tested again once they or the endpoint change, or
+// once per sync period.
+func (r *ReconcileIntrusionDetection) validateCredentials(ctx context.Context, instance *operatorv1.IntrusionDetection, endpoint string, roots *x509.CertPool, secrets []*corev1.Secret) error {
+	hash := rmeta.AnnotationHash([]interface{}{endpoint, rmeta.SecretsAnnotationHash(secrets...)})
+	interval := r.syncPeriod
+	if interval == 0 {
+		interval = defaultConnectivityCheckInterval
+	}
+	if r.credentialChecks.accepted(instance.Name, hash, interval) {
+		return nil
+	}
+
+	validate := r.credentialValidator
+	if validate == nil {
+		validate = validateElasticsearchCredentials
+	}
+	if err := testCredentials(ctx, validate, endpoint, roots, secrets); err != nil {
+		return err
+	}
+	r.credentialChecks.accept(instance.Name, hash)
+	return nil
+}
+
+// setElasticsearchReachable checks whether the Elasticsearch at the given endpoint can be reached with the credentials
+// in the given secret, and records the outcome in the ElasticsearchReachable condition of the IntrusionDetection. The
+// check runs at most once per sync period, unless the IntrusionDetection has changed since it last ran, and is skipped
+// when the reconciler has no connectivity check.
+func (r *ReconcileIntrusionDetection) setElasticsearchReachable(ctx context.Context, instance *operatorv1.IntrusionDetection, endpoint string, roots *x509.CertPool, secret *corev1.Secret) error {
+	if r.connectivityCheck == nil {
+		return nil
+	}
+	current := meta.FindStatusCondition(instance.Status.Conditions, ElasticsearchReachableConditionType)
+	interval := r.syncPeriod
+	if interval == 0 {
+		interval = defaultConnectivityCheckInterval
+	}
+	changed := current == nil || current.ObservedGeneration != instance.Generation
+	if !r.connectivityChecks.due(instance.Name, interval, changed) {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               ElasticsearchReachableConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             ElasticsearchReachableReason,
+		Message:            "Elasticsearch accepts the credentials of the intrusion-detection-controller",
+		ObservedGeneration: instance.Generation,
+	}
+	if err := testCredentials(ctx, r.connectivityCheck, endpoint, roots, []*corev1.Secret{secret}); err != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ElasticsearchUnreachableReason
+		if errors.Is(err, errInvalidCredentials) {
+			condition.Reason = ElasticsearchAuthenticationFailedReason
+		}
+		condition.Message = err.Error()
+	}
+
+	return r.setCondition(ctx, instance, condition)
+}
+
+// certPool adds each of the given certificates, along with its issuer, to the given pool and returns it.
+func certPool(pool *x509.CertPool, certs ...certificatemanagement.CertificateInterface) *x509.CertPool {
+	for _, cert := range certs {
+		pool.AppendCertsFromPEM(cert.GetCertificatePEM())
+		if issuer := cert.GetIssuer(); issuer != nil {
+			pool.AppendCertsFromPEM(issuer.GetCertificatePEM())
+		}
+	}
+	return pool
+}
+
+// testCredentials tests the credentials of each of the given secrets with validate.
+func testCredentials(ctx context.Context, validate credentialValidator, endpoint string, roots *x509.CertPool, secrets []*corev1.Secret) error {
+	for _, s := range secrets {
+		username, password := string(s.Data["username"]), string(s.Data["password"])
+		if username == "" || password == "" {
+			return fmt.Errorf("secret %s/%s has no username or password: %w", s.Namespace, s.Name, errInvalidCredentials)
+		}
+		if err := validate(ctx, endpoint, roots, username, password); err != nil {
+			return fmt.Errorf("secret %s/%s: %w", s.Namespace, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// checkThrottle records when a check last ran for each IntrusionDetection, so that it runs at most once per interval.
+// A nil checkThrottle never throttles.
+type checkThrottle struct {
+	lock sync.Mutex
+	last map[string]time.Time
+}
+
+func newCheckThrottle() *checkThrottle {
+	return &checkThrottle{last: map[string]time.Time{}}
+}
+
+// due returns whether the check is due for the given IntrusionDetection, i.e. whether it is forced or the interval has
+// passed since it last ran, and if so records that it runs now.
+func (t *checkThrottle) due(name string, interval time.Duration, force bool) bool {
+	if t == nil {
+		return true
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if last, ok := t.last[name]; ok && !force && time.Since(last) < interval {
+		return false
+	}
+	t.last[name] = time.Now()
+	return true
+}
+
+// forget drops what is recorded for the given IntrusionDetection, once it is deleted.
+func (t *checkThrottle) forget(name string) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	delete(t.last, name)
+}
+
+// credentialCache records the hash of the credentials that Elasticsearch last accepted for each IntrusionDetection, and
+// when. Rejected credentials are not recorded, so that they are tested again before they are rolled out. A nil
+// credentialCache accepts nothing.
+type credentialCache struct {
+	lock sync.Mutex
+	last map[string]acceptedCredentials
+}
+
+type acceptedCredentials struct {
+	hash string
+	at   time.Time
+}
+
+func newCredentialCache() *credentialCache {
+	return &credentialCache{last: map[string]acceptedCredentials{}}
+}
+
+// accepted returns whether the credentials with the given hash were accepted for the given IntrusionDetection within
+// the interval.
+func (c *credentialCache) accepted(name, hash string, interval time.Duration) bool {
+	if c == nil {
+		return false
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	a, ok := c.last[name]
+	return ok && a.hash == hash && time.Since(a.at) < interval
+}
+
+// accept records that the credentials with the given hash were accepted for the given IntrusionDetection.
+func (c *credentialCache) accept(name, hash string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.last[name] = acceptedCredentials{hash: hash, at: time.Now()}
+}
+
+// forget drops what is recorded for the given IntrusionDetection, once it is deleted.
+func (c *credentialCache) forget(name string) {
+	if c == nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.last, name)
+}
@@ -0,0 +1,121 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+)
+
+const (
+	secretDependencyKind    = "Secret"
+	configMapDependencyKind = "ConfigMap"
+)
+
+// requiredDependencies returns the Secrets and ConfigMaps that the reconcile of the IntrusionDetection reads, along
+// with whether each of them exists, so that a reconcile that is waiting on one of them can be diagnosed from the
+// status of the IntrusionDetection.
+func (r *ReconcileIntrusionDetection) requiredDependencies(ctx context.Context, instance *operatorv1.IntrusionDetection, installNamespace string, managedCluster, elasticExternal bool) ([]operatorv1.IntrusionDetectionDependency, error) {
+	ns := common.OperatorNamespace()
+	deps := []operatorv1.IntrusionDetectionDependency{
+		{Kind: configMapDependencyKind, Namespace: ns, Name: relasticsearch.ClusterConfigConfigMapName},
+		{Kind: secretDependencyKind, Namespace: ns, Name: render.ElasticsearchIntrusionDetectionUserSecret},
+		{Kind: secretDependencyKind, Namespace: ns, Name: render.ElasticsearchPerformanceHotspotsUserSecret},
+	}
+	if managedCluster {
+		deps = append(deps, operatorv1.IntrusionDetectionDependency{Kind: secretDependencyKind, Namespace: ns, Name: render.VoltronLinseedPublicCert})
+	} else {
+		deps = append(deps,
+			operatorv1.IntrusionDetectionDependency{Kind: secretDependencyKind, Namespace: ns, Name: render.ElasticsearchIntrusionDetectionJobUserSecret},
+			operatorv1.IntrusionDetectionDependency{Kind: secretDependencyKind, Namespace: ns, Name: relasticsearch.PublicCertSecret},
+			operatorv1.IntrusionDetectionDependency{Kind: secretDependencyKind, Namespace: ns, Name: render.TigeraLinseedSecret},
+			operatorv1.IntrusionDetectionDependency{Kind: secretDependencyKind, Namespace: ns, Name: render.ManagerInternalTLSSecretName},
+		)
+	}
+	if ref := instance.Spec.ExternalElasticsearchCABundle; ref != nil && elasticExternal {
+		deps = append(deps, operatorv1.IntrusionDetectionDependency{Kind: configMapDependencyKind, Namespace: ns, Name: ref.Name})
+	}
+	if ref := instance.Spec.DPITyphaCABundle; ref != nil && isDefaultInstance(instance) {
+		deps = append(deps, operatorv1.IntrusionDetectionDependency{Kind: configMapDependencyKind, Namespace: ns, Name: ref.Name})
+	}
+	for _, ref := range instance.Spec.InstallerCABundles {
+		deps = append(deps, operatorv1.IntrusionDetectionDependency{Kind: configMapDependencyKind, Namespace: ns, Name: ref.Name})
+	}
+	if webhook := instance.Spec.FindingsWebhook; webhook != nil && webhook.AuthSecret != nil {
+		deps = append(deps, operatorv1.IntrusionDetectionDependency{Kind: secretDependencyKind, Namespace: ns, Name: webhook.AuthSecret.Name})
+	}
+	for i := range deps {
+		present, err := r.dependencyExists(ctx, deps[i])
+		if err != nil {
+			return nil, err
+		}
+		deps[i].Present = present
+	}
+
+	// Detection rule ConfigMaps are read from the operator namespace when they are there, and otherwise from the
+	// namespace of the components. A missing one is reported in the operator namespace.
+	for _, name := range instance.Spec.AdditionalDetectionRuleConfigMaps {
+		dep := operatorv1.IntrusionDetectionDependency{Kind: configMapDependencyKind, Namespace: ns, Name: name}
+		for _, namespace := range []string{ns, installNamespace} {
+			candidate := operatorv1.IntrusionDetectionDependency{Kind: configMapDependencyKind, Namespace: namespace, Name: name}
+			present, err := r.dependencyExists(ctx, candidate)
+			if err != nil {
+				return nil, err
+			}
+			if present {
+				dep = candidate
+				dep.Present = true
+				break
+			}
+		}
+		deps = append(deps, dep)
+	}
+	return deps, nil
+}
+
+// dependencyExists returns whether the given dependency exists.
+func (r *ReconcileIntrusionDetection) dependencyExists(ctx context.Context, dep operatorv1.IntrusionDetectionDependency) (bool, error) {
+	var obj client.Object = &corev1.Secret{}
+	if dep.Kind == configMapDependencyKind {
+		obj = &corev1.ConfigMap{}
+	}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: dep.Name, Namespace: dep.Namespace}, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// setDependencies updates the dependencies in the status of the IntrusionDetection, if they have changed.
+func (r *ReconcileIntrusionDetection) setDependencies(ctx context.Context, instance *operatorv1.IntrusionDetection, deps []operatorv1.IntrusionDetectionDependency) error {
+	if reflect.DeepEqual(instance.Status.Dependencies, deps) {
+		return nil
+	}
+	return r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+		s.Dependencies = deps
+	})
+}
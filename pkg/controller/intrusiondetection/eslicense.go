@@ -0,0 +1,62 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"fmt"
+	"strings"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// eckLicenseLevels orders the ECK license levels by the Elasticsearch features they support. A trial supports the
+// features of an enterprise license.
+var eckLicenseLevels = map[render.ElasticsearchLicenseType]int{
+	render.ElasticsearchLicenseTypeBasic:           0,
+	render.ElasticsearchLicenseTypeEnterprise:      1,
+	render.ElasticsearchLicenseTypeEnterpriseTrial: 1,
+}
+
+// eckLicenseRequirement is an intrusion detection feature that relies on Elasticsearch features that need a minimum
+// ECK license level.
+type eckLicenseRequirement struct {
+	field   string
+	license render.ElasticsearchLicenseType
+	enabled func(*operatorv1.IntrusionDetection) bool
+}
+
+// eckLicenseRequirements lists the intrusion detection features that need more than a basic ECK license. None of the
+// features the operator configures do at the moment, so the list is empty until one is added.
+var eckLicenseRequirements []eckLicenseRequirement
+
+// checkECKLicense returns an error naming the configured features of the IntrusionDetection that the given ECK license
+// level doesn't support. A license level that the operator doesn't recognize is assumed to support them all.
+func checkECKLicense(instance *operatorv1.IntrusionDetection, license render.ElasticsearchLicenseType) error {
+	level, ok := eckLicenseLevels[license]
+	if !ok {
+		return nil
+	}
+	var unsupported []string
+	for _, req := range eckLicenseRequirements {
+		if req.enabled(instance) && level < eckLicenseLevels[req.license] {
+			unsupported = append(unsupported, fmt.Sprintf("%s needs an %s license", req.field, req.license))
+		}
+	}
+	if len(unsupported) == 0 {
+		return nil
+	}
+	return fmt.Errorf("the ECK license level is %s, but %s; upgrade the ECK license or disable these features", license, strings.Join(unsupported, ", "))
+}
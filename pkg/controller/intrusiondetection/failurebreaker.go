@@ -0,0 +1,91 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"sync"
+	"time"
+
+	"github.com/tigera/operator/pkg/controller/utils"
+)
+
+const (
+	// failureBreakerThreshold is the number of consecutive reconciles failing with the same error after which the
+	// controller backs off.
+	failureBreakerThreshold = 3
+
+	// failureBreakerMaxBackoff bounds the backoff, which starts at utils.StandardRetry and doubles with every further
+	// failure.
+	failureBreakerMaxBackoff = 10 * time.Minute
+)
+
+// failureBreaker tracks the consecutive reconciles of each IntrusionDetection that fail with the same error, so that a
+// permanent failure, such as a misconfiguration, is retried with an exponential backoff rather than in a tight loop.
+// A nil failureBreaker never backs off.
+type failureBreaker struct {
+	lock     sync.Mutex
+	failures map[string]consecutiveFailures
+}
+
+// consecutiveFailures is the error that the reconciles of an IntrusionDetection last failed with, and how many times
+// in a row they did.
+type consecutiveFailures struct {
+	lastErr string
+	count   int
+}
+
+func newFailureBreaker() *failureBreaker {
+	return &failureBreaker{failures: map[string]consecutiveFailures{}}
+}
+
+// failed records a failed reconcile of the given IntrusionDetection and returns how long to back off for, which is
+// zero until the same error has been seen failureBreakerThreshold times in a row. It also returns the number of
+// consecutive failures.
+func (b *failureBreaker) failed(name string, err error) (time.Duration, int) {
+	if b == nil {
+		return 0, 0
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	f := b.failures[name]
+	if err.Error() != f.lastErr {
+		f = consecutiveFailures{lastErr: err.Error()}
+	}
+	f.count++
+	b.failures[name] = f
+	if f.count < failureBreakerThreshold {
+		return 0, f.count
+	}
+
+	backoff := utils.StandardRetry
+	for i := failureBreakerThreshold; i < f.count && backoff < failureBreakerMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > failureBreakerMaxBackoff {
+		backoff = failureBreakerMaxBackoff
+	}
+	return backoff, f.count
+}
+
+// reset records a successful reconcile of the given IntrusionDetection.
+func (b *failureBreaker) reset(name string) {
+	if b == nil {
+		return
+	}
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	delete(b.failures, name)
+}
@@ -0,0 +1,86 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+
+	"github.com/elastic/cloud-on-k8s/v2/pkg/utils/stringsutil"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// completedInstallerJobHash records in the status of the IntrusionDetection the configuration that the installer Job
+// has completed with. Once that Job has been deleted, e.g. by its TTL, it returns the hash of that configuration, so
+// that the Job is not recreated and the installer doesn't run again until its configuration changes. It returns an
+// empty hash while the Job exists.
+//
+// The installer Job carries a finalizer, so a Job that is deleted before its completion was seen is held back until
+// then. This releases it once its completion has been recorded.
+func (r *ReconcileIntrusionDetection) completedInstallerJobHash(ctx context.Context, instance *operatorv1.IntrusionDetection, namespace string) (string, error) {
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: render.IntrusionDetectionInstallerJobName, Namespace: namespace}, job); err != nil {
+		if errors.IsNotFound(err) {
+			return instance.Status.InstallerJobCompletedHash, nil
+		}
+		return "", err
+	}
+
+	hash := job.Annotations[render.InstallerJobHashAnnotation]
+	if hash != "" && jobCompleted(job) && hash != instance.Status.InstallerJobCompletedHash {
+		if err := r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+			s.InstallerJobCompletedHash = hash
+		}); err != nil {
+			return "", err
+		}
+	}
+	if job.DeletionTimestamp == nil {
+		return "", nil
+	}
+	if err := r.releaseInstallerJob(ctx, job); err != nil {
+		return "", err
+	}
+	return instance.Status.InstallerJobCompletedHash, nil
+}
+
+// releaseInstallerJob removes the finalizer from the given installer Job, so that it can be deleted.
+func (r *ReconcileIntrusionDetection) releaseInstallerJob(ctx context.Context, job *batchv1.Job) error {
+	if !stringsutil.StringInSlice(render.InstallerJobFinalizer, job.GetFinalizers()) {
+		return nil
+	}
+	prePatch := client.MergeFrom(job.DeepCopy())
+	job.SetFinalizers(stringsutil.RemoveStringInSlice(render.InstallerJobFinalizer, job.GetFinalizers()))
+	if err := r.client.Patch(ctx, job, prePatch); err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// jobCompleted returns whether the given Job has completed successfully.
+func jobCompleted(job *batchv1.Job) bool {
+	for _, c := range job.Status.Conditions {
+		if c.Type == batchv1.JobComplete && c.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
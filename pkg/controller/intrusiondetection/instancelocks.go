@@ -0,0 +1,46 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import "sync"
+
+// instanceLocks holds a lock for each IntrusionDetection, so that the controller can reconcile several requests at the
+// same time without two of them reconciling the same IntrusionDetection at once. A nil instanceLocks doesn't lock.
+type instanceLocks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+func newInstanceLocks() *instanceLocks {
+	return &instanceLocks{locks: map[string]*sync.Mutex{}}
+}
+
+// lock locks the given IntrusionDetection, waiting for any reconcile of it that is in progress, and returns the
+// function that unlocks it.
+func (l *instanceLocks) lock(name string) func() {
+	if l == nil {
+		return func() {}
+	}
+	l.mu.Lock()
+	m, ok := l.locks[name]
+	if !ok {
+		m = &sync.Mutex{}
+		l.locks[name] = m
+	}
+	l.mu.Unlock()
+
+	m.Lock()
+	return m.Unlock
+}
@@ -16,17 +16,33 @@ package intrusiondetection
 
 import (
 	"context"
+	"crypto/x509"
+	stderrors "errors"
 	"fmt"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
 	esv1 "github.com/elastic/cloud-on-k8s/v2/pkg/apis/elasticsearch/v1"
+	"github.com/elastic/cloud-on-k8s/v2/pkg/utils/stringsutil"
+	"github.com/go-logr/logr"
 
 	"github.com/tigera/operator/pkg/render/common/networkpolicy"
 
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apimachinery/pkg/util/wait"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/controller/installation"
 	"github.com/tigera/operator/pkg/controller/logcollector"
@@ -34,30 +50,63 @@ import (
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
-	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rintrusiondetection "github.com/tigera/operator/pkg/render/intrusiondetection"
 	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
+	apivalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
 
 const ResourceName = "intrusion-detection"
 
+// IntrusionDetectionFinalizer is added to the IntrusionDetection resource so that the objects created for it are cleaned
+// up before it is removed.
+const IntrusionDetectionFinalizer = "tigera.io/intrusion-detection-cleanup"
+
+// VersionConditionType is the type of the IntrusionDetection status condition that reports the release and component
+// versions the operator is managing.
+const VersionConditionType = "Version"
+
+// DependenciesReadyConditionType is the type of the IntrusionDetection status condition that tells whether the
+// dependencies of intrusion detection, such as the license or the Elasticsearch secrets, are in place. While it is
+// false, its reason and message name the dependency that is being waited on. Whether the components themselves are
+// available is reported by the Ready condition, which is copied from the TigeraStatus.
+const (
+	DependenciesReadyConditionType = "DependenciesReady"
+
+	// AllDependenciesReadyReason is the reason of the DependenciesReady condition when all dependencies are in place.
+	AllDependenciesReadyReason = "AllDependenciesReady"
+)
+
+// certificateExpiryWarningPeriod is how long before the expiry of a certificate used by intrusion detection a warning
+// is logged.
+const certificateExpiryWarningPeriod = 30 * 24 * time.Hour
+
 var log = logf.Log.WithName("controller_intrusiondetection")
 
 // Add creates a new IntrusionDetection Controller and adds it to the Manager. The Manager will set fields on the Controller
@@ -73,10 +122,16 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	tierWatchReady := &utils.ReadyFlag{}
 
 	// create the reconciler
-	reconciler := newReconciler(mgr, opts, licenseAPIReady, dpiAPIReady, tierWatchReady)
+	reconciler, err := newReconciler(mgr, opts, licenseAPIReady, dpiAPIReady, tierWatchReady)
+	if err != nil {
+		return fmt.Errorf("failed to create intrusiondetection-controller: %w", err)
+	}
 
 	// Create a new controller
-	controller, err := controller.New("intrusiondetection-controller", mgr, controller.Options{Reconciler: reconcile.Reconciler(reconciler)})
+	controller, err := controller.New("intrusiondetection-controller", mgr, controller.Options{
+		Reconciler:              reconcile.Reconciler(reconciler),
+		MaxConcurrentReconciles: opts.IntrusionDetectionMaxConcurrentReconciles,
+	})
 	if err != nil {
 		return fmt.Errorf("failed to create intrusiondetection-controller: %v", err)
 	}
@@ -87,18 +142,33 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		return err
 	}
 
-	go utils.WaitToAddLicenseKeyWatch(controller, k8sClient, log, licenseAPIReady)
+	// Reconcile every IntrusionDetection as soon as the license changes, since the features it grants gate them all.
+	go utils.WaitToAddLicenseKeyWatchWithHandler(controller, k8sClient, log, licenseAPIReady,
+		handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+			return []reconcile.Request{{}}
+		}))
 
 	go utils.WaitToAddResourceWatch(controller, k8sClient, log, dpiAPIReady,
 		[]client.Object{&v3.DeepPacketInspection{TypeMeta: metav1.TypeMeta{Kind: v3.KindDeepPacketInspection}}})
 
 	go utils.WaitToAddTierWatch(networkpolicy.TigeraComponentTierName, controller, k8sClient, log, tierWatchReady)
+	// The policies of the components are watched in the namespaces of all IntrusionDetections.
+	policyNames := []string{
+		render.IntrusionDetectionControllerPolicyName,
+		render.IntrusionDetectionInstallerPolicyName,
+		render.ADAPIPolicyName,
+		render.ADDetectorPolicyName,
+		networkpolicy.TigeraComponentDefaultDenyPolicyName,
+	}
+	policies := []client.Object{}
+	for _, name := range policyNames {
+		policies = append(policies, &v3.NetworkPolicy{
+			TypeMeta:   metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: render.IntrusionDetectionNamespace},
+		})
+	}
+	go utils.WaitToAddResourceWatchWithHandler(controller, k8sClient, log, nil, policies, &handler.EnqueueRequestForObject{}, inInstanceNamespaceNamed(policyNames...))
 	go utils.WaitToAddNetworkPolicyWatches(controller, k8sClient, log, []types.NamespacedName{
-		{Name: render.IntrusionDetectionControllerPolicyName, Namespace: render.IntrusionDetectionNamespace},
-		{Name: render.IntrusionDetectionInstallerPolicyName, Namespace: render.IntrusionDetectionNamespace},
-		{Name: render.ADAPIPolicyName, Namespace: render.IntrusionDetectionNamespace},
-		{Name: render.ADDetectorPolicyName, Namespace: render.IntrusionDetectionNamespace},
-		{Name: networkpolicy.TigeraComponentDefaultDenyPolicyName, Namespace: render.IntrusionDetectionNamespace},
 		{Name: dpi.DeepPacketInspectionPolicyName, Namespace: dpi.DeepPacketInspectionNamespace},
 	})
 
@@ -106,21 +176,42 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 }
 
 // newReconciler returns a new reconcile.Reconciler
-func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, dpiAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) reconcile.Reconciler {
+func newReconciler(mgr manager.Manager, opts options.AddOptions, licenseAPIReady *utils.ReadyFlag, dpiAPIReady *utils.ReadyFlag, tierWatchReady *utils.ReadyFlag) (reconcile.Reconciler, error) {
+	cli := mgr.GetClient()
+	if opts.IntrusionDetectionQPS > 0 {
+		var err error
+		if cli, err = newRateLimitedClient(mgr, opts.IntrusionDetectionQPS, opts.IntrusionDetectionBurst); err != nil {
+			return nil, err
+		}
+	}
+	// Additional IntrusionDetections each report in a TigeraStatus of their own.
+	statuses := newInstanceStatuses(func(component string) status.StatusManager {
+		sm := status.New(mgr.GetClient(), component, opts.KubernetesVersion)
+		sm.Run(opts.ShutdownContext)
+		return sm
+	})
 	r := &ReconcileIntrusionDetection{
-		client:          mgr.GetClient(),
-		scheme:          mgr.GetScheme(),
-		provider:        opts.DetectedProvider,
-		status:          status.New(mgr.GetClient(), "intrusion-detection", opts.KubernetesVersion),
-		clusterDomain:   opts.ClusterDomain,
-		licenseAPIReady: licenseAPIReady,
-		dpiAPIReady:     dpiAPIReady,
-		tierWatchReady:  tierWatchReady,
-		usePSP:          opts.UsePSP,
-		elasticExternal: opts.ElasticExternal,
+		client:             cli,
+		scheme:             mgr.GetScheme(),
+		provider:           opts.DetectedProvider,
+		status:             status.New(mgr.GetClient(), "intrusion-detection", opts.KubernetesVersion),
+		clusterDomain:      opts.ClusterDomain,
+		licenseAPIReady:    licenseAPIReady,
+		dpiAPIReady:        dpiAPIReady,
+		tierWatchReady:     tierWatchReady,
+		usePSP:             opts.UsePSP,
+		syncPeriod:         opts.IntrusionDetectionSyncPeriod,
+		requeueJitter:      opts.IntrusionDetectionRequeueJitter,
+		failures:           newFailureBreaker(),
+		statuses:           statuses,
+		missingSecrets:     newAbsenceTracker(),
+		instanceLocks:      newInstanceLocks(),
+		connectivityCheck:  validateElasticsearchCredentials,
+		connectivityChecks: newCheckThrottle(),
+		credentialChecks:   newCredentialCache(),
 	}
 	r.status.Run(opts.ShutdownContext)
-	return r
+	return r, nil
 }
 
 // add adds watches for resources that are available at startup
@@ -133,14 +224,18 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		return fmt.Errorf("intrusiondetection-controller failed to watch primary resource: %v", err)
 	}
 
-	err = c.Watch(&source.Kind{Type: &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
-		Namespace: render.IntrusionDetectionNamespace,
-		Name:      render.IntrusionDetectionInstallerJobName,
-	}}}, &handler.EnqueueRequestForObject{})
+	// The installer Job and CronJob are watched in the namespaces of all IntrusionDetections.
+	installer := inInstanceNamespaceNamed(render.IntrusionDetectionInstallerJobName)
+	err = c.Watch(&source.Kind{Type: &batchv1.Job{}}, &handler.EnqueueRequestForObject{}, installer)
 	if err != nil {
 		return fmt.Errorf("intrusiondetection-controller failed to watch installer job: %v", err)
 	}
 
+	err = c.Watch(&source.Kind{Type: &batchv1.CronJob{}}, &handler.EnqueueRequestForObject{}, installer)
+	if err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch installer cronjob: %v", err)
+	}
+
 	// Watch for changes to to primary resource LogCollector, to determine if syslog forwarding is
 	// turned on or off.
 	err = c.Watch(&source.Kind{Type: &operatorv1.LogCollector{}}, &handler.EnqueueRequestForObject{})
@@ -181,6 +276,9 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		render.TyphaTLSSecretName,
 		render.TigeraLinseedSecret,
 		render.VoltronLinseedPublicCert,
+		render.IntrusionDetectionTLSSecretName,
+		render.DPITLSSecretName,
+		render.IntrusionDetectionMetricsTLSSecretName,
 		certificatemanagement.CASecretName,
 	} {
 		if err = utils.AddSecretsWatch(c, secretName, common.OperatorNamespace()); err != nil {
@@ -188,39 +286,221 @@ func add(mgr manager.Manager, c controller.Controller) error {
 		}
 	}
 
-	if err = utils.AddSecretsWatch(c, render.ManagerInternalTLSSecretName, render.IntrusionDetectionNamespace); err != nil {
+	// These watches are here to catch a modification to the resources we create in reconcile so the changes would be
+	// corrected. They cover the namespaces of all IntrusionDetections.
+	copiedSecrets := inInstanceNamespaceNamed(render.ManagerInternalTLSSecretName, relasticsearch.PublicCertSecret, render.TigeraLinseedSecret)
+	if err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestForObject{}, copiedSecrets); err != nil {
 		return fmt.Errorf("intrusiondetection-controller failed to watch the Secret resource: %v", err)
 	}
 
-	// These watches are here to catch a modification to the resources we create in reconcile so the changes would be corrected.
-	if err = utils.AddSecretsWatch(c, relasticsearch.PublicCertSecret, render.IntrusionDetectionNamespace); err != nil {
-		return fmt.Errorf("intrusiondetection-controller failed to watch the Secret resource: %v", err)
+	if err = utils.AddConfigMapWatch(c, relasticsearch.ClusterConfigConfigMapName, common.OperatorNamespace(), &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
 	}
 
-	if err = utils.AddSecretsWatch(c, render.TigeraLinseedSecret, render.IntrusionDetectionNamespace); err != nil {
-		return fmt.Errorf("intrusiondetection-controller failed to watch the Secret resource: %v", err)
+	if err = utils.AddConfigMapWatch(c, render.ECKLicenseConfigMapName, render.ECKOperatorNamespace, &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
 	}
 
-	if err = utils.AddConfigMapWatch(c, relasticsearch.ClusterConfigConfigMapName, common.OperatorNamespace(), &handler.EnqueueRequestForObject{}); err != nil {
+	if err = utils.AddConfigMapWatch(c, render.TyphaCAConfigMapName, common.OperatorNamespace(), &handler.EnqueueRequestForObject{}); err != nil {
 		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
 	}
 
-	if err = utils.AddConfigMapWatch(c, render.ECKLicenseConfigMapName, render.ECKOperatorNamespace, &handler.EnqueueRequestForObject{}); err != nil {
+	if err = utils.AddConfigMapWatch(c, utils.MaintenanceConfigMapName, common.OperatorNamespace(), &handler.EnqueueRequestForObject{}); err != nil {
 		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
 	}
 
-	if err = utils.AddConfigMapWatch(c, render.TyphaCAConfigMapName, common.OperatorNamespace(), &handler.EnqueueRequestForObject{}); err != nil {
+	if err = utils.AddConfigMapWatch(c, utils.ProxyConfigMapName, common.OperatorNamespace(), &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
+	}
+
+	// Watch for changes to the ConfigMaps referenced by the IntrusionDetection specs. The references are resolved
+	// against the current specs for every event, so a ConfigMap that is no longer referenced stops triggering
+	// reconciles.
+	if err = c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, handler.EnqueueRequestsFromMapFunc(referencedConfigMapRequests(mgr.GetClient()))); err != nil {
 		return fmt.Errorf("intrusiondetection-controller failed to watch the ConfigMap resource: %v", err)
 	}
 
+	// Likewise for the Secrets referenced by the IntrusionDetection specs.
+	if err = c.Watch(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(referencedSecretRequests(mgr.GetClient()))); err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the Secret resource: %v", err)
+	}
+
+	// Watch for changes to the labels of namespaces, which select the DeepPacketInspection resources that are acted
+	// on when an IntrusionDetection has a DPINamespaceSelector. Only the IntrusionDetections whose selector the change
+	// moves the namespace in or out of are reconciled.
+	namespaceSelectionRequests := dpiNamespaceSelectionRequests(mgr.GetClient())
+	err = c.Watch(&source.Kind{Type: &corev1.Namespace{}}, handler.Funcs{
+		UpdateFunc: func(e event.UpdateEvent, q workqueue.RateLimitingInterface) {
+			for _, req := range namespaceSelectionRequests(e.ObjectOld, e.ObjectNew) {
+				q.Add(req)
+			}
+		},
+	}, predicate.LabelChangedPredicate{})
+	if err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch namespaces: %v", err)
+	}
+
+	// Watch the allocatable resources of the nodes, which the scaled DeepPacketInspection resource defaults follow.
+	nodeAllocatable := predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldNode, newNode := e.ObjectOld.(*corev1.Node), e.ObjectNew.(*corev1.Node)
+			return oldNode.Labels["kubernetes.io/os"] != newNode.Labels["kubernetes.io/os"] ||
+				!oldNode.Status.Allocatable.Cpu().Equal(*newNode.Status.Allocatable.Cpu()) ||
+				!oldNode.Status.Allocatable.Memory().Equal(*newNode.Status.Allocatable.Memory())
+		},
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Node{}}, handler.EnqueueRequestsFromMapFunc(scaledDPIResourceRequests(mgr.GetClient())), nodeAllocatable)
+	if err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the Node resource: %v", err)
+	}
+
+	// Watch the Deployments and pods of the components, so that the ReplicasReady condition follows their rollout.
+	inInstanceNamespace := predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return isIntrusionDetectionNamespace(object.GetNamespace())
+	})
+	err = c.Watch(&source.Kind{Type: &appsv1.Deployment{}}, handler.EnqueueRequestsFromMapFunc(instanceRequestsForNamespace(mgr.GetClient())), inInstanceNamespace)
+	if err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the Deployment resource: %v", err)
+	}
+	err = c.Watch(&source.Kind{Type: &corev1.Pod{}}, handler.EnqueueRequestsFromMapFunc(instanceRequestsForNamespace(mgr.GetClient())), inInstanceNamespace)
+	if err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the Pod resource: %v", err)
+	}
+
 	// Watch for changes to TigeraStatus.
 	if err = utils.AddTigeraStatusWatch(c, ResourceName); err != nil {
 		return fmt.Errorf("intrusiondetection-controller failed to watch intrusion-detection Tigerastatus: %w", err)
 	}
 
+	// Watch for changes to the TigeraStatuses of the additional IntrusionDetections.
+	err = c.Watch(&source.Kind{Type: &operatorv1.TigeraStatus{}}, &handler.EnqueueRequestForObject{}, predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return strings.HasPrefix(object.GetName(), ResourceName+"-")
+	}))
+	if err != nil {
+		return fmt.Errorf("intrusiondetection-controller failed to watch the Tigerastatus of additional IntrusionDetections: %w", err)
+	}
+
 	return nil
 }
 
+// dpiNamespaceSelectionRequests returns a function that maps a change to the labels of a namespace to a request for
+// every IntrusionDetection whose DPINamespaceSelector selects the namespace either before or after the change, but not
+// both.
+func dpiNamespaceSelectionRequests(cli client.Client) func(oldObj, newObj client.Object) []reconcile.Request {
+	return func(oldObj, newObj client.Object) []reconcile.Request {
+		list := &operatorv1.IntrusionDetectionList{}
+		if err := cli.List(context.Background(), list); err != nil {
+			log.Error(err, "Failed to list IntrusionDetections for a namespace change", "Namespace", newObj.GetName())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, ids := range list.Items {
+			if ids.Spec.DPINamespaceSelector == nil {
+				continue
+			}
+			selector, err := metav1.LabelSelectorAsSelector(ids.Spec.DPINamespaceSelector)
+			if err != nil {
+				// An invalid selector is reported by the reconcile of the IntrusionDetection itself.
+				continue
+			}
+			if selector.Matches(labels.Set(oldObj.GetLabels())) != selector.Matches(labels.Set(newObj.GetLabels())) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ids.Name}})
+			}
+		}
+		return requests
+	}
+}
+
+// scaledDPIResourceRequests returns a function that maps a node to a request for every IntrusionDetection whose
+// DeepPacketInspection resource defaults scale with the nodes.
+func scaledDPIResourceRequests(cli client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		list := &operatorv1.IntrusionDetectionList{}
+		if err := cli.List(context.Background(), list); err != nil {
+			log.Error(err, "Failed to list IntrusionDetections for a node change", "Node", obj.GetName())
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for _, ids := range list.Items {
+			if ids.Spec.ScaleDPIResourceDefaults != nil && *ids.Spec.ScaleDPIResourceDefaults {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: ids.Name}})
+			}
+		}
+		return requests
+	}
+}
+
+// referencedConfigMapRequests returns a function that maps a ConfigMap to a request for every IntrusionDetection whose
+// spec references it.
+func referencedConfigMapRequests(cli client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		list := &operatorv1.IntrusionDetectionList{}
+		if err := cli.List(context.Background(), list); err != nil {
+			log.Error(err, "Failed to list IntrusionDetections for a ConfigMap change", "ConfigMap", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range list.Items {
+			if referencesConfigMap(&list.Items[i], obj.GetNamespace(), obj.GetName()) {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: list.Items[i].Name}})
+			}
+		}
+		return requests
+	}
+}
+
+// referencedSecretRequests returns a function that maps a Secret to a request for every IntrusionDetection whose spec
+// references it.
+func referencedSecretRequests(cli client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		if obj.GetNamespace() != common.OperatorNamespace() {
+			return nil
+		}
+		list := &operatorv1.IntrusionDetectionList{}
+		if err := cli.List(context.Background(), list); err != nil {
+			log.Error(err, "Failed to list IntrusionDetections for a Secret change", "Secret", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+
+		var requests []reconcile.Request
+		for i := range list.Items {
+			if webhook := list.Items[i].Spec.FindingsWebhook; webhook != nil && webhook.AuthSecret != nil && webhook.AuthSecret.Name == obj.GetName() {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: list.Items[i].Name}})
+			}
+		}
+		return requests
+	}
+}
+
+// referencesConfigMap returns true if the spec of the IntrusionDetection references the given ConfigMap.
+func referencesConfigMap(instance *operatorv1.IntrusionDetection, namespace, name string) bool {
+	if namespace == common.OperatorNamespace() {
+		// Every IntrusionDetection is rendered for the Elasticsearch mode in the bootstrap configuration.
+		if name == utils.BootstrapConfigMapName {
+			return true
+		}
+		if ref := instance.Spec.ExternalElasticsearchCABundle; ref != nil && ref.Name == name {
+			return true
+		}
+		if ref := instance.Spec.DPITyphaCABundle; ref != nil && ref.Name == name {
+			return true
+		}
+		for _, ref := range instance.Spec.InstallerCABundles {
+			if ref.Name == name {
+				return true
+			}
+		}
+	}
+	// Detection rule ConfigMaps are read from either the operator namespace or the namespace of the components.
+	if namespace == common.OperatorNamespace() || namespace == instanceNamespace(instance) {
+		return stringsutil.StringInSlice(name, instance.Spec.AdditionalDetectionRuleConfigMaps)
+	}
+	return false
+}
+
 // blank assignment to verify that ReconcileIntrusionDetection implements reconcile.Reconciler
 var _ reconcile.Reconciler = &ReconcileIntrusionDetection{}
 
@@ -237,7 +517,45 @@ type ReconcileIntrusionDetection struct {
 	dpiAPIReady     *utils.ReadyFlag
 	tierWatchReady  *utils.ReadyFlag
 	usePSP          bool
-	elasticExternal bool
+
+	// syncPeriod is how long to wait before reconciling again after a successful reconcile. When zero, the
+	// controller only reconciles in response to watch events.
+	syncPeriod time.Duration
+
+	// requeueJitter is the maximum fraction by which the delay of a requeue is randomly lengthened. When zero, requeues
+	// happen exactly after the requested delay.
+	requeueJitter float64
+
+	// failures tracks the consecutive failed reconciles of each IntrusionDetection, to back off when the same failure
+	// keeps recurring.
+	failures *failureBreaker
+
+	// statuses holds the status managers of the additional IntrusionDetections. The default IntrusionDetection reports
+	// in status.
+	statuses *instanceStatuses
+
+	// missingSecrets tracks since when the Elasticsearch secrets of each IntrusionDetection have been missing, for the
+	// missing secret grace period.
+	missingSecrets *absenceTracker
+
+	// instanceLocks serializes the reconciles of each IntrusionDetection, since requests that apply to the same one,
+	// e.g. for the IntrusionDetection and for a watched Secret, may be reconciled at the same time.
+	instanceLocks *instanceLocks
+
+	// credentialValidator tests the Elasticsearch credentials when ValidateElasticsearchCredentials is set. When nil,
+	// validateElasticsearchCredentials is used.
+	credentialValidator credentialValidator
+
+	// connectivityCheck tests whether Elasticsearch can be reached, for the ElasticsearchReachable condition. When nil,
+	// the check is skipped and the condition is not set.
+	connectivityCheck credentialValidator
+
+	// connectivityChecks limits how often connectivityCheck runs for each IntrusionDetection.
+	connectivityChecks *checkThrottle
+
+	// credentialChecks remembers the credentials that Elasticsearch accepted for each IntrusionDetection, so that they
+	// are only tested again once they change or the sync period has passed.
+	credentialChecks *credentialCache
 }
 
 // Reconcile reads that state of the cluster for a IntrusionDetection object and makes changes based on the state read
@@ -246,47 +564,321 @@ type ReconcileIntrusionDetection struct {
 // The Controller will requeue the Request to be processed again if the returned error is non-nil or
 // Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
 func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request reconcile.Request) (reconcile.Result, error) {
-	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name)
+	// Tag every log line of this reconcile with a correlation ID so that a single reconcile can be followed through the
+	// logs. The logger is also stored in the context for the helpers that are called from here.
+	reqLogger := log.WithValues("Request.Namespace", request.Namespace, "Request.Name", request.Name, "CorrelationID", uuid.NewUUID())
+	ctx = logf.IntoContext(ctx, reqLogger)
 	reqLogger.Info("Reconciling IntrusionDetection")
 
-	// Fetch the IntrusionDetection instance
-	instance := &operatorv1.IntrusionDetection{}
-	err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
+	// Fetch the IntrusionDetection instances
+	instances, err := r.instancesToReconcile(ctx, request)
 	if err != nil {
-		if errors.IsNotFound(err) {
-			reqLogger.V(3).Info("IntrusionDetection CR not found", "err", err)
-			// Request object not found, could have been deleted after reconcile request.
-			// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
-			// Return and don't requeue
-			r.status.OnCRNotFound()
-			return reconcile.Result{}, nil
-		}
 		r.status.SetDegraded(operatorv1.ResourceReadError, "Error querying IntrusionDetection", err, reqLogger)
+		if delay, ok := throttleDelay(err); ok {
+			return r.throttled(delay, reqLogger), nil
+		}
 		// Error reading the object - requeue the request.
 		return reconcile.Result{}, err
 	}
-	r.status.OnCRFound()
+
+	// Each IntrusionDetection is reconciled as a stack of its own, so a failure of one doesn't hold back the others. The
+	// errors are returned together once all of them have been reconciled, and otherwise the request is requeued for the
+	// soonest of the requested times.
+	var result reconcile.Result
+	var errs []error
+	var throttle time.Duration
+	deleted := 0
+	for i := range instances {
+		instance := &instances[i]
+		if instance.DeletionTimestamp != nil {
+			deleted++
+		}
+		unlock := r.instanceLocks.lock(instance.Name)
+		instanceLogger := reqLogger.WithValues("IntrusionDetection", instance.Name)
+		summary := &reconcileSummary{}
+		res, err := r.reconcileInstance(ctx, request, instance, summary, instanceLogger)
+		if instance.DeletionTimestamp == nil {
+			r.setSummary(ctx, instance, summary, err, instanceLogger)
+		}
+		unlock()
+		if err != nil {
+			// An overloaded API server says how long to wait, which beats both an immediate retry and the backoff.
+			if delay, ok := throttleDelay(err); ok {
+				if delay > throttle {
+					throttle = delay
+				}
+				continue
+			}
+			// Rather than retrying a failure that keeps recurring in a tight loop, back off and say so in the status.
+			// The error is not returned then, since that would requeue the request right away.
+			if backoff, failures := r.failures.failed(instance.Name, err); backoff > 0 {
+				// Keep the reason of the failure, rather than hiding it behind the backoff.
+				reason := summary.degradedReason
+				if reason == "" {
+					reason = operatorv1.Unknown
+				}
+				r.statusFor(instance).SetDegraded(reason, fmt.Sprintf("Reconcile failed %d times in a row with the same error, retrying in %s", failures, backoff), err, instanceLogger)
+				res = reconcile.Result{RequeueAfter: backoff}
+			} else {
+				errs = append(errs, err)
+				continue
+			}
+		} else {
+			r.failures.reset(instance.Name)
+			// The status of the default IntrusionDetection is cleared below, once there are none left.
+			if instance.DeletionTimestamp != nil && !isDefaultInstance(instance) {
+				r.statusFor(instance).OnCRNotFound()
+			}
+		}
+		if res.RequeueAfter > 0 && (result.RequeueAfter == 0 || res.RequeueAfter < result.RequeueAfter) {
+			result.RequeueAfter = res.RequeueAfter
+		}
+	}
+
+	if len(errs) > 0 {
+		return reconcile.Result{}, stderrors.Join(errs...)
+	}
+	if throttle > 0 {
+		return r.throttled(throttle, reqLogger), nil
+	}
+	if deleted == len(instances) {
+		reqLogger.V(3).Info("IntrusionDetection CR not found")
+		// Request object not found, could have been deleted after reconcile request.
+		// Owned objects are automatically garbage collected. For additional cleanup logic use finalizers.
+		// Return and don't requeue
+		r.status.OnCRNotFound()
+		return reconcile.Result{}, nil
+	}
+	// Requeues of reconciles that were triggered together, e.g. by the license changing, would otherwise all fire at
+	// the same time. Errors are returned above without a delay, so the jitter never holds back a retry of a failure.
+	result.RequeueAfter = r.jitter(result.RequeueAfter)
+	return result, nil
+}
+
+// throttled returns the result that requeues a reconcile that the API server throttled after the given delay. The
+// delay is not jittered, since it is what the API server asked for.
+func (r *ReconcileIntrusionDetection) throttled(delay time.Duration, reqLogger logr.Logger) reconcile.Result {
+	reqLogger.Info("The API server is throttling requests, retrying the reconcile later", "retryAfter", delay)
+	return reconcile.Result{RequeueAfter: delay}
+}
+
+// jitter returns the given delay randomly lengthened by up to the requeue jitter fraction of it.
+func (r *ReconcileIntrusionDetection) jitter(d time.Duration) time.Duration {
+	if d <= 0 || r.requeueJitter <= 0 {
+		return d
+	}
+	return wait.Jitter(d, r.requeueJitter)
+}
+
+// instancesToReconcile returns the IntrusionDetections that a request applies to. A request for an IntrusionDetection
+// applies to that one only, while any other request, e.g. for a watched Secret, applies to all of them. The default
+// IntrusionDetection comes first.
+func (r *ReconcileIntrusionDetection) instancesToReconcile(ctx context.Context, request reconcile.Request) ([]operatorv1.IntrusionDetection, error) {
+	if request.Name != "" && request.Namespace == "" {
+		instance := operatorv1.IntrusionDetection{}
+		err := r.client.Get(ctx, request.NamespacedName, &instance)
+		if err == nil {
+			return []operatorv1.IntrusionDetection{instance}, nil
+		} else if !errors.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	list := &operatorv1.IntrusionDetectionList{}
+	if err := r.client.List(ctx, list); err != nil {
+		return nil, err
+	}
+	sort.Slice(list.Items, func(i, j int) bool {
+		if isDefaultInstance(&list.Items[i]) != isDefaultInstance(&list.Items[j]) {
+			return isDefaultInstance(&list.Items[i])
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list.Items, nil
+}
+
+// isDefaultInstance returns true if the IntrusionDetection is the default one, which also manages the cluster wide
+// components such as DeepPacketInspection.
+func isDefaultInstance(instance *operatorv1.IntrusionDetection) bool {
+	return instance.Name == utils.DefaultTSEEInstanceKey.Name
+}
+
+// instanceNamespace returns the namespace the components of an IntrusionDetection are installed into. The default
+// IntrusionDetection uses the well-known namespace, and any other one a namespace of its own.
+func instanceNamespace(instance *operatorv1.IntrusionDetection) string {
+	if isDefaultInstance(instance) {
+		return render.IntrusionDetectionNamespace
+	}
+	return render.IntrusionDetectionInstanceNamespace(instance.Name)
+}
+
+// inInstanceNamespaceNamed returns a predicate that matches the objects with one of the given names in the namespace
+// of any IntrusionDetection.
+func inInstanceNamespaceNamed(names ...string) predicate.Predicate {
+	return predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return stringsutil.StringInSlice(object.GetName(), names) && isIntrusionDetectionNamespace(object.GetNamespace())
+	})
+}
+
+// isIntrusionDetectionNamespace returns true if the namespace is the one of the default IntrusionDetection or could be
+// the one of an additional IntrusionDetection.
+func isIntrusionDetectionNamespace(namespace string) bool {
+	return namespace == render.IntrusionDetectionNamespace || strings.HasPrefix(namespace, render.IntrusionDetectionNamespace+"-")
+}
+
+// reconcileInstance reconciles the stack of a single IntrusionDetection, and records the health signals it comes across
+// in summary.
+func (r *ReconcileIntrusionDetection) reconcileInstance(ctx context.Context, request reconcile.Request, instance *operatorv1.IntrusionDetection, summary *reconcileSummary, reqLogger logr.Logger) (reconcile.Result, error) {
+	// The IntrusionDetection resource is cluster scoped, so the components are installed into a single-tenant
+	// namespace of their own.
+	helper := utils.NewSingleTenantNamespaceHelper(instanceNamespace(instance))
+	defaultInstance := isDefaultInstance(instance)
+	statusManager := degradedRecorder{StatusManager: r.statusFor(instance), summary: summary}
+
+	// Clean up the objects that garbage collection can't be relied upon to remove before letting the
+	// IntrusionDetection go.
+	if instance.DeletionTimestamp != nil {
+		if err := r.cleanup(ctx, instance, helper.InstallNamespace(), reqLogger); err != nil {
+			return reconcile.Result{}, err
+		}
+		r.connectivityChecks.forget(instance.Name)
+		r.credentialChecks.forget(instance.Name)
+		return reconcile.Result{}, nil
+	}
+	if !stringsutil.StringInSlice(IntrusionDetectionFinalizer, instance.GetFinalizers()) {
+		prePatch := client.MergeFrom(instance.DeepCopy())
+		instance.SetFinalizers(append(instance.GetFinalizers(), IntrusionDetectionFinalizer))
+		if err := r.client.Patch(ctx, instance, prePatch); err != nil {
+			statusManager.SetDegraded(operatorv1.ResourcePatchError, "Failed to set finalizer on IntrusionDetection", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
+	statusManager.OnCRFound()
 	reqLogger.V(2).Info("Loaded config", "config", instance)
 	// SetMetaData in the TigeraStatus such as observedGenerations.
-	defer r.status.SetMetaData(&instance.ObjectMeta)
+	defer statusManager.SetMetaData(&instance.ObjectMeta)
 
 	// Changes for updating IntrusionDetection status conditions
-	if request.Name == ResourceName && request.Namespace == "" {
+	if request.Name == tigeraStatusName(instance) && request.Namespace == "" {
 		ts := &operatorv1.TigeraStatus{}
-		err := r.client.Get(ctx, types.NamespacedName{Name: ResourceName}, ts)
+		err := r.client.Get(ctx, types.NamespacedName{Name: tigeraStatusName(instance)}, ts)
+		if err != nil {
+			return reconcile.Result{}, err
+		}
+		err = r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+			s.Conditions = status.UpdateStatusCondition(s.Conditions, ts.Status.Conditions)
+		})
 		if err != nil {
+			reqLogger.WithValues("reason", err).Info("Failed to create IntrusionDetection status conditions.")
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Leave the managed objects alone while the IntrusionDetection is paused. This is not an error, so clear any
+	// degraded status and report the paused state instead.
+	if utils.IsPaused(instance) {
+		reqLogger.Info("IntrusionDetection is paused, changes are not being applied", "annotation", utils.PauseAnnotation)
+		statusManager.ClearDegraded()
+		if instance.Status.State != operatorv1.TigeraStatusPaused {
+			if err := r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+				s.State = operatorv1.TigeraStatusPaused
+			}); err != nil {
+				return reconcile.Result{}, err
+			}
+		}
+		return reconcile.Result{}, nil
+	}
+
+	// Don't apply any changes while an operator-wide maintenance window is active. Like a pause, this is not an error.
+	maintenanceActive, err := utils.IsMaintenanceActive(ctx, r.client)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Error reading the maintenance ConfigMap", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if err := r.setMaintenance(ctx, instance, maintenanceActive); err != nil {
+		return reconcile.Result{}, err
+	}
+	if maintenanceActive {
+		reqLogger.Info("Operator maintenance is active, changes are not being applied", "configMap", utils.MaintenanceConfigMapName)
+		statusManager.ClearDegraded()
+		return reconcile.Result{}, nil
+	}
+
+	if err := validateIntrusionDetectionResource(instance); err != nil {
+		statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	// An additional IntrusionDetection relies on the cluster wide objects and the key pairs that the default one
+	// renders.
+	if !defaultInstance {
+		if err := r.client.Get(ctx, utils.DefaultTSEEInstanceKey, &operatorv1.IntrusionDetection{}); err != nil {
+			if errors.IsNotFound(err) {
+				r.waitingOn(ctx, instance, operatorv1.ResourceNotFound, fmt.Sprintf("Waiting for the default IntrusionDetection %s to be created", utils.DefaultTSEEInstanceKey.Name), nil, reqLogger)
+				return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
+			}
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "Error querying the default IntrusionDetection", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Reading a kind whose CRD isn't installed fails with a schema error that doesn't say what is missing, so check
+	// for the CRDs upfront.
+	if kind, err := r.missingCRD(ctx); err != nil {
+		if kind != "" {
+			statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("%s CRD not found; install the Calico Enterprise CRDs", kind), err, reqLogger)
 			return reconcile.Result{}, err
 		}
-		instance.Status.Conditions = status.UpdateStatusCondition(instance.Status.Conditions, ts.Status.Conditions)
-		if err := r.client.Status().Update(ctx, instance); err != nil {
-			log.WithValues("reason", err).Info("Failed to create IntrusionDetection status conditions.")
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to check for the Calico Enterprise CRDs", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	// Query for the installation object.
+	variant, network, err := utils.GetInstallation(context.Background(), r.client)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			statusManager.SetDegraded(operatorv1.ResourceNotFound, "Installation not found", err, reqLogger)
 			return reconcile.Result{}, err
 		}
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Error querying installation", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	// Intrusion detection only works on Calico Enterprise, so stop here rather than fail part way through on Calico.
+	// The variant is not set until the Installation has been reconciled for the first time.
+	if variant == "" {
+		r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for the Installation to report its variant", nil, reqLogger)
+		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
+	}
+	if variant != operatorv1.TigeraSecureEnterprise {
+		r.waitingOn(ctx, instance, operatorv1.InvalidConfigurationError, "IntrusionDetection requires Calico Enterprise", nil, reqLogger)
+		return reconcile.Result{}, nil
+	}
+
+	if sa := instance.Spec.ServiceAccounts; sa != nil {
+		for _, ref := range []types.NamespacedName{
+			{Name: sa.Controller, Namespace: helper.InstallNamespace()},
+			{Name: sa.Installer, Namespace: helper.InstallNamespace()},
+			{Name: sa.DeepPacketInspection, Namespace: dpi.DeepPacketInspectionNamespace},
+		} {
+			if ref.Name == "" {
+				continue
+			}
+			if err := r.client.Get(ctx, ref, &corev1.ServiceAccount{}); err != nil {
+				if errors.IsNotFound(err) {
+					statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The referenced ServiceAccount %s was not found", ref), err, reqLogger)
+					return reconcile.Result{}, err
+				}
+				statusManager.SetDegraded(operatorv1.ResourceReadError, fmt.Sprintf("Failed to read the referenced ServiceAccount %s", ref), err, reqLogger)
+				return reconcile.Result{}, err
+			}
+		}
 	}
 
 	managementClusterConnection, err := utils.GetManagementClusterConnection(ctx, r.client)
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Failed to read ManagementClusterConnection", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read ManagementClusterConnection", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
@@ -294,82 +886,104 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 
 	managementCluster, err := utils.GetManagementCluster(ctx, r.client)
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Error reading ManagementCluster", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Error reading ManagementCluster", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
 	isManagementCluster := managementCluster != nil
 
 	if err := r.fillDefaults(ctx, instance); err != nil {
-		r.status.SetDegraded(operatorv1.ResourceUpdateError, "Unable to set defaults on IntrusionDetection", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Unable to set defaults on IntrusionDetection", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if err := r.handleUnrecognizedComponentResources(ctx, instance); err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Unable to handle the unrecognized ComponentResources of the IntrusionDetection", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	// Defaults that scale with the nodes are recomputed on every reconcile, so that they follow the nodes.
+	dpiInstance, err := r.withScaledDPIResourceDefaults(ctx, instance)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the nodes to scale the DeepPacketInspection resources", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	// The resources of DeepPacketInspection are only known once the defaults are filled in.
+	if err := validateDPICPUPinning(dpiInstance); err != nil {
+		statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
 	if !utils.IsAPIServerReady(r.client, reqLogger) {
-		r.status.SetDegraded(operatorv1.ResourceNotReady, "Waiting for Tigera API server to be ready", nil, reqLogger)
+		r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for Tigera API server to be ready", nil, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	// The Elasticsearch mode is read on every reconcile, so that the components follow a change from an operator managed
+	// to an external Elasticsearch, or back.
+	elasticExternal, err := utils.ReadUseExternalElastic(ctx, r.client)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the operator bootstrap configuration", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	deps, err := r.requiredDependencies(ctx, instance, helper.InstallNamespace(), isManagedCluster, elasticExternal)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to look up the Secrets and ConfigMaps intrusion detection needs", err, reqLogger)
 		return reconcile.Result{}, err
 	}
+	if err := r.setDependencies(ctx, instance, deps); err != nil {
+		reqLogger.Error(err, "Failed to update the IntrusionDetection dependencies")
+	}
 
-	if !isManagedCluster && !r.elasticExternal {
+	var elasticsearch *esv1.Elasticsearch
+	if !isManagedCluster && !elasticExternal {
 		// check es-gateway to be available
-		elasticsearch, err := utils.GetElasticsearch(ctx, r.client)
+		elasticsearch, err = utils.GetElasticsearch(ctx, r.client)
 		if err != nil {
-			r.status.SetDegraded(operatorv1.ResourceReadError, "An error occurred trying to retrieve Elasticsearch", err, reqLogger)
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "An error occurred trying to retrieve Elasticsearch", err, reqLogger)
 			return reconcile.Result{}, err
 		}
 		if elasticsearch == nil || elasticsearch.Status.Phase != esv1.ElasticsearchReadyPhase {
-			r.status.SetDegraded(operatorv1.ResourceNotReady, "Waiting for Elasticsearch cluster to be operational", nil, reqLogger)
+			r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for Elasticsearch cluster to be operational", nil, reqLogger)
 			return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 		}
 	}
 
 	// Validate that the tier watch is ready before querying the tier to ensure we utilize the cache.
 	if !r.tierWatchReady.IsReady() {
-		r.status.SetDegraded(operatorv1.ResourceNotReady, "Waiting for Tier watch to be established", nil, reqLogger)
+		r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for Tier watch to be established", nil, reqLogger)
 		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 	}
 
 	// Ensure the allow-tigera tier exists, before rendering any network policies within it.
 	if err := r.client.Get(ctx, client.ObjectKey{Name: networkpolicy.TigeraComponentTierName}, &v3.Tier{}); err != nil {
 		if errors.IsNotFound(err) {
-			r.status.SetDegraded(operatorv1.ResourceNotReady, "Waiting for allow-tigera tier to be created", err, reqLogger)
+			r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for allow-tigera tier to be created", err, reqLogger)
 			return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 		} else {
-			r.status.SetDegraded(operatorv1.ResourceNotReady, "Error querying allow-tigera tier", err, reqLogger)
+			statusManager.SetDegraded(operatorv1.ResourceNotReady, "Error querying allow-tigera tier", err, reqLogger)
 			return reconcile.Result{}, err
 		}
 	}
 
 	if !r.licenseAPIReady.IsReady() {
-		r.status.SetDegraded(operatorv1.ResourceNotReady, "Waiting for LicenseKeyAPI to be ready", nil, reqLogger)
+		r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for LicenseKeyAPI to be ready", nil, reqLogger)
 		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 	}
 
 	license, err := utils.FetchLicenseKey(ctx, r.client)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			r.status.SetDegraded(operatorv1.ResourceNotFound, "License not found", err, reqLogger)
+			r.waitingOn(ctx, instance, operatorv1.ResourceNotFound, "License not found", err, reqLogger)
 			return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 		}
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Error querying license", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Error querying license", err, reqLogger)
 		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 	}
 
-	// Query for the installation object.
-	variant, network, err := utils.GetInstallation(context.Background(), r.client)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			r.status.SetDegraded(operatorv1.ResourceNotFound, "Installation not found", err, reqLogger)
-			return reconcile.Result{}, err
-		}
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Error querying installation", err, reqLogger)
-		return reconcile.Result{}, err
-	}
-
 	// Query for pull secrets in operator namespace
 	pullSecrets, err := utils.GetNetworkingPullSecrets(network, r.client)
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Error retrieving pull secrets", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Error retrieving pull secrets", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
@@ -386,13 +1000,24 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 	esClusterConfig, err := utils.GetElasticsearchClusterConfig(context.Background(), r.client)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			r.status.SetDegraded(operatorv1.ResourceNotFound, "Elasticsearch cluster configuration is not available, waiting for it to become available", err, reqLogger)
+			r.waitingOn(ctx, instance, operatorv1.ResourceNotFound, "Elasticsearch cluster configuration is not available, waiting for it to become available", err, reqLogger)
 			return reconcile.Result{}, nil
 		}
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Failed to get the elasticsearch cluster configuration", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to get the elasticsearch cluster configuration", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
+	if elasticsearch != nil {
+		if err := validateAwarenessAttributes(esClusterConfig.AwarenessAttributes(), elasticsearch); err != nil {
+			statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "Elasticsearch shard allocation awareness is misconfigured", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		if err := validateElasticsearchVersion(instance.Spec.ElasticsearchVersion, elasticsearch); err != nil {
+			statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "The running Elasticsearch does not match the pinned version", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
 	secrets := []string{
 		render.ElasticsearchIntrusionDetectionUserSecret,
 		render.ElasticsearchPerformanceHotspotsUserSecret,
@@ -409,27 +1034,43 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 	)
 	if err != nil {
 		if errors.IsNotFound(err) {
-			r.status.SetDegraded(operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available", err, reqLogger)
+			// Give secrets that are synced from elsewhere a chance to be created before degrading.
+			if grace := missingSecretGracePeriod(instance); grace > 0 {
+				if missing := r.missingSecrets.missing(instance.Name); missing < grace {
+					reqLogger.Info("Elasticsearch secrets are not available yet, waiting for them to be created", "error", err.Error(), "gracePeriod", grace)
+					if err := r.setDependenciesReady(ctx, instance, false, string(operatorv1.ResourceNotFound), fmt.Sprintf("Waiting up to %s for the Elasticsearch secrets to be created", grace)); err != nil {
+						reqLogger.Error(err, "Failed to update the IntrusionDetection conditions")
+					}
+					return reconcile.Result{RequeueAfter: grace - missing}, nil
+				}
+			}
+			r.waitingOn(ctx, instance, operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available", err, reqLogger)
 			return reconcile.Result{}, nil
 		}
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Failed to get Elasticsearch credentials", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to get Elasticsearch credentials", err, reqLogger)
 		return reconcile.Result{}, err
 	}
+	r.missingSecrets.found(instance.Name)
 
 	certificateManager, err := certificatemanager.Create(r.client, network, r.clusterDomain, common.OperatorNamespace())
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceCreateError, "Unable to create the Tigera CA", err, reqLogger)
+		r.setCertificateDegraded(statusManager, operatorv1.ResourceCreateError, "Unable to create the Tigera CA", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
-	esgwCertificate, err := certificateManager.GetCertificate(r.client, relasticsearch.PublicCertSecret, common.OperatorNamespace())
-	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, fmt.Sprintf("Failed to retrieve / validate  %s", relasticsearch.PublicCertSecret), err, reqLogger)
-		return reconcile.Result{}, err
-	} else if esgwCertificate == nil {
-		log.Info("Elasticsearch gateway certificate is not available yet, waiting until they become available")
-		r.status.SetDegraded(operatorv1.ResourceNotReady, "Elasticsearch gateway certificate are not available yet, waiting until they become available", nil, reqLogger)
-		return reconcile.Result{}, nil
+	// Managed clusters have no Elasticsearch gateway of their own, since their components reach Linseed through the
+	// management cluster, so they do not depend on its certificate.
+	var esgwCertificate certificatemanagement.CertificateInterface
+	if !isManagedCluster {
+		esgwCertificate, err = certificateManager.GetCertificate(r.client, relasticsearch.PublicCertSecret, common.OperatorNamespace())
+		if err != nil {
+			r.setCertificateDegraded(statusManager, operatorv1.ResourceReadError, fmt.Sprintf("Failed to retrieve / validate  %s", relasticsearch.PublicCertSecret), err, reqLogger)
+			return reconcile.Result{}, err
+		} else if esgwCertificate == nil {
+			reqLogger.Info("Elasticsearch gateway certificate is not available yet, waiting until they become available")
+			r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Elasticsearch gateway certificate are not available yet, waiting until they become available", nil, reqLogger)
+			return reconcile.Result{}, nil
+		}
 	}
 
 	// The location of the Linseed certificate varies based on if this is a managed cluster or not.
@@ -439,78 +1080,255 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 	}
 	linseedCertificate, err := certificateManager.GetCertificate(r.client, linseedCertLocation, common.OperatorNamespace())
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, fmt.Sprintf("Failed to retrieve / validate  %s", render.TigeraLinseedSecret), err, reqLogger)
+		r.setCertificateDegraded(statusManager, operatorv1.ResourceReadError, fmt.Sprintf("Failed to retrieve / validate  %s", render.TigeraLinseedSecret), err, reqLogger)
 		return reconcile.Result{}, err
 	} else if linseedCertificate == nil {
-		log.Info("Linseed certificate is not available yet, waiting until they become available")
-		r.status.SetDegraded(operatorv1.ResourceNotReady, "Linseed certificate are not available yet, waiting until they become available", nil, reqLogger)
+		reqLogger.Info("Linseed certificate is not available yet, waiting until they become available")
+		r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Linseed certificate are not available yet, waiting until they become available", nil, reqLogger)
 		return reconcile.Result{}, nil
 	}
 
 	// intrusionDetectionKeyPair is the key pair intrusion detection presents to identify itself
-	intrusionDetectionKeyPair, err := certificateManager.GetOrCreateKeyPair(r.client, render.IntrusionDetectionTLSSecretName, common.OperatorNamespace(), []string{render.IntrusionDetectionTLSSecretName})
+	intrusionDetectionKeyPair, err := getOrCreateKeyPair(certificateManager, r.client, instance, render.IntrusionDetectionTLSSecretName, []string{render.IntrusionDetectionTLSSecretName})
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceCreateError, "Error creating TLS certificate", err, reqLogger)
+		r.setCertificateDegraded(statusManager, operatorv1.ResourceCreateError, "Error creating TLS certificate", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
+	// metricsKeyPair is the key pair the intrusion detection controller serves its metrics endpoint with.
+	var metricsKeyPair certificatemanagement.KeyPairInterface
+	if instance.Spec.ControllerMetricsTLS != nil && *instance.Spec.ControllerMetricsTLS {
+		metricsKeyPair, err = getOrCreateKeyPair(certificateManager, r.client, instance, render.IntrusionDetectionMetricsTLSSecretName,
+			dns.GetServiceDNSNames(render.IntrusionDetectionName, helper.InstallNamespace(), r.clusterDomain))
+		if err != nil {
+			r.setCertificateDegraded(statusManager, operatorv1.ResourceCreateError, "Error creating the metrics TLS certificate", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
 	if !r.dpiAPIReady.IsReady() {
-		log.Info("Waiting for DeepPacketInspection API to be ready")
-		r.status.SetDegraded(operatorv1.ResourceNotReady, "Waiting for DeepPacketInspection API to be ready", nil, reqLogger)
+		reqLogger.Info("Waiting for DeepPacketInspection API to be ready")
+		r.waitingOn(ctx, instance, operatorv1.ResourceNotReady, "Waiting for DeepPacketInspection API to be ready", nil, reqLogger)
 		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 	}
+	if err := r.setDependenciesReady(ctx, instance, true, AllDependenciesReadyReason, "All intrusion detection dependencies are in place"); err != nil {
+		reqLogger.Error(err, "Failed to update the IntrusionDetection conditions")
+	}
 
 	// Intrusion detection controller sometimes needs to make requests to outside sources. Therefore, we include
 	// the system root certificate bundle.
 	trustedBundle, err := certificateManager.CreateTrustedBundleWithSystemRootCertificates(esgwCertificate, linseedCertificate)
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceCreateError, "Unable to create tigera-ca-bundle configmap", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceCreateError, "Unable to create tigera-ca-bundle configmap", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
 	var esLicenseType render.ElasticsearchLicenseType
 	if !isManagedCluster {
 		if esLicenseType, err = utils.GetElasticLicenseType(ctx, r.client, reqLogger); err != nil {
-			r.status.SetDegraded(operatorv1.ResourceReadError, "Failed to get Elasticsearch license", err, reqLogger)
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to get Elasticsearch license", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		if err := checkECKLicense(instance, esLicenseType); err != nil {
+			statusManager.SetDegraded(operatorv1.ResourceValidationError, "The Elasticsearch license doesn't support the configured intrusion detection features", err, reqLogger)
 			return reconcile.Result{}, err
 		}
 
 		managerInternalTLSSecret, err := certificateManager.GetCertificate(r.client, render.ManagerInternalTLSSecretName, common.OperatorNamespace())
 		if err != nil {
-			r.status.SetDegraded(operatorv1.ResourceValidationError, fmt.Sprintf("failed to retrieve / validate  %s", render.ManagerInternalTLSSecretName), err, reqLogger)
+			r.setCertificateDegraded(statusManager, operatorv1.ResourceValidationError, fmt.Sprintf("failed to retrieve / validate  %s", render.ManagerInternalTLSSecretName), err, reqLogger)
 			return reconcile.Result{}, err
 		}
 
 		trustedBundle.AddCertificates(managerInternalTLSSecret)
 	}
 
-	// Create a component handler to manage the rendered component.
-	handler := utils.NewComponentHandler(log, r.client, r.scheme, instance)
+	var externalElasticsearchCerts []certificatemanagement.CertificateInterface
+	if elasticExternal && instance.Spec.ExternalElasticsearchCABundle != nil {
+		ref := instance.Spec.ExternalElasticsearchCABundle
+		cm := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: common.OperatorNamespace()}, cm); err != nil {
+			if errors.IsNotFound(err) {
+				statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The external Elasticsearch CA bundle ConfigMap %s/%s was not found", common.OperatorNamespace(), ref.Name), err, reqLogger)
+				return reconcile.Result{}, err
+			}
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the external Elasticsearch CA bundle", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		caCerts, err := certificatemanagement.ParseCertificateBundle(ref.Name, common.OperatorNamespace(), []byte(cm.Data[ref.Key]))
+		if err != nil {
+			statusManager.SetDegraded(operatorv1.ResourceValidationError, fmt.Sprintf("Key %q of the external Elasticsearch CA bundle ConfigMap is not a valid PEM bundle", ref.Key), err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		trustedBundle.AddCertificates(caCerts...)
+		externalElasticsearchCerts = caCerts
+	}
 
-	reqLogger.V(3).Info("rendering components")
-	// Render the desired objects from the CRD and create or update them.
-	hasNoLicense := !utils.IsFeatureActive(license, common.ThreatDefenseFeature)
-	intrusionDetectionCfg := &render.IntrusionDetectionConfiguration{
-		IntrusionDetection:           *instance,
-		LogCollector:                 lc,
-		ESSecrets:                    esSecrets,
-		Installation:                 network,
-		ESClusterConfig:              esClusterConfig,
-		PullSecrets:                  pullSecrets,
-		Openshift:                    r.provider == operatorv1.ProviderOpenShift,
-		ClusterDomain:                r.clusterDomain,
-		ESLicenseType:                esLicenseType,
-		ManagedCluster:               isManagedCluster,
-		ManagementCluster:            isManagementCluster,
-		HasNoLicense:                 hasNoLicense,
-		TrustedCertBundle:            trustedBundle,
-		IntrusionDetectionCertSecret: intrusionDetectionKeyPair,
-		UsePSP:                       r.usePSP,
+	// The additional CA bundles of the installer are copied into the namespace of the components, so they are only
+	// read here for the installer to mount them.
+	var installerCABundles []*corev1.ConfigMap
+	for _, ref := range instance.Spec.InstallerCABundles {
+		cm := &corev1.ConfigMap{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: common.OperatorNamespace()}, cm); err != nil {
+			if errors.IsNotFound(err) {
+				statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The installer CA bundle ConfigMap %s/%s was not found", common.OperatorNamespace(), ref.Name), err, reqLogger)
+				return reconcile.Result{}, err
+			}
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the installer CA bundle", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		if _, err := certificatemanagement.ParseCertificateBundle(ref.Name, common.OperatorNamespace(), []byte(cm.Data[ref.Key])); err != nil {
+			statusManager.SetDegraded(operatorv1.ResourceValidationError, fmt.Sprintf("Key %q of the installer CA bundle ConfigMap %s is not a valid PEM bundle", ref.Key, ref.Name), err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		installerCABundles = append(installerCABundles, cm)
+	}
+
+	// The Authorization header of the findings webhook is copied into the namespace of the components.
+	var findingsWebhookAuthSecret *corev1.Secret
+	if webhook := instance.Spec.FindingsWebhook; webhook != nil && webhook.AuthSecret != nil {
+		ref := webhook.AuthSecret
+		findingsWebhookAuthSecret = &corev1.Secret{}
+		if err := r.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: common.OperatorNamespace()}, findingsWebhookAuthSecret); err != nil {
+			if errors.IsNotFound(err) {
+				statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The findings webhook auth Secret %s/%s was not found", common.OperatorNamespace(), ref.Name), err, reqLogger)
+				return reconcile.Result{}, err
+			}
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the findings webhook auth Secret", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		if len(findingsWebhookAuthSecret.Data[ref.Key]) == 0 {
+			err := fmt.Errorf("secret %s/%s has no key %q", common.OperatorNamespace(), ref.Name, ref.Key)
+			statusManager.SetDegraded(operatorv1.ResourceValidationError, fmt.Sprintf("The findings webhook auth Secret %s has no key %q", ref.Name, ref.Key), err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Elasticsearch is checked at the endpoint that the components reach it at. Managed clusters don't use an
+	// Elasticsearch of their own.
+	validateCredentials := instance.Spec.ValidateElasticsearchCredentials != nil && *instance.Spec.ValidateElasticsearchCredentials && !isManagedCluster
+	checkConnectivity := instance.Spec.CheckElasticsearchConnectivity != nil && *instance.Spec.CheckElasticsearchConnectivity && !isManagedCluster
+	var esEndpoint string
+	var esRoots *x509.CertPool
+	if validateCredentials || checkConnectivity {
+		esEndpoint, esRoots = r.elasticsearchEndpoint(elasticExternal, esgwCertificate, certificateManager, externalElasticsearchCerts, reqLogger)
+	}
+
+	// Test the credentials before they are rolled out, so that a rotation to credentials that Elasticsearch does not
+	// accept leaves the running components untouched.
+	if validateCredentials {
+		if err := r.validateCredentials(ctx, instance, esEndpoint, esRoots, esSecrets); err != nil {
+			statusManager.SetDegraded(operatorv1.ResourceValidationError, "Elasticsearch did not accept the intrusion detection credentials", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// Report whether Elasticsearch can be reached, apart from the degraded state, since an unreachable Elasticsearch
+	// otherwise only shows up through the components failing. The first of the Elasticsearch secrets is the one of the
+	// intrusion-detection-controller.
+	if checkConnectivity {
+		if err := r.setElasticsearchReachable(ctx, instance, esEndpoint, esRoots, esSecrets[0]); err != nil {
+			reqLogger.Error(err, "Failed to update the ElasticsearchReachable condition")
+		}
+	}
+
+	// Detection rule ConfigMaps are copied from the operator namespace when they are there, and otherwise must already
+	// exist in the namespace of the intrusion detection components.
+	var detectionRuleConfigMaps []*corev1.ConfigMap
+	for _, name := range instance.Spec.AdditionalDetectionRuleConfigMaps {
+		cm := &corev1.ConfigMap{}
+		err := r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: common.OperatorNamespace()}, cm)
+		if err == nil {
+			detectionRuleConfigMaps = append(detectionRuleConfigMaps, cm)
+			continue
+		}
+		if errors.IsNotFound(err) {
+			err = r.client.Get(ctx, types.NamespacedName{Name: name, Namespace: helper.InstallNamespace()}, cm)
+		}
+		if err != nil {
+			if errors.IsNotFound(err) {
+				statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The detection rule ConfigMap %s was not found in %s or %s", name, common.OperatorNamespace(), helper.InstallNamespace()), err, reqLogger)
+				return reconcile.Result{}, err
+			}
+			statusManager.SetDegraded(operatorv1.ResourceReadError, fmt.Sprintf("Failed to read the detection rule ConfigMap %s", name), err, reqLogger)
+			return reconcile.Result{}, err
+		}
+	}
+
+	// The copies of ConfigMaps that are no longer referenced are deleted.
+	copiedConfigMaps, err := r.copiedConfigMaps(ctx, helper.InstallNamespace())
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the copied ConfigMaps", err, reqLogger)
+		return reconcile.Result{}, err
 	}
-	comp := render.IntrusionDetection(intrusionDetectionCfg)
 
-	if err = imageset.ApplyImageSet(ctx, r.client, variant, comp); err != nil {
-		r.status.SetDegraded(operatorv1.ResourceUpdateError, "Error with images from ImageSet", err, reqLogger)
+	proxyConfig, err := utils.GetProxyConfig(ctx, r.client)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the proxy configuration", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	// Create a component handler to manage the rendered component.
+	recreateOnImmutableFieldChange := instance.Spec.ImmutableFieldChangePolicy != nil &&
+		*instance.Spec.ImmutableFieldChangePolicy == operatorv1.ImmutableFieldChangePolicyRecreate
+	handler := utils.NewComponentHandler(reqLogger, r.client, r.scheme, instance, utils.WithRecreateOnImmutableFieldChange(recreateOnImmutableFieldChange))
+
+	reqLogger.V(3).Info("rendering components")
+	// Render the desired objects from the CRD and create or update them.
+	hasNoLicense := !utils.IsFeatureActive(license, common.ThreatDefenseFeature)
+	licenseGraceRemaining, err := r.licenseLossGraceRemaining(ctx, instance, hasNoLicense, helper.InstallNamespace())
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Failed to track the license loss grace period", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if licenseGraceRemaining > 0 {
+		// Keep the components running until the grace period is over.
+		hasNoLicense = false
+	}
+	completedInstallerJobHash, err := r.completedInstallerJobHash(ctx, instance, helper.InstallNamespace())
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the installer Job", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	intrusionDetectionCfg := &render.IntrusionDetectionConfiguration{
+		IntrusionDetection:           *instance,
+		LogCollector:                 lc,
+		ESSecrets:                    esSecrets,
+		Installation:                 network,
+		ESClusterConfig:              esClusterConfig,
+		PullSecrets:                  pullSecrets,
+		Openshift:                    r.provider == operatorv1.ProviderOpenShift,
+		ClusterDomain:                r.clusterDomain,
+		ESLicenseType:                esLicenseType,
+		ManagedCluster:               isManagedCluster,
+		ManagementCluster:            isManagementCluster,
+		ElasticExternal:              elasticExternal,
+		CompletedInstallerJobHash:    completedInstallerJobHash,
+		HasNoLicense:                 hasNoLicense,
+		TrustedCertBundle:            trustedBundle,
+		IntrusionDetectionCertSecret: intrusionDetectionKeyPair,
+		MetricsKeyPair:               metricsKeyPair,
+		UsePSP:                       r.usePSP,
+		ProxyConfig:                  proxyConfig,
+		Namespace:                    helper.InstallNamespace(),
+		DetectionRuleConfigMaps:      detectionRuleConfigMaps,
+		CopiedConfigMaps:             copiedConfigMaps,
+		InstallerCABundles:           installerCABundles,
+		FindingsWebhookAuthSecret:    findingsWebhookAuthSecret,
+	}
+	if !defaultInstance {
+		intrusionDetectionCfg.Instance = instance.Name
+	}
+	if err := render.ValidateIntrusionDetectionControllerVolumes(intrusionDetectionCfg); err != nil {
+		statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "The IntrusionDetection controller volumes conflict with the operator managed volumes", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if err := render.ValidateIntrusionDetectionControllerArgs(intrusionDetectionCfg); err != nil {
+		statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "The IntrusionDetection controller args conflict with the operator managed settings", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if err := render.ValidateIntrusionDetectionPodSecurityStandard(intrusionDetectionCfg); err != nil {
+		statusManager.SetDegraded(operatorv1.InvalidConfigurationError, "The intrusion detection pods don't meet the Pod Security Standard of their namespace", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
@@ -519,122 +1337,997 @@ func (r *ReconcileIntrusionDetection) Reconcile(ctx context.Context, request rec
 	// makes tests fail, this needs to be looked at.
 	typhaNodeTLS, err := installation.GetOrCreateTyphaNodeTLSConfig(r.client, certificateManager)
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Error with Typha/Felix secrets", err, reqLogger)
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Error with Typha/Felix secrets", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
 	typhaNodeTLS.TrustedBundle.AddCertificates(linseedCertificate)
 
 	// dpiKeyPair is the key pair dpi presents to identify itself
-	dpiKeyPair, err := certificateManager.GetOrCreateKeyPair(r.client, render.DPITLSSecretName, common.OperatorNamespace(), []string{render.IntrusionDetectionTLSSecretName})
+	dpiKeyPair, err := getOrCreateKeyPair(certificateManager, r.client, instance, render.DPITLSSecretName, []string{render.IntrusionDetectionTLSSecretName})
 	if err != nil {
-		r.status.SetDegraded(operatorv1.ResourceCreateError, "Error creating TLS certificate", err, reqLogger)
+		r.setCertificateDegraded(statusManager, operatorv1.ResourceCreateError, "Error creating TLS certificate", err, reqLogger)
 		return reconcile.Result{}, err
 	}
+	certificatemanager.RecordCertificateExpiry(intrusionDetectionKeyPair, dpiKeyPair)
+	for _, cert := range certificatemanager.ExpiringCertificates(certificateExpiryWarningPeriod, intrusionDetectionKeyPair, dpiKeyPair, esgwCertificate, linseedCertificate) {
+		reqLogger.Info("Certificate is nearing expiry and should be rotated", "name", cert.GetName(), "namespace", cert.GetNamespace(), "notAfter", cert.NotAfter)
+	}
 
 	dpiList := &v3.DeepPacketInspectionList{}
 	if err := r.client.List(ctx, dpiList); err != nil {
-		r.status.SetDegraded(operatorv1.ResourceReadError, "Failed to retrieve DeepPacketInspection resource", err, reqLogger)
-		return reconcile.Result{}, err
-	}
-	hasNoDPIResource := len(dpiList.Items) == 0
-
-	dpiComponent := dpi.DPI(&dpi.DPIConfig{
-		IntrusionDetection: instance,
-		Installation:       network,
-		TyphaNodeTLS:       typhaNodeTLS,
-		PullSecrets:        pullSecrets,
-		Openshift:          r.provider == operatorv1.ProviderOpenShift,
-		ManagedCluster:     isManagedCluster,
-		ManagementCluster:  isManagementCluster,
-		HasNoLicense:       hasNoLicense,
-		HasNoDPIResource:   hasNoDPIResource,
-		ESClusterConfig:    esClusterConfig,
-		ClusterDomain:      r.clusterDomain,
-		DPICertSecret:      dpiKeyPair,
-	})
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to retrieve DeepPacketInspection resource", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	dpiResources, err := r.selectDPIResources(ctx, instance, dpiList.Items)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the namespaces of the DeepPacketInspection resources", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	hasNoDPIResource := len(dpiResources) == 0
+
+	componentsCfg := &rintrusiondetection.Config{IntrusionDetection: intrusionDetectionCfg}
+	// DeepPacketInspection runs cluster wide, so only the default IntrusionDetection manages it.
+	if defaultInstance {
+		var typhaCABundle *corev1.ConfigMap
+		if ref := instance.Spec.DPITyphaCABundle; ref != nil {
+			typhaCABundle = &corev1.ConfigMap{}
+			if err := r.client.Get(ctx, types.NamespacedName{Name: ref.Name, Namespace: common.OperatorNamespace()}, typhaCABundle); err != nil {
+				if errors.IsNotFound(err) {
+					statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The DeepPacketInspection Typha CA bundle ConfigMap %s/%s was not found", common.OperatorNamespace(), ref.Name), err, reqLogger)
+					return reconcile.Result{}, err
+				}
+				statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the DeepPacketInspection Typha CA bundle", err, reqLogger)
+				return reconcile.Result{}, err
+			}
+			if _, err := certificatemanagement.ParseCertificateBundle(ref.Name, common.OperatorNamespace(), []byte(typhaCABundle.Data[ref.Key])); err != nil {
+				statusManager.SetDegraded(operatorv1.ResourceValidationError, fmt.Sprintf("Key %q of the DeepPacketInspection Typha CA bundle ConfigMap is not a valid PEM bundle", ref.Key), err, reqLogger)
+				return reconcile.Result{}, err
+			}
+		}
+		if name := instance.Spec.DPIPriorityClassName; name != nil {
+			if err := r.client.Get(ctx, types.NamespacedName{Name: *name}, &schedulingv1.PriorityClass{}); err != nil {
+				if errors.IsNotFound(err) {
+					statusManager.SetDegraded(operatorv1.ResourceNotFound, fmt.Sprintf("The DeepPacketInspection PriorityClass %s was not found", *name), err, reqLogger)
+					return reconcile.Result{}, err
+				}
+				statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the DeepPacketInspection PriorityClass", err, reqLogger)
+				return reconcile.Result{}, err
+			}
+		}
+		staleProfiles, err := r.staleDPIProfiles(ctx, instance)
+		if err != nil {
+			statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the DeepPacketInspection DaemonSets", err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		componentsCfg.DPI = &dpi.DPIConfig{
+			IntrusionDetection: dpiInstance,
+			Installation:       network,
+			TyphaNodeTLS:       typhaNodeTLS,
+			PullSecrets:        pullSecrets,
+			Openshift:          r.provider == operatorv1.ProviderOpenShift,
+			ManagedCluster:     isManagedCluster,
+			ManagementCluster:  isManagementCluster,
+			HasNoLicense:       hasNoLicense,
+			HasNoDPIResource:   hasNoDPIResource,
+			ESClusterConfig:    esClusterConfig,
+			ClusterDomain:      r.clusterDomain,
+			DPICertSecret:      dpiKeyPair,
+			TyphaCABundle:      typhaCABundle,
+			StaleProfiles:      staleProfiles,
+		}
+	}
+	components := rintrusiondetection.Components(componentsCfg)
 
-	components := []render.Component{
-		comp,
-		dpiComponent,
-		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
-			Namespace:       render.IntrusionDetectionNamespace,
-			ServiceAccounts: []string{render.IntrusionDetectionName},
-			KeyPairOptions: []rcertificatemanagement.KeyPairOption{
-				rcertificatemanagement.NewKeyPairOption(intrusionDetectionCfg.IntrusionDetectionCertSecret, true, true),
-			},
-			TrustedBundle: trustedBundle,
-		}),
-		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
-			Namespace:       dpi.DeepPacketInspectionNamespace,
-			ServiceAccounts: []string{dpi.DeepPacketInspectionName},
-			KeyPairOptions: []rcertificatemanagement.KeyPairOption{
-				rcertificatemanagement.NewKeyPairOption(typhaNodeTLS.NodeSecret, false, true),
-				rcertificatemanagement.NewKeyPairOption(dpiKeyPair, true, true),
-			},
-			TrustedBundle: typhaNodeTLS.TrustedBundle,
-		}),
-	}
-
-	if err = imageset.ApplyImageSet(ctx, r.client, variant, dpiComponent); err != nil {
-		r.status.SetDegraded(operatorv1.ResourceUpdateError, "Error with images from ImageSet", err, reqLogger)
+	if err = imageset.ApplyImageSet(ctx, r.client, variant, components...); err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Error with images from ImageSet", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+
+	rendered := newRenderedObjects(helper.InstallNamespace(), r.scheme)
+	var toCreate []client.Object
+	for i := range components {
+		components[i] = rendered.track(components[i])
+		objs, _ := components[i].Objects()
+		toCreate = append(toCreate, objs...)
+	}
+
+	// A quota that the workloads don't fit in would otherwise only surface as pods that fail to be created.
+	if err := r.checkResourceQuotas(ctx, helper.InstallNamespace(), toCreate); err != nil {
+		var quotaErr *quotaExceededError
+		if stderrors.As(err, &quotaErr) {
+			msg := fmt.Sprintf("The intrusion detection workloads exceed the ResourceQuota %s/%s", quotaErr.quota.Namespace, quotaErr.quota.Name)
+			if len(quotaErr.exceeded) == 0 {
+				msg = fmt.Sprintf("The intrusion detection workloads don't set the resources that the ResourceQuota %s/%s requires", quotaErr.quota.Namespace, quotaErr.quota.Name)
+			}
+			statusManager.SetDegraded(operatorv1.ResourceValidationError, msg, err, reqLogger)
+			return reconcile.Result{}, err
+		}
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the ResourceQuotas of the intrusion detection namespace", err, reqLogger)
 		return reconcile.Result{}, err
 	}
 
 	for _, comp := range components {
-		if err := handler.CreateOrUpdateOrDelete(context.Background(), comp, r.status); err != nil {
-			r.status.SetDegraded(operatorv1.ResourceUpdateError, "Error creating / updating resource", err, reqLogger)
+		if err := handler.CreateOrUpdateOrDelete(context.Background(), comp, statusManager); err != nil {
+			statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Error creating / updating resource", err, reqLogger)
 			return reconcile.Result{}, err
 		}
 	}
+	summary.rendered = rendered
+	switch {
+	case licenseGraceRemaining > 0:
+		summary.license = summaryLicenseGrace
+	case hasNoLicense:
+		summary.license = summaryLicenseMissing
+	default:
+		summary.license = summaryLicenseOK
+	}
+
+	// Objects that an earlier reconcile created but that are no longer rendered, e.g. because their component was
+	// disabled, would otherwise be left behind.
+	pruned, err := r.pruneOrphans(ctx, instance, rendered, statusManager)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Failed to prune orphaned intrusion detection resources", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if len(pruned) > 0 {
+		reqLogger.Info("Pruned orphaned intrusion detection resources", "resources", pruned)
+	}
+	// Release an installer Job that was deleted above, e.g. to be recreated with a new configuration.
+	if _, err := r.completedInstallerJobHash(ctx, instance, helper.InstallNamespace()); err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceUpdateError, "Failed to release the installer Job", err, reqLogger)
+		return reconcile.Result{}, err
+	}
 
 	if hasNoLicense {
-		log.V(4).Info("IntrusionDetection is not activated as part of this license")
-		r.status.SetDegraded(operatorv1.ResourceValidationError, "Feature is not active - License does not support this feature", nil, reqLogger)
+		reqLogger.V(4).Info("IntrusionDetection is not activated as part of this license")
+		statusManager.SetDegraded(operatorv1.ResourceValidationError, "Feature is not active - License does not support this feature", nil, reqLogger)
 		return reconcile.Result{}, nil
 	}
+	if licenseGraceRemaining > 0 {
+		reqLogger.Info("IntrusionDetection is not activated as part of this license, waiting before removing it", "remaining", licenseGraceRemaining)
+		statusManager.SetDegraded(operatorv1.ResourceValidationError, fmt.Sprintf("Feature is not active - License does not support this feature, the intrusion detection components will be removed in %s", licenseGraceRemaining.Round(time.Second)), nil, reqLogger)
+		return reconcile.Result{RequeueAfter: licenseGraceRemaining}, nil
+	}
+
+	// A pod that can't pull its image would otherwise only show up as a rollout that never completes, so report the
+	// image that is failing. This also covers a rollout that is stuck while the previous pods are still available.
+	podNamespaces := []string{helper.InstallNamespace()}
+	if defaultInstance {
+		podNamespaces = append(podNamespaces, dpi.DeepPacketInspectionNamespace)
+	}
+	msg, err := r.imagePullFailure(ctx, podNamespaces...)
+	if err != nil {
+		statusManager.SetDegraded(operatorv1.ResourceReadError, "Failed to read the intrusion detection pods", err, reqLogger)
+		return reconcile.Result{}, err
+	}
+	if msg != "" {
+		statusManager.SetDegraded(operatorv1.PodFailure, msg, nil, reqLogger)
+		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
+	}
 
 	// Clear the degraded bit if we've reached this far.
-	r.status.ClearDegraded()
+	statusManager.ClearDegraded()
 
-	if !r.status.IsAvailable() {
+	if !statusManager.IsAvailable() {
 		// Schedule a kick to check again in the near future. Hopefully by then
 		// things will be available.
 		return reconcile.Result{RequeueAfter: utils.StandardRetry}, nil
 	}
 
-	// Everything is available - update the CRD status.
-	instance.Status.State = operatorv1.TigeraStatusReady
-	if err = r.client.Status().Update(ctx, instance); err != nil {
-		return reconcile.Result{}, err
+	// Everything is available - update the CRD status, unless it already says so, so that a reconcile that changes
+	// nothing doesn't write it.
+	setReady := func(s *operatorv1.IntrusionDetectionStatus) {
+		s.State = operatorv1.TigeraStatusReady
+		meta.SetStatusCondition(&s.Conditions, versionCondition(instance.Generation))
 	}
-	return reconcile.Result{}, nil
+	ready := instance.Status.DeepCopy()
+	setReady(ready)
+	if !reflect.DeepEqual(*ready, instance.Status) {
+		if err := r.updateStatus(ctx, instance, setReady); err != nil {
+			return reconcile.Result{}, err
+		}
+	}
+	// Watches drive reconciliation, but a sync period schedules a resync as a safety net for when events are missed.
+	return reconcile.Result{RequeueAfter: r.syncPeriod}, nil
 }
 
-// fillDefaults updates the IntrusionDetection resource with defaults if
-// ComponentResources is not populated.
-func (r *ReconcileIntrusionDetection) fillDefaults(ctx context.Context, ids *operatorv1.IntrusionDetection) error {
-	if ids.Spec.ComponentResources == nil {
-		ids.Spec.ComponentResources = []operatorv1.IntrusionDetectionComponentResource{
-			{
-				ComponentName: operatorv1.ComponentNameDeepPacketInspection,
-				ResourceRequirements: &corev1.ResourceRequirements{
-					Limits: corev1.ResourceList{
-						corev1.ResourceMemory: resource.MustParse(dpi.DefaultMemoryLimit),
-						corev1.ResourceCPU:    resource.MustParse(dpi.DefaultCPULimit),
-					},
-					Requests: corev1.ResourceList{
-						corev1.ResourceMemory: resource.MustParse(dpi.DefaultMemoryRequest),
-						corev1.ResourceCPU:    resource.MustParse(dpi.DefaultCPURequest),
-					},
-				},
-			},
-		}
-	}
-
-	if err := r.client.Update(ctx, ids); err != nil {
+// missingSecretGracePeriod returns how long to wait for missing Elasticsearch secrets to be created before degrading.
+func missingSecretGracePeriod(instance *operatorv1.IntrusionDetection) time.Duration {
+	if instance.Spec.MissingSecretGracePeriodSeconds == nil {
+		return 0
+	}
+	return time.Duration(*instance.Spec.MissingSecretGracePeriodSeconds) * time.Second
+}
+
+// selectDPIResources returns the DeepPacketInspection resources in the namespaces that the DPINamespaceSelector of the
+// IntrusionDetection selects, or all of them when it has none.
+func (r *ReconcileIntrusionDetection) selectDPIResources(ctx context.Context, instance *operatorv1.IntrusionDetection, dpis []v3.DeepPacketInspection) ([]v3.DeepPacketInspection, error) {
+	if instance.Spec.DPINamespaceSelector == nil {
+		return dpis, nil
+	}
+	// The selector is checked by validateIntrusionDetectionResource.
+	selector, err := metav1.LabelSelectorAsSelector(instance.Spec.DPINamespaceSelector)
+	if err != nil {
+		return nil, err
+	}
+	namespaces := &corev1.NamespaceList{}
+	if err := r.client.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, err
+	}
+	selected := map[string]bool{}
+	for _, ns := range namespaces.Items {
+		selected[ns.Name] = true
+	}
+
+	var result []v3.DeepPacketInspection
+	for _, d := range dpis {
+		if selected[d.Namespace] {
+			result = append(result, d)
+		}
+	}
+	return result, nil
+}
+
+// validateDPIProfiles checks the DeepPacketInspection profiles of an IntrusionDetection, whose settings are checked as
+// the IntrusionDetection settings they replace.
+func validateDPIProfiles(profiles []operatorv1.DPIProfile) error {
+	seen := map[string]bool{}
+	for _, p := range profiles {
+		if errs := validation.IsDNS1123Label(dpi.ProfileDaemonSetName(p.Name)); len(errs) > 0 {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles name %q can't be used for its DaemonSet: %s", p.Name, strings.Join(errs, ", "))
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles lists %q more than once", p.Name)
+		}
+		seen[p.Name] = true
+		if len(p.NodeSelector) == 0 {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles %q must have a node selector", p.Name)
+		}
+		if _, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: p.NodeSelector}); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles %q has an invalid node selector: %w", p.Name, err)
+		}
+		if threads := p.WorkerThreads; threads != nil && (*threads < 1 || *threads > maxDPIWorkerThreads) {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles %q workerThreads %d must be between 1 and %d", p.Name, *threads, maxDPIWorkerThreads)
+		}
+		if regex := p.InterfaceRegex; regex != nil {
+			if _, err := regexp.Compile(*regex); err != nil {
+				return fmt.Errorf("IntrusionDetection spec.DPIProfiles %q interfaceRegex %q is not a valid regular expression: %w", p.Name, *regex, err)
+			}
+		}
+		if filter := p.BPFFilter; filter != nil && strings.TrimSpace(*filter) == "" {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles %q bpfFilter must not be empty when it is set", p.Name)
+		}
+		if severity := p.LogSeverity; severity != nil && !validDPILogSeverity(*severity) {
+			return fmt.Errorf("IntrusionDetection spec.DPIProfiles %q logSeverity %q must be one of Trace, Debug, Info, Warn, Error or Fatal", p.Name, *severity)
+		}
+	}
+	if err := dpi.ValidateProfileNodeSelectors(profiles); err != nil {
+		return fmt.Errorf("IntrusionDetection spec.DPIProfiles %w", err)
+	}
+	return nil
+}
+
+// validateDPICPUPinning checks that the CPU requests and limits of DeepPacketInspection, and those of the profiles that
+// set resources of their own, are equal whole numbers of CPUs when DeepPacketInspection is pinned to dedicated CPUs.
+func validateDPICPUPinning(instance *operatorv1.IntrusionDetection) error {
+	if pinning := instance.Spec.DPICPUPinning; pinning == nil || !*pinning {
+		return nil
+	}
+	resources := render.IntrusionDetectionComponentResources(instance.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection)
+	if err := validatePinnedCPU(resources); err != nil {
+		return fmt.Errorf("IntrusionDetection spec.DPICPUPinning requires the ComponentResources of DeepPacketInspection %w", err)
+	}
+	for _, p := range instance.Spec.DPIProfiles {
+		if p.ResourceRequirements == nil {
+			continue
+		}
+		if err := validatePinnedCPU(p.ResourceRequirements); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.DPICPUPinning requires the resourceRequirements of spec.DPIProfiles %q %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// validatePinnedCPU returns an error unless the given resource requirements have a CPU request and limit that are
+// the same whole number of CPUs.
+func validatePinnedCPU(resources *corev1.ResourceRequirements) error {
+	if resources == nil {
+		return fmt.Errorf("to be set")
+	}
+	request, hasRequest := resources.Requests[corev1.ResourceCPU]
+	limit, hasLimit := resources.Limits[corev1.ResourceCPU]
+	if !hasRequest || !hasLimit {
+		return fmt.Errorf("to have both a CPU request and a CPU limit")
+	}
+	if request.Cmp(limit) != 0 {
+		return fmt.Errorf("to have equal CPU requests and limits, not %s and %s", request.String(), limit.String())
+	}
+	if limit.Sign() <= 0 || limit.MilliValue()%1000 != 0 {
+		return fmt.Errorf("to have a CPU limit that is a whole number of CPUs, not %s", limit.String())
+	}
+	return nil
+}
+
+// pinnedResourceDefaults returns the given default resource requirements of DeepPacketInspection adjusted for CPU
+// pinning: the CPU limit is rounded down to a whole number of CPUs, at least one, and the requests are raised to the
+// limits.
+func pinnedResourceDefaults(resources corev1.ResourceRequirements) corev1.ResourceRequirements {
+	resources = *resources.DeepCopy()
+	cpus := resources.Limits.Cpu().MilliValue() / 1000
+	if cpus < 1 {
+		cpus = 1
+	}
+	resources.Limits[corev1.ResourceCPU] = *resource.NewQuantity(cpus, resource.DecimalSI)
+	resources.Requests = resources.Limits.DeepCopy()
+	return resources
+}
+
+// elasticsearchTimeUnits are the Elasticsearch time units that index lifecycle ages can be given in.
+var elasticsearchTimeUnits = map[string]time.Duration{
+	"d":  24 * time.Hour,
+	"h":  time.Hour,
+	"m":  time.Minute,
+	"s":  time.Second,
+	"ms": time.Millisecond,
+}
+
+var (
+	elasticsearchTimeRegexp = regexp.MustCompile(`^([0-9]+)(d|h|m|s|ms)$`)
+	elasticsearchSizeRegexp = regexp.MustCompile(`^[0-9]+(b|kb|mb|gb|tb|pb)$`)
+)
+
+// parseElasticsearchTime parses a duration in Elasticsearch time units, e.g. 7d.
+func parseElasticsearchTime(s string) (time.Duration, error) {
+	m := elasticsearchTimeRegexp.FindStringSubmatch(s)
+	if m == nil {
+		return 0, fmt.Errorf("%q is not a duration in Elasticsearch time units, e.g. 7d", s)
+	}
+	n, err := strconv.ParseInt(m[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a duration in Elasticsearch time units, e.g. 7d: %w", s, err)
+	}
+	return time.Duration(n) * elasticsearchTimeUnits[m[2]], nil
+}
+
+// validateIndexLifecycle checks the index lifecycle phases of an IntrusionDetection, whose ages must increase from the
+// rollover of the hot phase to the warm, cold and delete phases.
+func validateIndexLifecycle(ilm *operatorv1.IntrusionDetectionIndexLifecycle) error {
+	if ilm == nil {
+		return nil
+	}
+	if size := ilm.RolloverMaxSize; size != "" && !elasticsearchSizeRegexp.MatchString(size) {
+		return fmt.Errorf("IntrusionDetection spec.IndexLifecycle.RolloverMaxSize %q is not a size in Elasticsearch byte units, e.g. 50gb", size)
+	}
+
+	ages := []struct {
+		field, age string
+	}{{"RolloverMaxAge", ilm.RolloverMaxAge}}
+	for _, phase := range []struct {
+		name  string
+		phase *operatorv1.IndexLifecyclePhase
+	}{{"Warm", ilm.Warm}, {"Cold", ilm.Cold}} {
+		if phase.phase == nil {
+			continue
+		}
+		if phase.phase.MinAge == "" {
+			return fmt.Errorf("IntrusionDetection spec.IndexLifecycle.%s.MinAge must be set", phase.name)
+		}
+		if replicas := phase.phase.Replicas; replicas != nil && *replicas < 0 {
+			return fmt.Errorf("IntrusionDetection spec.IndexLifecycle.%s.Replicas must not be negative", phase.name)
+		}
+		if segments := phase.phase.ForceMergeMaxSegments; segments != nil && *segments < 1 {
+			return fmt.Errorf("IntrusionDetection spec.IndexLifecycle.%s.ForceMergeMaxSegments must be at least 1", phase.name)
+		}
+		ages = append(ages, struct{ field, age string }{phase.name + ".MinAge", phase.phase.MinAge})
+	}
+	ages = append(ages, struct{ field, age string }{"DeleteMinAge", ilm.DeleteMinAge})
+
+	var prevField string
+	var prev time.Duration
+	for _, a := range ages {
+		if a.age == "" {
+			continue
+		}
+		d, err := parseElasticsearchTime(a.age)
+		if err != nil {
+			return fmt.Errorf("IntrusionDetection spec.IndexLifecycle.%s is invalid: %w", a.field, err)
+		}
+		if prevField != "" && d <= prev {
+			return fmt.Errorf("IntrusionDetection spec.IndexLifecycle.%s %s must be later than %s", a.field, a.age, prevField)
+		}
+		prevField, prev = a.field, d
+	}
+	return nil
+}
+
+// validDPILogSeverity returns whether DeepPacketInspection supports the given log severity.
+func validDPILogSeverity(severity operatorv1.LogLevel) bool {
+	switch severity {
+	case operatorv1.LogLevelTrace, operatorv1.LogLevelDebug, operatorv1.LogLevelInfo, operatorv1.LogLevelWarn, operatorv1.LogLevelError, operatorv1.LogLevelFatal:
+		return true
+	}
+	return false
+}
+
+// staleDPIProfiles returns the names of the DeepPacketInspection profiles that have a DaemonSet but are no longer in
+// the IntrusionDetection.
+func (r *ReconcileIntrusionDetection) staleDPIProfiles(ctx context.Context, instance *operatorv1.IntrusionDetection) ([]string, error) {
+	daemonSets := &appsv1.DaemonSetList{}
+	if err := r.client.List(ctx, daemonSets, client.InNamespace(dpi.DeepPacketInspectionNamespace), client.HasLabels{dpi.ProfileLabel}); err != nil {
+		return nil, err
+	}
+	current := map[string]bool{}
+	for _, profile := range instance.Spec.DPIProfiles {
+		current[profile.Name] = true
+	}
+	var stale []string
+	for _, ds := range daemonSets.Items {
+		if name := ds.Labels[dpi.ProfileLabel]; !current[name] {
+			stale = append(stale, name)
+		}
+	}
+	return stale, nil
+}
+
+// copiedConfigMaps returns the names of the ConfigMaps in the given namespace that were copied from the operator
+// namespace.
+func (r *ReconcileIntrusionDetection) copiedConfigMaps(ctx context.Context, namespace string) ([]string, error) {
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.client.List(ctx, configMaps, client.InNamespace(namespace), client.HasLabels{render.CopiedConfigMapLabel}); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, cm := range configMaps.Items {
+		names = append(names, cm.Name)
+	}
+	return names, nil
+}
+
+// licenseLossGraceRemaining returns how much longer the components of the IntrusionDetection are kept running while
+// the license does not grant the intrusion detection feature, or zero when they should not be. The grace period only
+// applies to components that are already running, so that a license without the feature never starts them. The time
+// the feature was lost is recorded in the status of the IntrusionDetection, so that a restart of the operator doesn't
+// start the grace period over.
+func (r *ReconcileIntrusionDetection) licenseLossGraceRemaining(ctx context.Context, instance *operatorv1.IntrusionDetection, hasNoLicense bool, namespace string) (time.Duration, error) {
+	if !hasNoLicense {
+		if instance.Status.LicenseFeatureLostTime == nil {
+			return 0, nil
+		}
+		return 0, r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+			s.LicenseFeatureLostTime = nil
+		})
+	}
+	if instance.Spec.LicenseLossGracePeriodSeconds == nil || *instance.Spec.LicenseLossGracePeriodSeconds == 0 {
+		return 0, nil
+	}
+	grace := time.Duration(*instance.Spec.LicenseLossGracePeriodSeconds) * time.Second
+
+	err := r.client.Get(ctx, types.NamespacedName{Name: render.IntrusionDetectionName, Namespace: namespace}, &appsv1.Deployment{})
+	if errors.IsNotFound(err) {
+		return 0, nil
+	} else if err != nil {
+		return 0, err
+	}
+	if instance.Status.LicenseFeatureLostTime == nil {
+		now := metav1.Now()
+		if err := r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+			s.LicenseFeatureLostTime = &now
+		}); err != nil {
+			return 0, err
+		}
+	}
+	if lost := time.Since(instance.Status.LicenseFeatureLostTime.Time); lost < grace {
+		return grace - lost, nil
+	}
+	return 0, nil
+}
+
+// imagePullFailure returns a message naming the image of the first container in the given namespaces that is failing
+// to pull its image, or an empty message when no image pull is failing.
+func (r *ReconcileIntrusionDetection) imagePullFailure(ctx context.Context, namespaces ...string) (string, error) {
+	for _, ns := range namespaces {
+		pods := &corev1.PodList{}
+		if err := r.client.List(ctx, pods, client.InNamespace(ns)); err != nil {
+			return "", err
+		}
+		for _, p := range pods.Items {
+			for _, c := range append(p.Status.InitContainerStatuses, p.Status.ContainerStatuses...) {
+				if c.State.Waiting == nil {
+					continue
+				}
+				if reason := c.State.Waiting.Reason; reason == "ImagePullBackOff" || reason == "ErrImagePull" {
+					return fmt.Sprintf("Pod %s/%s failed to pull image %s for container %s: %s", p.Namespace, p.Name, c.Image, c.Name, c.State.Waiting.Message), nil
+				}
+			}
+		}
+	}
+	return "", nil
+}
+
+// overridableImages are the names of the images that spec.ImageOverrides can replace and spec.ImagePathRemappings
+// can remap.
+var overridableImages = map[string]bool{
+	components.ComponentIntrusionDetectionController.Image:   true,
+	components.ComponentElasticTseeInstaller.Image:           true,
+	components.ComponentSecurityEventWebhooksProcessor.Image: true,
+	components.ComponentDeepPacketInspection.Image:           true,
+}
+
+// sysctlNameRegexp matches the name of a sysctl, with its parts separated by dots or slashes.
+var sysctlNameRegexp = regexp.MustCompile(`^([a-z0-9]([-_a-z0-9]*[a-z0-9])?[./])*[a-z0-9]([-_a-z0-9]*[a-z0-9])?$`)
+
+// maxDPIWorkerThreads is the largest number of worker threads a DeepPacketInspection pod can be configured with.
+const maxDPIWorkerThreads = 64
+
+// maxElasticsearchBulkSize is the largest number of documents the intrusion-detection-controller can be configured to
+// write to Elasticsearch in a single bulk request.
+const maxElasticsearchBulkSize = 10000
+
+func validateIntrusionDetectionResource(instance *operatorv1.IntrusionDetection) error {
+	if errs := validation.IsDNS1123Label(instanceNamespace(instance)); len(errs) > 0 {
+		return fmt.Errorf("IntrusionDetection name %q can't be used for its namespace: %s", instance.Name, strings.Join(errs, ", "))
+	}
+	// The metrics key pair is valid for the service of a single namespace, and the key pairs in the operator namespace
+	// are shared by all IntrusionDetections.
+	if !isDefaultInstance(instance) && instance.Spec.ControllerMetricsTLS != nil && *instance.Spec.ControllerMetricsTLS {
+		return fmt.Errorf("IntrusionDetection spec.controllerMetricsTLS is only supported on the default IntrusionDetection")
+	}
+	if size := instance.Spec.DPIPacketBufferSize; size != "" {
+		q, err := resource.ParseQuantity(size)
+		if err != nil {
+			return fmt.Errorf("IntrusionDetection spec.DPIPacketBufferSize %q is not a valid quantity: %w", size, err)
+		}
+		// Reject fractional byte counts, e.g. from a milli suffix, along with non-positive sizes.
+		if q.Sign() <= 0 || q.CmpInt64(q.Value()) != 0 {
+			return fmt.Errorf("IntrusionDetection spec.DPIPacketBufferSize %q must be a positive number of bytes", size)
+		}
+	}
+	if regex := instance.Spec.DPIInterfaceRegex; regex != nil {
+		if _, err := regexp.Compile(*regex); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.DPIInterfaceRegex %q is not a valid regular expression: %w", *regex, err)
+		}
+	}
+	if filter := instance.Spec.DPIBPFFilter; filter != nil && strings.TrimSpace(*filter) == "" {
+		return fmt.Errorf("IntrusionDetection spec.DPIBPFFilter must not be empty when it is set")
+	}
+	if selector := instance.Spec.DPINamespaceSelector; selector != nil {
+		if _, err := metav1.LabelSelectorAsSelector(selector); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.DPINamespaceSelector is not a valid label selector: %w", err)
+		}
+	}
+	if threads := instance.Spec.DPIWorkerThreads; threads != nil && (*threads < 1 || *threads > maxDPIWorkerThreads) {
+		return fmt.Errorf("IntrusionDetection spec.DPIWorkerThreads %d must be between 1 and %d", *threads, maxDPIWorkerThreads)
+	}
+	if err := validateDPIProfiles(instance.Spec.DPIProfiles); err != nil {
+		return err
+	}
+	if err := validateIndexLifecycle(instance.Spec.IndexLifecycle); err != nil {
+		return err
+	}
+	if preStop := instance.Spec.ControllerPreStop; preStop != nil {
+		if preStop.TCPSocket != nil {
+			return fmt.Errorf("IntrusionDetection spec.ControllerPreStop can't use tcpSocket, which Kubernetes doesn't support for lifecycle hooks")
+		}
+		if (preStop.Exec == nil) == (preStop.HTTPGet == nil) {
+			return fmt.Errorf("IntrusionDetection spec.ControllerPreStop must set exactly one of exec or httpGet")
+		}
+	}
+	if timeout := instance.Spec.ElasticsearchQueryTimeout; timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return fmt.Errorf("IntrusionDetection spec.ElasticsearchQueryTimeout %q is not a valid duration: %w", timeout, err)
+		}
+		if d <= 0 {
+			return fmt.Errorf("IntrusionDetection spec.ElasticsearchQueryTimeout %q must be positive", timeout)
+		}
+	}
+	if ttl := instance.Spec.InstallerJobTTLSecondsAfterFinished; ttl != nil && *ttl < 1 {
+		return fmt.Errorf("IntrusionDetection spec.InstallerJobTTLSecondsAfterFinished %d must be at least 1", *ttl)
+	}
+	if size := instance.Spec.ElasticsearchBulkSize; size != nil && (*size < 1 || *size > maxElasticsearchBulkSize) {
+		return fmt.Errorf("IntrusionDetection spec.ElasticsearchBulkSize %d must be between 1 and %d", *size, maxElasticsearchBulkSize)
+	}
+	switch policy := instance.Spec.ImagePullPolicy; policy {
+	case "", corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+	default:
+		return fmt.Errorf("IntrusionDetection spec.ImagePullPolicy %q must be one of %s, %s or %s", policy, corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever)
+	}
+	if severity := instance.Spec.DPILogSeverity; severity != nil && !validDPILogSeverity(*severity) {
+		return fmt.Errorf("IntrusionDetection spec.DPILogSeverity %q must be one of Trace, Debug, Info, Warn, Error or Fatal", *severity)
+	}
+	if p := instance.Spec.DPISeccompProfile; p != nil {
+		switch p.Type {
+		case corev1.SeccompProfileTypeLocalhost:
+			if p.LocalhostProfile == nil || *p.LocalhostProfile == "" {
+				return fmt.Errorf("IntrusionDetection spec.DPISeccompProfile of type %s must set localhostProfile", p.Type)
+			}
+		case corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeUnconfined:
+			if p.LocalhostProfile != nil {
+				return fmt.Errorf("IntrusionDetection spec.DPISeccompProfile of type %s must not set localhostProfile", p.Type)
+			}
+		default:
+			return fmt.Errorf("IntrusionDetection spec.DPISeccompProfile type %q must be one of %s, %s or %s", p.Type,
+				corev1.SeccompProfileTypeRuntimeDefault, corev1.SeccompProfileTypeLocalhost, corev1.SeccompProfileTypeUnconfined)
+		}
+	}
+	if webhook := instance.Spec.FindingsWebhook; webhook != nil {
+		u, err := url.Parse(webhook.URL)
+		if err != nil {
+			return fmt.Errorf("IntrusionDetection spec.FindingsWebhook.URL is invalid: %w", err)
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("IntrusionDetection spec.FindingsWebhook.URL %q must be an http or https URL with a host", webhook.URL)
+		}
+		if ref := webhook.AuthSecret; ref != nil && (ref.Name == "" || ref.Key == "") {
+			return fmt.Errorf("IntrusionDetection spec.FindingsWebhook.AuthSecret must set a name and a key")
+		}
+	}
+	seenSysctls := map[string]bool{}
+	for _, sysctl := range instance.Spec.DPISysctls {
+		if !sysctlNameRegexp.MatchString(sysctl.Name) {
+			return fmt.Errorf("IntrusionDetection spec.DPISysctls %q is not a valid sysctl name", sysctl.Name)
+		}
+		if seenSysctls[sysctl.Name] {
+			return fmt.Errorf("IntrusionDetection spec.DPISysctls sets %q more than once", sysctl.Name)
+		}
+		seenSysctls[sysctl.Name] = true
+		if err := dpi.ValidateSysctl(sysctl.Name); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.DPISysctls %w", err)
+		}
+		if sysctl.Value == "" {
+			return fmt.Errorf("IntrusionDetection spec.DPISysctls value of %q must not be empty", sysctl.Name)
+		}
+	}
+	// Without a cluster DNS server to fall back on, the pods need their name servers to be configured explicitly.
+	if instance.Spec.DNSPolicy == corev1.DNSNone && (instance.Spec.DNSConfig == nil || len(instance.Spec.DNSConfig.Nameservers) == 0) {
+		return fmt.Errorf("IntrusionDetection spec.DNSConfig must list at least one nameserver when spec.DNSPolicy is %s", corev1.DNSNone)
+	}
+	annotatedComponents := map[operatorv1.IntrusionDetectionComponentName]bool{}
+	for _, pa := range instance.Spec.ComponentPodAnnotations {
+		if annotatedComponents[pa.ComponentName] {
+			return fmt.Errorf("IntrusionDetection spec.ComponentPodAnnotations lists the component %s more than once", pa.ComponentName)
+		}
+		annotatedComponents[pa.ComponentName] = true
+		if errs := apivalidation.ValidateAnnotations(pa.Annotations, field.NewPath("spec", "componentPodAnnotations").Key(string(pa.ComponentName))); len(errs) > 0 {
+			return fmt.Errorf("IntrusionDetection spec.ComponentPodAnnotations of %s are invalid: %w", pa.ComponentName, errs.ToAggregate())
+		}
+	}
+	if md := instance.Spec.CopiedSecretMetadata; md != nil {
+		errs := metav1validation.ValidateLabels(md.Labels, field.NewPath("spec", "copiedSecretMetadata", "labels"))
+		errs = append(errs, apivalidation.ValidateAnnotations(md.Annotations, field.NewPath("spec", "copiedSecretMetadata", "annotations"))...)
+		if len(errs) > 0 {
+			return fmt.Errorf("IntrusionDetection spec.CopiedSecretMetadata is invalid: %w", errs.ToAggregate())
+		}
+	}
+	for image, ref := range instance.Spec.ImageOverrides {
+		if !overridableImages[image] {
+			return fmt.Errorf("IntrusionDetection spec.ImageOverrides can't override the image %q of a component that isn't part of intrusion detection", image)
+		}
+		if err := components.ValidateReference(ref); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.ImageOverrides for %q is invalid: %w", image, err)
+		}
+	}
+	for image, name := range instance.Spec.ImagePathRemappings {
+		if !overridableImages[image] {
+			return fmt.Errorf("IntrusionDetection spec.ImagePathRemappings can't remap the image %q of a component that isn't part of intrusion detection", image)
+		}
+		if err := components.ValidateImageName(name); err != nil {
+			return fmt.Errorf("IntrusionDetection spec.ImagePathRemappings for %q is invalid: %w", image, err)
+		}
+	}
+	seen := map[string]bool{}
+	for _, name := range instance.Spec.AdditionalDetectionRuleConfigMaps {
+		if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+			return fmt.Errorf("IntrusionDetection spec.AdditionalDetectionRuleConfigMaps %q is not a valid ConfigMap name: %s", name, strings.Join(errs, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("IntrusionDetection spec.AdditionalDetectionRuleConfigMaps lists %q more than once", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// validateAwarenessAttributes returns an error if any of the given shard allocation awareness attributes is not
+// advertised by the nodes of the Elasticsearch cluster.
+func validateAwarenessAttributes(attrs []string, es *esv1.Elasticsearch) error {
+	for _, attr := range attrs {
+		advertised := false
+		for _, nodeSet := range es.Spec.NodeSets {
+			if nodeSet.Config == nil {
+				continue
+			}
+			if _, ok := nodeSet.Config.Data[fmt.Sprintf("node.attr.%s", attr)]; ok {
+				advertised = true
+				break
+			}
+		}
+		if !advertised {
+			return fmt.Errorf("awareness attribute %q is not advertised by any Elasticsearch node", attr)
+		}
+	}
+	return nil
+}
+
+// validateElasticsearchVersion returns an error if a version is pinned and the Elasticsearch cluster runs a different
+// version. A major.minor pin matches any patch release of that version.
+func validateElasticsearchVersion(pinned string, es *esv1.Elasticsearch) error {
+	if pinned == "" {
+		return nil
+	}
+	running := es.Spec.Version
+	if running != pinned && !strings.HasPrefix(running, pinned+".") {
+		return fmt.Errorf("IntrusionDetection spec.elasticsearchVersion %q does not match the running Elasticsearch version %q", pinned, running)
+	}
+	return nil
+}
+
+// versionCondition returns a condition that summarizes the Calico Enterprise release and the versions of the intrusion
+// detection components that the operator is managing.
+func versionCondition(generation int64) metav1.Condition {
+	versions := []string{
+		fmt.Sprintf("%s:%s", components.ComponentIntrusionDetectionController.Image, components.ComponentIntrusionDetectionController.Version),
+		fmt.Sprintf("%s:%s", components.ComponentSecurityEventWebhooksProcessor.Image, components.ComponentSecurityEventWebhooksProcessor.Version),
+		fmt.Sprintf("%s:%s", components.ComponentElasticTseeInstaller.Image, components.ComponentElasticTseeInstaller.Version),
+		fmt.Sprintf("%s:%s", components.ComponentDeepPacketInspection.Image, components.ComponentDeepPacketInspection.Version),
+	}
+	return metav1.Condition{
+		Type:               VersionConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ReleaseApplied",
+		Message:            fmt.Sprintf("Managing Calico Enterprise %s: %s", components.EnterpriseRelease, strings.Join(versions, ", ")),
+		ObservedGeneration: generation,
+	}
+}
+
+// cleanup deletes the objects that were created for the IntrusionDetection in order, and then removes the finalizer so
+// that the IntrusionDetection itself can be deleted.
+func (r *ReconcileIntrusionDetection) cleanup(ctx context.Context, ids *operatorv1.IntrusionDetection, namespace string, reqLogger logr.Logger) error {
+	cfg := &render.IntrusionDetectionConfiguration{IntrusionDetection: *ids, Namespace: namespace}
+	if !isDefaultInstance(ids) {
+		cfg.Instance = ids.Name
+	}
+	for _, obj := range render.IntrusionDetectionCleanupObjects(cfg) {
+		reqLogger.V(2).Info("Deleting object", "kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
+		if err := r.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+			r.statusFor(ids).SetDegraded(operatorv1.ResourceUpdateError, fmt.Sprintf("Failed to delete %s %s", obj.GetObjectKind().GroupVersionKind().Kind, obj.GetName()), err, reqLogger)
+			return err
+		}
+	}
+	job := &batchv1.Job{}
+	if err := r.client.Get(ctx, types.NamespacedName{Name: render.IntrusionDetectionInstallerJobName, Namespace: namespace}, job); err == nil {
+		err = r.releaseInstallerJob(ctx, job)
+		if err != nil {
+			r.statusFor(ids).SetDegraded(operatorv1.ResourcePatchError, "Failed to release the installer Job", err, reqLogger)
+			return err
+		}
+	} else if !errors.IsNotFound(err) {
+		r.statusFor(ids).SetDegraded(operatorv1.ResourceReadError, "Failed to read the installer Job", err, reqLogger)
 		return err
 	}
 
+	if stringsutil.StringInSlice(IntrusionDetectionFinalizer, ids.GetFinalizers()) {
+		prePatch := client.MergeFrom(ids.DeepCopy())
+		ids.SetFinalizers(stringsutil.RemoveStringInSlice(IntrusionDetectionFinalizer, ids.GetFinalizers()))
+		if err := r.client.Patch(ctx, ids, prePatch); err != nil {
+			r.statusFor(ids).SetDegraded(operatorv1.ResourcePatchError, "Error patching to remove finalizer", err, reqLogger)
+			return err
+		}
+	}
 	return nil
 }
+
+// setCertificateDegraded reports an error of the certificate manager in the given status manager. A missing key pair,
+// invalid certificate data and a denied read each get a reason of their own; other errors are reported with the given
+// reason.
+func (r *ReconcileIntrusionDetection) setCertificateDegraded(statusManager status.StatusManager, reason operatorv1.TigeraStatusReason, msg string, err error, reqLogger logr.Logger) {
+	switch {
+	case stderrors.Is(err, certificatemanager.ErrKeyPairNotFound):
+		reason = operatorv1.ResourceNotFound
+	case stderrors.Is(err, certificatemanager.ErrInvalidCertData):
+		reason = operatorv1.ResourceValidationError
+	case errors.IsForbidden(err):
+		reason = operatorv1.ResourceReadError
+	}
+	statusManager.SetDegraded(reason, msg, err, reqLogger)
+}
+
+// getOrCreateKeyPair returns the key pair in the given secret of the operator namespace. The certificate manager
+// creates and rotates it for the default IntrusionDetection, while an additional one waits for it to exist. When the
+// IntrusionDetection has ProvidedCertificates set, the key pair must exist, and its certificate must be valid for the
+// given DNS names.
+func getOrCreateKeyPair(cm certificatemanager.CertificateManager, cli client.Client, instance *operatorv1.IntrusionDetection, secretName string, dnsNames []string) (certificatemanagement.KeyPairInterface, error) {
+	provided := instance.Spec.ProvidedCertificates != nil && *instance.Spec.ProvidedCertificates
+	if !provided && isDefaultInstance(instance) {
+		return cm.GetOrCreateKeyPair(cli, secretName, common.OperatorNamespace(), dnsNames)
+	}
+
+	// The key pairs in the operator namespace are shared, so an additional IntrusionDetection only uses the ones that
+	// the default IntrusionDetection created.
+	keyPair, err := cm.GetKeyPair(cli, secretName, common.OperatorNamespace(), dnsNames)
+	if err != nil {
+		return nil, err
+	} else if keyPair == nil && !provided {
+		return nil, fmt.Errorf("secret %s/%s is not created by the default IntrusionDetection yet: %w", common.OperatorNamespace(), secretName, certificatemanager.ErrKeyPairNotFound)
+	} else if keyPair == nil {
+		return nil, fmt.Errorf("secret %s/%s is not provided: %w", common.OperatorNamespace(), secretName, certificatemanager.ErrKeyPairNotFound)
+	} else if keyPair.UseCertificateManagement() || !provided {
+		// The certificate is issued by the CSR flow of certificate management, for the given DNS names.
+		return keyPair, nil
+	}
+
+	cert, err := certificatemanagement.ParseCertificate(keyPair.GetCertificatePEM())
+	if err != nil {
+		return nil, err
+	}
+	if err = certificatemanager.HasExpectedDNSNames(secretName, common.OperatorNamespace(), cert, dnsNames); err != nil {
+		return nil, fmt.Errorf("%w, expected %s: %w", err, strings.Join(dnsNames, ", "), certificatemanager.ErrInvalidCertData)
+	}
+	return keyPair, nil
+}
+
+// waitingOn reports that the IntrusionDetection is waiting on a dependency. Besides the degraded status, it sets the
+// DependenciesReady condition to false with the dependency that is being waited on.
+func (r *ReconcileIntrusionDetection) waitingOn(ctx context.Context, instance *operatorv1.IntrusionDetection, reason operatorv1.TigeraStatusReason, msg string, err error, reqLogger logr.Logger) {
+	r.statusFor(instance).SetDegraded(reason, msg, err, reqLogger)
+	if err := r.setDependenciesReady(ctx, instance, false, string(reason), msg); err != nil {
+		reqLogger.Error(err, "Failed to update the IntrusionDetection conditions")
+	}
+}
+
+// setDependenciesReady updates the DependenciesReady condition of the IntrusionDetection, if it has changed.
+func (r *ReconcileIntrusionDetection) setDependenciesReady(ctx context.Context, instance *operatorv1.IntrusionDetection, ready bool, reason, msg string) error {
+	condition := metav1.Condition{
+		Type:               DependenciesReadyConditionType,
+		Status:             metav1.ConditionFalse,
+		Reason:             reason,
+		Message:            msg,
+		ObservedGeneration: instance.Generation,
+	}
+	if ready {
+		condition.Status = metav1.ConditionTrue
+	}
+	return r.setCondition(ctx, instance, condition)
+}
+
+// setCondition sets the given condition on the status of the IntrusionDetection, if it has changed.
+func (r *ReconcileIntrusionDetection) setCondition(ctx context.Context, instance *operatorv1.IntrusionDetection, condition metav1.Condition) error {
+	if !conditionChanged(instance.Status.Conditions, condition) {
+		return nil
+	}
+	return r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+		meta.SetStatusCondition(&s.Conditions, condition)
+	})
+}
+
+// conditionChanged returns whether setting the given condition would change any of the given conditions, other than
+// the time of its last transition.
+func conditionChanged(conditions []metav1.Condition, condition metav1.Condition) bool {
+	current := meta.FindStatusCondition(conditions, condition.Type)
+	return current == nil || current.Status != condition.Status || current.Reason != condition.Reason ||
+		current.Message != condition.Message || current.ObservedGeneration != condition.ObservedGeneration
+}
+
+// updateStatus applies mutate to the status of the IntrusionDetection resource and writes it, retrying on transient
+// errors. The resource is re-read before each retry so that conflicting writes are applied to the latest version.
+func (r *ReconcileIntrusionDetection) updateStatus(ctx context.Context, ids *operatorv1.IntrusionDetection, mutate func(*operatorv1.IntrusionDetectionStatus)) error {
+	refresh := false
+	return utils.RetryOnTransientError(func() error {
+		if refresh {
+			logf.FromContext(ctx).V(2).Info("Retrying IntrusionDetection status update")
+			if err := r.client.Get(ctx, client.ObjectKeyFromObject(ids), ids); err != nil {
+				return err
+			}
+		}
+		refresh = true
+		mutate(&ids.Status)
+		return r.client.Status().Update(ctx, ids)
+	})
+}
+
+// fillDefaults updates the IntrusionDetection resource with defaults if
+// ComponentResources has no DeepPacketInspection entry. Defaults that scale with the nodes are not saved, since they
+// have to follow the nodes; withScaledDPIResourceDefaults recomputes them on every reconcile instead.
+func (r *ReconcileIntrusionDetection) fillDefaults(ctx context.Context, ids *operatorv1.IntrusionDetection) error {
+	if render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection) != nil {
+		return nil
+	}
+	if ids.Spec.ScaleDPIResourceDefaults != nil && *ids.Spec.ScaleDPIResourceDefaults {
+		return nil
+	}
+
+	dpiResources := dpiResourceDefaults(ids, nil)
+
+	refresh := false
+	return utils.RetryOnTransientError(func() error {
+		if refresh {
+			if err := r.client.Get(ctx, client.ObjectKeyFromObject(ids), ids); err != nil {
+				return err
+			}
+		}
+		refresh = true
+
+		if render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection) == nil {
+			ids.Spec.ComponentResources = append(ids.Spec.ComponentResources, operatorv1.IntrusionDetectionComponentResource{
+				ComponentName:        operatorv1.ComponentNameDeepPacketInspection,
+				ResourceRequirements: dpiResources.DeepCopy(),
+			})
+		}
+
+		return r.client.Update(ctx, ids)
+	})
+}
+
+// withScaledDPIResourceDefaults returns the IntrusionDetection that DeepPacketInspection is rendered from. When
+// ScaleDPIResourceDefaults is set and ComponentResources has no DeepPacketInspection entry, that is a copy with the
+// defaults for the current nodes, which are not saved in the resource. Otherwise, it is the given IntrusionDetection.
+func (r *ReconcileIntrusionDetection) withScaledDPIResourceDefaults(ctx context.Context, ids *operatorv1.IntrusionDetection) (*operatorv1.IntrusionDetection, error) {
+	if ids.Spec.ScaleDPIResourceDefaults == nil || !*ids.Spec.ScaleDPIResourceDefaults {
+		return ids, nil
+	}
+	if render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection) != nil {
+		return ids, nil
+	}
+
+	allocatable, err := r.smallestNodeAllocatable(ctx)
+	if err != nil {
+		return nil, err
+	}
+	dpiResources := dpiResourceDefaults(ids, allocatable)
+	scaled := ids.DeepCopy()
+	scaled.Spec.ComponentResources = append(scaled.Spec.ComponentResources, operatorv1.IntrusionDetectionComponentResource{
+		ComponentName:        operatorv1.ComponentNameDeepPacketInspection,
+		ResourceRequirements: &dpiResources,
+	})
+	return scaled, nil
+}
+
+// dpiResourceDefaults returns the default DeepPacketInspection resource requirements. They are scaled to the given
+// allocatable resources of a node, or fixed when there are none, and are whole CPUs when DPICPUPinning is set.
+func dpiResourceDefaults(ids *operatorv1.IntrusionDetection, allocatable corev1.ResourceList) corev1.ResourceRequirements {
+	dpiResources := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(dpi.DefaultMemoryLimit),
+			corev1.ResourceCPU:    resource.MustParse(dpi.DefaultCPULimit),
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceMemory: resource.MustParse(dpi.DefaultMemoryRequest),
+			corev1.ResourceCPU:    resource.MustParse(dpi.DefaultCPURequest),
+		},
+	}
+	if allocatable != nil {
+		dpiResources = dpi.ScaledResourceRequirements(allocatable)
+	}
+	if ids.Spec.DPICPUPinning != nil && *ids.Spec.DPICPUPinning {
+		dpiResources = pinnedResourceDefaults(dpiResources)
+	}
+	return dpiResources
+}
+
+// smallestNodeAllocatable returns the smallest CPU and the smallest memory allocatable across the Linux nodes, which
+// DeepPacketInspection runs on, or nil if there are none.
+func (r *ReconcileIntrusionDetection) smallestNodeAllocatable(ctx context.Context) (corev1.ResourceList, error) {
+	nodes := corev1.NodeList{}
+	if err := r.client.List(ctx, &nodes, client.MatchingLabels{"kubernetes.io/os": "linux"}); err != nil {
+		return nil, err
+	}
+
+	var allocatable corev1.ResourceList
+	for _, node := range nodes.Items {
+		if allocatable == nil {
+			allocatable = corev1.ResourceList{
+				corev1.ResourceCPU:    node.Status.Allocatable.Cpu().DeepCopy(),
+				corev1.ResourceMemory: node.Status.Allocatable.Memory().DeepCopy(),
+			}
+			continue
+		}
+		if node.Status.Allocatable.Cpu().Cmp(*allocatable.Cpu()) < 0 {
+			allocatable[corev1.ResourceCPU] = node.Status.Allocatable.Cpu().DeepCopy()
+		}
+		if node.Status.Allocatable.Memory().Cmp(*allocatable.Memory()) < 0 {
+			allocatable[corev1.ResourceMemory] = node.Status.Allocatable.Memory().DeepCopy()
+		}
+	}
+	return allocatable, nil
+}
@@ -16,19 +16,31 @@ package intrusiondetection
 
 import (
 	"context"
+	"crypto/x509"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"time"
 
+	cmnv1 "github.com/elastic/cloud-on-k8s/v2/pkg/apis/common/v1"
 	esv1 "github.com/elastic/cloud-on-k8s/v2/pkg/apis/elasticsearch/v1"
 
 	"github.com/tigera/operator/pkg/apis"
 
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
+	"github.com/tigera/operator/pkg/render/common/secret"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 
 	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
+	"github.com/tigera/operator/pkg/tls"
+	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
@@ -41,19 +53,25 @@ import (
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/controller/status"
 	"github.com/tigera/operator/pkg/controller/utils"
+	"github.com/tigera/operator/pkg/controller/utils/imageset"
+	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rmeta "github.com/tigera/operator/pkg/render/common/meta"
+	"github.com/tigera/operator/pkg/render/logstorage/esgateway"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	schedulingv1 "k8s.io/api/scheduling/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
@@ -73,6 +91,7 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 		Expect(batchv1.SchemeBuilder.AddToScheme(scheme)).ShouldNot(HaveOccurred())
 		Expect(operatorv1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
 		Expect(storagev1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
+		Expect(schedulingv1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
 		Expect(esv1.SchemeBuilder.AddToScheme(scheme)).NotTo(HaveOccurred())
 
 		// Create a client that will have a crud interface of k8s objects.
@@ -86,6 +105,7 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 		mockStatus.On("RemoveDeployments", mock.Anything).Return()
 		mockStatus.On("AddStatefulSets", mock.Anything).Return()
 		mockStatus.On("AddCronJobs", mock.Anything)
+		mockStatus.On("RemoveCronJobs", mock.Anything)
 		mockStatus.On("IsAvailable").Return(true)
 		mockStatus.On("OnCRFound").Return()
 		mockStatus.On("ClearDegraded")
@@ -275,6 +295,62 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 			Expect(test.GetResource(c, &adAPI)).To(HaveOccurred())
 		})
 
+		It("should report the managed release and component versions in the status", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Status.State).To(Equal(operatorv1.TigeraStatusReady))
+			cond := meta.FindStatusCondition(ids.Status.Conditions, VersionConditionType)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Message).To(ContainSubstring(components.EnterpriseRelease))
+			Expect(cond.Message).To(ContainSubstring(fmt.Sprintf("%s:%s",
+				components.ComponentIntrusionDetectionController.Image, components.ComponentIntrusionDetectionController.Version)))
+			Expect(cond.Message).To(ContainSubstring(fmt.Sprintf("%s:%s",
+				components.ComponentDeepPacketInspection.Image, components.ComponentDeepPacketInspection.Version)))
+		})
+
+		It("should report the dependencies of the IntrusionDetection as ready once they are all in place", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			dependencies := meta.FindStatusCondition(ids.Status.Conditions, DependenciesReadyConditionType)
+			Expect(dependencies).NotTo(BeNil())
+			Expect(dependencies.Status).To(Equal(metav1.ConditionTrue))
+			Expect(dependencies.Reason).To(Equal(AllDependenciesReadyReason))
+			Expect(meta.FindStatusCondition(ids.Status.Conditions, string(operatorv1.ComponentAvailable))).To(BeNil())
+		})
+
+		It("should requeue after the sync period when one is configured", func() {
+			r.syncPeriod = 10 * time.Minute
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(10 * time.Minute))
+		})
+
+		It("should jitter the requeue within the configured fraction of the sync period", func() {
+			r.syncPeriod = 10 * time.Minute
+			r.requeueJitter = 0.2
+			for i := 0; i < 5; i++ {
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">=", 10*time.Minute))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 12*time.Minute))
+			}
+		})
+
+		It("should requeue promptly when degraded even if a sync period is configured", func() {
+			r.syncPeriod = 10 * time.Minute
+			Expect(c.Delete(ctx, &v3.Tier{ObjectMeta: metav1.ObjectMeta{Name: "allow-tigera"}})).NotTo(HaveOccurred())
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(utils.StandardRetry))
+		})
+
 		It("should use images from imageset", func() {
 			Expect(c.Create(ctx, &operatorv1.ImageSet{
 				ObjectMeta: metav1.ObjectMeta{Name: "enterprise-" + components.EnterpriseRelease},
@@ -340,6 +416,74 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 					"sha256:deeppacketinspectionhash")))
 		})
 
+		It("should degrade when an imageset digest is not in the digest allowlist", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceUpdateError, "Error with images from ImageSet", mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &operatorv1.ImageSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "enterprise-" + components.EnterpriseRelease},
+				Spec: operatorv1.ImageSetSpec{
+					Images: []operatorv1.Image{
+						{Image: "tigera/intrusion-detection-controller", Digest: "sha256:intrusiondetectioncontrollerhash"},
+					},
+				},
+			})).ToNot(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: imageset.DigestAllowlistConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{imageset.DigestAllowlistKey: "sha256:someotherhash"},
+			})).ToNot(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceUpdateError, "Error with images from ImageSet", mock.Anything, mock.Anything)
+
+			d := appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller", Namespace: render.IntrusionDetectionNamespace},
+			}
+			Expect(test.GetResource(c, &d)).NotTo(BeNil())
+		})
+
+		It("should re-pin images when the imageset is updated", func() {
+			is := &operatorv1.ImageSet{
+				ObjectMeta: metav1.ObjectMeta{Name: "enterprise-" + components.EnterpriseRelease},
+				Spec: operatorv1.ImageSetSpec{
+					Images: []operatorv1.Image{
+						{Image: "tigera/intrusion-detection-job-installer", Digest: "sha256:intrusiondetectionjobinstallerhash"},
+						{Image: "tigera/intrusion-detection-controller", Digest: "sha256:intrusiondetectioncontrollerhash"},
+						{Image: "tigera/deep-packet-inspection", Digest: "sha256:deeppacketinspectionhash"},
+						{Image: "tigera/webhooks-processor", Digest: "sha256:webhooksprocessorhash"},
+					},
+				},
+			}
+			Expect(c.Create(ctx, is)).ToNot(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			By("updating the controller digest in the imageset")
+			Expect(test.GetResource(c, is)).To(BeNil())
+			is.Spec.Images[1].Digest = "sha256:updatedcontrollerhash"
+			Expect(c.Update(ctx, is)).ToNot(HaveOccurred())
+
+			// The ImageSet watch enqueues a request named after the ImageSet, without any change to the CR.
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: is.Name}})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			d := appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "intrusion-detection-controller",
+					Namespace: render.IntrusionDetectionNamespace,
+				},
+			}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			controller := test.GetContainer(d.Spec.Template.Spec.Containers, "controller")
+			Expect(controller).ToNot(BeNil())
+			Expect(controller.Image).To(Equal(
+				fmt.Sprintf("some.registry.org/%s@%s",
+					components.ComponentIntrusionDetectionController.Image,
+					"sha256:updatedcontrollerhash")))
+		})
+
 		It("should not register intrusion-detection-job-installer image when cluster is managed", func() {
 			Expect(c.Create(ctx, &operatorv1.ManagementClusterConnection{
 				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
@@ -415,6 +559,54 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 		})
 	})
 
+	Context("Elasticsearch gateway certificate", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			Expect(c.Delete(ctx, &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: relasticsearch.PublicCertSecret, Namespace: common.OperatorNamespace()}})).NotTo(HaveOccurred())
+		})
+
+		It("should not depend on the certificate when the cluster is managed", func() {
+			Expect(c.Create(ctx, &operatorv1.ManagementClusterConnection{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+				Spec: operatorv1.ManagementClusterConnectionSpec{
+					ManagementClusterAddr: "127.0.0.1:12345",
+				},
+			})).ToNot(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 0)
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+		})
+
+		It("should wait for the certificate when in a management cluster", func() {
+			Expect(c.Create(ctx, &operatorv1.ManagementCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+				Spec: operatorv1.ManagementClusterSpec{
+					Address: "127.0.0.1:12345",
+				},
+			})).ToNot(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotReady,
+				"Elasticsearch gateway certificate are not available yet, waiting until they become available", mock.Anything, mock.Anything)
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).NotTo(BeNil())
+		})
+	})
+
 	Context("secret availability", func() {
 		BeforeEach(func() {
 			mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
@@ -446,6 +638,84 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 			// The missing secret should force utils.ElasticSearch to return a NotFound error which triggers r.status.SetDegraded.
 			mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 1)
 		})
+
+		It("should report the IntrusionDetection as waiting on the tigera-ee-installer-elasticsearch-access secret", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			dependencies := meta.FindStatusCondition(ids.Status.Conditions, DependenciesReadyConditionType)
+			Expect(dependencies).NotTo(BeNil())
+			Expect(dependencies.Status).To(Equal(metav1.ConditionFalse))
+			Expect(dependencies.Reason).To(Equal(string(operatorv1.ResourceNotFound)))
+			Expect(dependencies.Message).To(Equal("Elasticsearch secrets are not available yet, waiting until they become available"))
+			Expect(ids.Status.State).NotTo(Equal(operatorv1.TigeraStatusReady))
+		})
+
+		It("should list the missing tigera-ee-installer-elasticsearch-access secret in the dependencies", func() {
+			installerSecret := operatorv1.IntrusionDetectionDependency{
+				Kind:      "Secret",
+				Namespace: common.OperatorNamespace(),
+				Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+			}
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Status.Dependencies).To(ContainElement(installerSecret))
+			Expect(ids.Status.Dependencies).To(ContainElement(operatorv1.IntrusionDetectionDependency{
+				Kind:      "ConfigMap",
+				Namespace: common.OperatorNamespace(),
+				Name:      relasticsearch.ClusterConfigConfigMapName,
+				Present:   true,
+			}))
+
+			By("marking the secret as present once it is created")
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: installerSecret.Name, Namespace: installerSecret.Namespace},
+			})).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).ShouldNot(HaveOccurred())
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			installerSecret.Present = true
+			Expect(ids.Status.Dependencies).To(ContainElement(installerSecret))
+		})
+
+		Context("with a missing secret grace period", func() {
+			BeforeEach(func() {
+				r.missingSecrets = newAbsenceTracker()
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Spec.MissingSecretGracePeriodSeconds = ptr.Int32ToPtr(120)
+				Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			})
+
+			It("should not degrade while the secret is within the grace period", func() {
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 120*time.Second))
+				mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 0)
+
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				dependencies := meta.FindStatusCondition(ids.Status.Conditions, DependenciesReadyConditionType)
+				Expect(dependencies).NotTo(BeNil())
+				Expect(dependencies.Message).To(Equal("Waiting up to 2m0s for the Elasticsearch secrets to be created"))
+			})
+
+			It("should degrade once the secret is past the grace period", func() {
+				r.missingSecrets.since["tigera-secure"] = time.Now().Add(-3 * time.Minute)
+
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound,
+					"Elasticsearch secrets are not available yet, waiting until they become available", mock.Anything, mock.Anything)
+			})
+		})
 	})
 
 	Context("Feature intrusion detection not active", func() {
@@ -487,12 +757,269 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 			Expect(installer).To(BeNil())
 		})
 
+		It("should create resources once a license with the feature is applied", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, "Feature is not active - License does not support this feature", mock.Anything, mock.Anything).Return()
+			mockStatus.On("RemoveDaemonsets", mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			d := appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller", Namespace: render.IntrusionDetectionNamespace},
+			}
+			Expect(test.GetResource(c, &d)).NotTo(BeNil())
+
+			By("Applying a license that contains intrusion detection as a feature")
+			license := &v3.LicenseKey{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "default"}, license)).NotTo(HaveOccurred())
+			oldLicense := license.DeepCopy()
+			license.Status.Features = []string{common.ThreatDefenseFeature}
+			Expect(c.Update(ctx, license)).NotTo(HaveOccurred())
+
+			// The license watch enqueues a request for every IntrusionDetection when the features change.
+			Expect(utils.LicenseFeaturesChangedPredicate().Update(event.UpdateEvent{ObjectOld: oldLicense, ObjectNew: license})).To(BeTrue())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(test.GetContainer(d.Spec.Template.Spec.Containers, "controller")).NotTo(BeNil())
+		})
+
+		Context("with a license loss grace period", func() {
+			var d appsv1.Deployment
+
+			BeforeEach(func() {
+				mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+				mockStatus.On("RemoveDaemonsets", mock.Anything).Return()
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+				})).NotTo(HaveOccurred())
+
+				// The components were deployed while the license still granted the feature.
+				d = appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+				Expect(c.Create(ctx, &d)).NotTo(HaveOccurred())
+
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Spec.LicenseLossGracePeriodSeconds = ptr.Int32ToPtr(120)
+				Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			})
+
+			It("should keep the components running while the feature is lost within the grace period", func() {
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 120*time.Second))
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+					"Feature is not active - License does not support this feature, the intrusion detection components will be removed in 2m0s", mock.Anything, mock.Anything)
+
+				Expect(test.GetResource(c, &d)).To(BeNil())
+				Expect(test.GetContainer(d.Spec.Template.Spec.Containers, "controller")).NotTo(BeNil())
+
+				By("keeping the time the feature was lost in the status, so that a restart doesn't start the grace period over")
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				Expect(ids.Status.LicenseFeatureLostTime).NotTo(BeNil())
+				lost := ids.Status.LicenseFeatureLostTime.Time
+				_, err = r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				Expect(ids.Status.LicenseFeatureLostTime.Time).To(BeTemporally("==", lost))
+			})
+
+			It("should remove the components once the grace period is over", func() {
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Status.LicenseFeatureLostTime = &metav1.Time{Time: time.Now().Add(-3 * time.Minute)}
+				Expect(c.Status().Update(ctx, &ids)).NotTo(HaveOccurred())
+
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeZero())
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, "Feature is not active - License does not support this feature", mock.Anything, mock.Anything)
+				Expect(test.GetResource(c, &d)).NotTo(BeNil())
+			})
+
+			It("should not start components that were not running", func() {
+				Expect(c.Delete(ctx, &d)).NotTo(HaveOccurred())
+
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(test.GetResource(c, &d)).NotTo(BeNil())
+			})
+		})
+
 		AfterEach(func() {
 			By("Deleting the previous license")
 			Expect(c.Delete(ctx, &v3.LicenseKey{ObjectMeta: metav1.ObjectMeta{Name: "default"}, Status: v3.LicenseKeyStatus{Features: []string{}}})).NotTo(HaveOccurred())
 		})
 	})
 
+	Context("Operator maintenance and pause", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+
+			Expect(c.Create(ctx, &esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName},
+				Status: esv1.ElasticsearchStatus{
+					Phase: esv1.ElasticsearchReadyPhase,
+				},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should not apply resources while maintenance is active", func() {
+			maintenance := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.MaintenanceConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"active": "true"},
+			}
+			Expect(c.Create(ctx, maintenance)).NotTo(HaveOccurred())
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+			mockStatus.AssertCalled(GinkgoT(), "ClearDegraded")
+			mockStatus.AssertNotCalled(GinkgoT(), "SetDegraded", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			condition := meta.FindStatusCondition(ids.Status.Conditions, MaintenanceConditionType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(MaintenanceActiveReason))
+
+			d := appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "intrusion-detection-controller",
+					Namespace: render.IntrusionDetectionNamespace,
+				},
+			}
+			Expect(test.GetResource(c, &d)).NotTo(BeNil())
+
+			By("ending the maintenance window")
+			Expect(c.Delete(ctx, maintenance)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &d)).To(BeNil())
+
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			condition = meta.FindStatusCondition(ids.Status.Conditions, MaintenanceConditionType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should not modify resources while the IntrusionDetection is paused", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			d := appsv1.Deployment{
+				TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "intrusion-detection-controller",
+					Namespace: render.IntrusionDetectionNamespace,
+				},
+			}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Annotations = map[string]string{utils.PauseAnnotation: "true"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			By("editing the deployment by hand")
+			d.Spec.Template.Spec.Containers[0].Image = "hand-edited:latest"
+			Expect(c.Update(ctx, &d)).NotTo(HaveOccurred())
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result).To(Equal(reconcile.Result{}))
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).To(Equal("hand-edited:latest"))
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Status.State).To(Equal(operatorv1.TigeraStatusPaused))
+
+			By("removing the pause annotation")
+			delete(ids.Annotations, utils.PauseAnnotation)
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(d.Spec.Template.Spec.Containers[0].Image).NotTo(Equal("hand-edited:latest"))
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Status.State).To(Equal(operatorv1.TigeraStatusReady))
+		})
+	})
+
+	Context("IntrusionDetection deletion", func() {
+		BeforeEach(func() {
+			mockStatus.On("OnCRNotFound").Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+
+			Expect(c.Create(ctx, &esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName},
+				Status: esv1.ElasticsearchStatus{
+					Phase: esv1.ElasticsearchReadyPhase,
+				},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should clean up the created objects before removing the finalizer", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Finalizers).To(ContainElement(IntrusionDetectionFinalizer))
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(BeNil())
+
+			// PodTemplates left over from anomaly detection aren't owned by the IntrusionDetection, so they are not
+			// garbage collected with it.
+			podTemplates := []string{render.ADJobPodTemplateBaseName + ".training", render.ADJobPodTemplateBaseName + ".detection"}
+			for _, name := range podTemplates {
+				Expect(c.Create(ctx, &corev1.PodTemplate{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: render.IntrusionDetectionNamespace}})).NotTo(HaveOccurred())
+			}
+
+			r.connectivityChecks = newCheckThrottle()
+			r.connectivityChecks.due("tigera-secure", time.Minute, false)
+			r.credentialChecks = newCredentialCache()
+			r.credentialChecks.accept("tigera-secure", "hash")
+
+			Expect(c.Delete(ctx, &ids)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(r.connectivityChecks.last).To(BeEmpty())
+			Expect(r.credentialChecks.last).To(BeEmpty())
+			Expect(test.GetResource(c, &d)).To(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+			for _, name := range podTemplates {
+				pt := corev1.PodTemplate{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: render.IntrusionDetectionNamespace}}
+				Expect(test.GetResource(c, &pt)).To(HaveOccurred())
+			}
+			Expect(test.GetResource(c, &ids)).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "OnCRNotFound")
+		})
+	})
+
 	Context("Reconcile tests", func() {
 		BeforeEach(func() {
 			mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
@@ -514,264 +1041,2503 @@ var _ = Describe("IntrusionDetection controller tests", func() {
 			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Memory()).Should(Equal(resource.MustParse(dpi.DefaultMemoryLimit)))
 		})
 
-		It("should not overwrite resource requirements if they are already set", func() {
-			By("Deleting the previous IntrusionDetection")
-			Expect(c.Delete(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}})).NotTo(HaveOccurred())
+		It("should default the DPI resources to equal requests and limits when DPI CPU pinning is on", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPICPUPinning = ptr.BoolToPtr(true)
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			resources := render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection)
+			Expect(resources).NotTo(BeNil())
+			Expect(*resources.Requests.Cpu()).Should(Equal(resource.MustParse(dpi.DefaultCPULimit)))
+			Expect(*resources.Requests.Memory()).Should(Equal(resource.MustParse(dpi.DefaultMemoryLimit)))
+			Expect(resources.Requests).To(Equal(resources.Limits))
+		})
+
+		It("should degrade when the DPI packet buffer size has invalid units", func() {
+			for _, size := range []string{"64MB", "1500m", "-1Mi"} {
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Spec.DPIPacketBufferSize = size
+				Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+			}
+			mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 3)
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the DPI interface regex doesn't compile or the BPF filter is empty", func() {
+			regex, filter := "eth[0-9", " "
+			for _, spec := range []operatorv1.IntrusionDetectionSpec{
+				{DPIInterfaceRegex: &regex},
+				{DPIBPFFilter: &filter},
+			} {
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Spec.DPIInterfaceRegex = spec.DPIInterfaceRegex
+				ids.Spec.DPIBPFFilter = spec.DPIBPFFilter
+				Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+			}
+			mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 2)
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade without rendering anything when the Installation is not Calico Enterprise", func() {
+			installation := &operatorv1.Installation{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+			Expect(test.GetResource(c, installation)).To(BeNil())
+			installation.Spec.Variant = operatorv1.Calico
+			installation.Status.Variant = operatorv1.Calico
+			Expect(c.Update(ctx, installation)).NotTo(HaveOccurred())
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeZero())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection requires Calico Enterprise", mock.Anything, mock.Anything)
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			dependencies := meta.FindStatusCondition(ids.Status.Conditions, DependenciesReadyConditionType)
+			Expect(dependencies).NotTo(BeNil())
+			Expect(dependencies.Message).To(Equal("IntrusionDetection requires Calico Enterprise"))
+
+			ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &ns)).NotTo(BeNil())
+		})
+
+		It("should degrade when the extra controller arguments conflict with the operator managed settings", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ControllerExtraArgs = []string{"--linseed-token=/tmp/token"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "The IntrusionDetection controller args conflict with the operator managed settings", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the number of DPI worker threads is out of bounds", func() {
+			for _, threads := range []int32{0, 65} {
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Spec.DPIWorkerThreads = ptr.Int32ToPtr(threads)
+				Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+			}
+			mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 2)
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the Elasticsearch bulk size is out of bounds", func() {
+			for _, size := range []int32{0, 10001} {
+				ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, &ids)).To(BeNil())
+				ids.Spec.ElasticsearchBulkSize = ptr.Int32ToPtr(size)
+				Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("ElasticsearchBulkSize"))
+			}
+			mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 2)
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when two DeepPacketInspection profiles have the same name", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPIProfiles = []operatorv1.DPIProfile{
+				{Name: "edge", NodeSelector: map[string]string{"node-role": "edge"}},
+				{Name: "edge", NodeSelector: map[string]string{"zone": "far"}},
+			}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("more than once"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the DeepPacketInspection profiles need too many node affinity terms", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			for i := 0; i < 6; i++ {
+				ids.Spec.DPIProfiles = append(ids.Spec.DPIProfiles, operatorv1.DPIProfile{
+					Name:         fmt.Sprintf("profile-%d", i),
+					NodeSelector: map[string]string{fmt.Sprintf("a-%d", i): "true", fmt.Sprintf("b-%d", i): "true"},
+				})
+			}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("node affinity terms"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should delete the DaemonSet of a DeepPacketInspection profile that is removed", func() {
+			mockStatus.On("RemoveDaemonsets", mock.Anything)
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPIProfiles = []operatorv1.DPIProfile{
+				{Name: "core", NodeSelector: map[string]string{"node-role": "core"}},
+				{Name: "edge", NodeSelector: map[string]string{"node-role": "edge"}},
+			}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			for _, name := range []string{"tigera-dpi-core", "tigera-dpi-edge"} {
+				ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: dpi.DeepPacketInspectionNamespace}}
+				Expect(test.GetResource(c, &ds)).To(BeNil())
+			}
+
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPIProfiles = ids.Spec.DPIProfiles[:1]
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "tigera-dpi-core", Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+			ds = appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "tigera-dpi-edge", Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).NotTo(BeNil())
+		})
+
+		It("should degrade when the image pull policy is not a known policy", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImagePullPolicy = "Sometimes"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ImagePullPolicy"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should back off exponentially when the same failure keeps recurring and reset after a success", func() {
+			r.failures = newFailureBreaker()
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImagePullPolicy = "Sometimes"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			By("failing until the threshold is reached")
+			for i := 1; i < failureBreakerThreshold; i++ {
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+			}
+
+			By("backing off with a growing delay")
+			for _, expected := range []time.Duration{30 * time.Second, time.Minute, 2 * time.Minute, 4 * time.Minute, 8 * time.Minute, 10 * time.Minute, 10 * time.Minute} {
+				result, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(Equal(expected))
+			}
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError,
+				"Reconcile failed 3 times in a row with the same error, retrying in 30s", mock.Anything, mock.Anything)
+
+			By("resetting after a successful reconcile")
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImagePullPolicy = ""
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImagePullPolicy = "Sometimes"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should degrade when the DPI log severity is not a known level", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			severity := operatorv1.LogLevel("Verbose")
+			ids.Spec.DPILogSeverity = &severity
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("DPILogSeverity"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when DPI CPU pinning is on and the DPI CPU resources are not whole CPUs", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPICPUPinning = ptr.BoolToPtr(true)
+			ids.Spec.ComponentResources = []operatorv1.IntrusionDetectionComponentResource{{
+				ComponentName: operatorv1.ComponentNameDeepPacketInspection,
+				ResourceRequirements: &corev1.ResourceRequirements{
+					Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")},
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("1500m")},
+				},
+			}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("whole number of CPUs"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when a Localhost DPI seccomp profile does not name a profile", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPISeccompProfile = &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("DPISeccompProfile"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the Elasticsearch query timeout is not a duration", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ElasticsearchQueryTimeout = "90"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ElasticsearchQueryTimeout"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the index lifecycle phases are not in order", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.IndexLifecycle = &operatorv1.IntrusionDetectionIndexLifecycle{
+				Warm:         &operatorv1.IndexLifecyclePhase{MinAge: "30d"},
+				Cold:         &operatorv1.IndexLifecyclePhase{MinAge: "168h"},
+				DeleteMinAge: "90d",
+			}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.IndexLifecycle.Cold.MinAge 168h must be later than Warm.MinAge"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the controller preStop hook uses tcpSocket", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ControllerPreStop = &corev1.LifecycleHandler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(9094)}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ControllerPreStop"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the pod annotations of a component are invalid", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ComponentPodAnnotations = []operatorv1.IntrusionDetectionComponentPodAnnotations{{
+				ComponentName: operatorv1.ComponentNameDeepPacketInspection,
+				Annotations:   map[string]string{"not a valid key": "false"},
+			}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ComponentPodAnnotations of DeepPacketInspection are invalid"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when an image override is not a valid image reference", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImageOverrides = map[string]string{"tigera/intrusion-detection-controller": "quay.io/tigera/intrusion-detection-controller"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must have a tag or a digest"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when an image override is for an image that isn't part of intrusion detection", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImageOverrides = map[string]string{"tigera/cnx-node": "quay.io/tigera/cnx-node:hotfix"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("isn't part of intrusion detection"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when an image path remapping is not a valid image name", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImagePathRemappings = map[string]string{"tigera/intrusion-detection-controller": "mirror/security/idc:v3.17.1"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.ImagePathRemappings for \"tigera/intrusion-detection-controller\" is invalid"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when a DPI sysctl is of the network namespace", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPISysctls = []corev1.Sysctl{{Name: "net.core.rmem_max", Value: "134217728"}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.DPISysctls \"net.core.rmem_max\" is a sysctl of the network namespace"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when a DPI sysctl is not safe", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPISysctls = []corev1.Sysctl{{Name: "kernel.msgmax", Value: "65536"}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.DPISysctls \"kernel.msgmax\" is not a sysctl that Kubernetes considers safe"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the installer Job TTL is not positive", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.InstallerJobTTLSecondsAfterFinished = ptr.Int32ToPtr(0)
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("InstallerJobTTLSecondsAfterFinished 0 must be at least 1"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the DNS policy is None without any nameservers", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DNSPolicy = corev1.DNSNone
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("DNSConfig"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should mount the DeepPacketInspection Typha CA bundle once it exists", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPITyphaCABundle = &corev1.ConfigMapKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "typha-custom-ca"},
+				Key:                  "bundle.pem",
+			}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound,
+				"The DeepPacketInspection Typha CA bundle ConfigMap tigera-operator/typha-custom-ca was not found", mock.Anything, mock.Anything)
+
+			By("creating the CA bundle")
+			ca, err := tls.MakeCA("typha-custom-ca")
+			Expect(err).NotTo(HaveOccurred())
+			caPEM, _, err := ca.Config.GetPEMBytes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "typha-custom-ca", Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"bundle.pem": string(caPEM)},
+			})).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "typha-custom-ca", Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &cm)).To(BeNil())
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+			Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "DPI_TYPHACAFILE", Value: "/etc/pki/typha-ca/ca.crt"}))
+		})
+
+		It("should run DeepPacketInspection with its own PriorityClass once it exists", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			priorityClass := "dpi-critical"
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPIPriorityClassName = &priorityClass
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound,
+				"The DeepPacketInspection PriorityClass dpi-critical was not found", mock.Anything, mock.Anything)
+
+			By("creating the PriorityClass")
+			Expect(c.Create(ctx, &schedulingv1.PriorityClass{
+				ObjectMeta: metav1.ObjectMeta{Name: "dpi-critical"},
+				Value:      1000000,
+			})).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+			Expect(ds.Spec.Template.Spec.PriorityClassName).To(Equal("dpi-critical"))
+			deploy := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &deploy)).To(BeNil())
+			Expect(deploy.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+		})
+
+		It("should degrade when an installer CA bundle is not valid PEM", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			ca, err := tls.MakeCA("proxy-ca")
+			Expect(err).NotTo(HaveOccurred())
+			caPEM, _, err := ca.Config.GetPEMBytes()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "installer-cas", Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"proxy.pem": string(caPEM), "corp.pem": "not a certificate"},
+			})).NotTo(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.InstallerCABundles = []corev1.ConfigMapKeySelector{
+				{LocalObjectReference: corev1.LocalObjectReference{Name: "installer-cas"}, Key: "proxy.pem"},
+				{LocalObjectReference: corev1.LocalObjectReference{Name: "installer-cas"}, Key: "corp.pem"},
+			}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+				`Key "corp.pem" of the installer CA bundle ConfigMap installer-cas is not a valid PEM bundle`, mock.Anything, mock.Anything)
+
+			By("mounting both bundles once they are valid")
+			cm := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "installer-cas", Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, &cm)).To(BeNil())
+			cm.Data["corp.pem"] = string(caPEM)
+			Expect(c.Update(ctx, &cm)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			copied := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "installer-cas", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &copied)).To(BeNil())
+			job := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &job)).To(BeNil())
+			Expect(job.Spec.Template.Spec.Volumes).To(ContainElements(
+				HaveField("Name", "installer-ca-bundle-0"),
+				HaveField("Name", "installer-ca-bundle-1"),
+			))
+
+			By("deleting the copy once the bundles are no longer referenced")
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.InstallerCABundles = nil
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &copied)).To(HaveOccurred())
+		})
+
+		It("should only deploy DeepPacketInspection for resources in the namespaces the selector selects", func() {
+			mockStatus.On("RemoveDaemonsets", mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "test-dpi-ns", Labels: map[string]string{"tenant": "a"}}})).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b", Labels: map[string]string{"tenant": "b"}}})).NotTo(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPINamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tenant": "b"}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			By("ignoring the DeepPacketInspection resource in the excluded namespace")
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).NotTo(BeNil())
+
+			By("acting on a DeepPacketInspection resource in the included namespace")
+			Expect(c.Create(ctx, &v3.DeepPacketInspection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-b-dpi", Namespace: "tenant-b"}})).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+		})
+
+		It("should degrade when the DPI namespace selector is not valid", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.DPINamespaceSelector = &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "tenant", Operator: metav1.LabelSelectorOpIn},
+			}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should copy the detection rule ConfigMaps into the intrusion detection namespace", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.AdditionalDetectionRuleConfigMaps = []string{"custom-rules", "more-rules"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"rules.yaml": "rules"},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound,
+				"The detection rule ConfigMap more-rules was not found in tigera-operator or tigera-intrusion-detection", mock.Anything, mock.Anything)
+
+			By("creating the second ConfigMap in the intrusion detection namespace")
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "more-rules", Namespace: render.IntrusionDetectionNamespace},
+			})).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			cm := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &cm)).To(BeNil())
+			Expect(cm.Data).To(Equal(map[string]string{"rules.yaml": "rules"}))
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(d.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElements(
+				corev1.VolumeMount{Name: "detection-rules-0", MountPath: "/etc/tigera/detection-rules/custom-rules", ReadOnly: true},
+				corev1.VolumeMount{Name: "detection-rules-1", MountPath: "/etc/tigera/detection-rules/more-rules", ReadOnly: true},
+			))
+
+			By("deleting the copy once it is no longer referenced")
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.AdditionalDetectionRuleConfigMaps = nil
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, &cm)).To(HaveOccurred())
+			userCM := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "more-rules", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &userCM)).To(BeNil())
+		})
+
+		It("should degrade naming the image that a pod fails to pull", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller-abc", Namespace: render.IntrusionDetectionNamespace},
+				Status: corev1.PodStatus{
+					ContainerStatuses: []corev1.ContainerStatus{{
+						Name:  "controller",
+						Image: "some.registry.org/tigera/intrusion-detection-controller:missing",
+						State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+							Reason:  "ImagePullBackOff",
+							Message: "Back-off pulling image",
+						}},
+					}},
+				},
+			})).NotTo(HaveOccurred())
+			msg := "Pod tigera-intrusion-detection/intrusion-detection-controller-abc failed to pull image " +
+				"some.registry.org/tigera/intrusion-detection-controller:missing for container controller: Back-off pulling image"
+			mockStatus.On("SetDegraded", operatorv1.PodFailure, msg, nil, mock.Anything).Return()
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(utils.StandardRetry))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.PodFailure, msg, nil, mock.Anything)
+		})
+
+		It("should reconcile when a referenced ConfigMap changes", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.AdditionalDetectionRuleConfigMaps = []string{"custom-rules"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			rules := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"rules.yaml": "rules"},
+			}
+			Expect(c.Create(ctx, rules)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("mapping a change of the referenced ConfigMap to the IntrusionDetection")
+			mapFunc := referencedConfigMapRequests(c)
+			rules.Data = map[string]string{"rules.yaml": "updated rules"}
+			Expect(c.Update(ctx, rules)).NotTo(HaveOccurred())
+			requests := mapFunc(rules)
+			Expect(requests).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}}))
+			Expect(mapFunc(&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: "some-namespace"},
+			})).To(BeEmpty())
+
+			_, err = r.Reconcile(ctx, requests[0])
+			Expect(err).NotTo(HaveOccurred())
+			cm := corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &cm)).To(BeNil())
+			Expect(cm.Data).To(Equal(map[string]string{"rules.yaml": "updated rules"}))
+
+			By("no longer mapping the ConfigMap once the reference is renamed")
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.AdditionalDetectionRuleConfigMaps = []string{"other-rules"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			Expect(mapFunc(rules)).To(BeEmpty())
+		})
+
+		It("should degrade when the awareness attributes are not advertised by the Elasticsearch nodes", func() {
+			Expect(c.Update(ctx, relasticsearch.NewClusterConfig("cluster", 1, 1, 1, "zone").ConfigMap())).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "Elasticsearch shard allocation awareness is misconfigured", mock.Anything, mock.Anything)
+
+			By("advertising the attribute on the Elasticsearch nodes")
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace}}
+			Expect(test.GetResource(c, &es)).To(BeNil())
+			es.Spec.NodeSets = []esv1.NodeSet{{
+				Name:   "default",
+				Config: &cmnv1.Config{Data: map[string]interface{}{"node.attr.zone": "us-west-2a"}},
+			}}
+			Expect(c.Update(ctx, &es)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(BeNil())
+			installer := test.GetContainer(j.Spec.Template.Spec.Containers, "elasticsearch-job-installer")
+			Expect(installer.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_ALLOCATION_AWARENESS_ATTRIBUTES", Value: "zone"}))
+		})
+
+		It("should roll the controller when an Elasticsearch user secret is rotated", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			hash := d.Spec.Template.Annotations["hash.operator.tigera.io/elasticsearch-secrets"]
+			Expect(hash).NotTo(BeEmpty())
+
+			secret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, &secret)).To(BeNil())
+			secret.Data = map[string][]byte{"username": []byte("tigera-ee-intrusion-detection"), "password": []byte("rotated")}
+			Expect(c.Update(ctx, &secret)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(d.Spec.Template.Annotations["hash.operator.tigera.io/elasticsearch-secrets"]).NotTo(Equal(hash))
+		})
+
+		It("should only run the installer against the pinned Elasticsearch version", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			es := esv1.Elasticsearch{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace}}
+			Expect(test.GetResource(c, &es)).To(BeNil())
+			es.Spec.Version = "8.6.2"
+			Expect(c.Update(ctx, &es)).NotTo(HaveOccurred())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ElasticsearchVersion = "7.17"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "The running Elasticsearch does not match the pinned version", mock.Anything, mock.Anything)
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+
+			By("pinning the running version")
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ElasticsearchVersion = "8.6"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(BeNil())
+			installer := test.GetContainer(j.Spec.Template.Spec.Containers, "elasticsearch-job-installer")
+			Expect(installer.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_VERSION", Value: "8.6"}))
+		})
+
+		It("should remove the installer Job when the operator switches to an external Elasticsearch", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(BeNil())
+
+			By("switching to an external Elasticsearch without restarting the controller")
+			Expect(c.Delete(ctx, &esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace},
+			})).NotTo(HaveOccurred())
+			bootstrap := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.BootstrapConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"ELASTIC_EXTERNAL": "true"},
+			}
+			Expect(c.Create(ctx, bootstrap)).NotTo(HaveOccurred())
+			Expect(referencedConfigMapRequests(c)(bootstrap)).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}}))
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+			policy := v3.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerPolicyName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &policy)).To(HaveOccurred())
+		})
+
+		It("should not recreate the installer Job once it has completed and been deleted", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(BeNil())
+			hash := j.Annotations[render.InstallerJobHashAnnotation]
+			Expect(hash).NotTo(BeEmpty())
+
+			By("recording the configuration that the Job completed with")
+			j.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+			Expect(c.Update(ctx, &j)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Status.InstallerJobCompletedHash).To(Equal(hash))
+
+			By("leaving the Job deleted once its TTL has passed")
+			Expect(c.Delete(ctx, &j)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+
+			By("running the installer again once its configuration changes")
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ImagePullPolicy = corev1.PullAlways
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(BeNil())
+			Expect(j.Annotations[render.InstallerJobHashAnnotation]).NotTo(Equal(hash))
+		})
+
+		It("should not recreate the installer Job when it is deleted before its completion was seen", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(BeNil())
+			Expect(j.Finalizers).To(ContainElement(render.InstallerJobFinalizer))
+			hash := j.Annotations[render.InstallerJobHashAnnotation]
+
+			By("completing the Job and deleting it without a reconcile in between")
+			j.Status.Conditions = []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: corev1.ConditionTrue}}
+			Expect(c.Update(ctx, &j)).NotTo(HaveOccurred())
+			Expect(c.Delete(ctx, &j)).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(BeNil())
+			Expect(j.DeletionTimestamp).NotTo(BeNil())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Status.InstallerJobCompletedHash).To(Equal(hash))
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+		})
+
+		It("should replace the installer Job with a CronJob when a schedule is set", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			j := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &j)).To(BeNil())
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.InstallerSchedule = "@daily"
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &j)).To(HaveOccurred())
+			cj := batchv1.CronJob{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &cj)).To(BeNil())
+			Expect(cj.Spec.Schedule).To(Equal("@daily"))
+			mockStatus.AssertCalled(GinkgoT(), "AddCronJobs", []types.NamespacedName{{Name: render.IntrusionDetectionInstallerJobName, Namespace: render.IntrusionDetectionNamespace}})
+		})
+
+		It("should reconcile additional IntrusionDetections into namespaces of their own", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})).NotTo(HaveOccurred())
+
+			By("waiting for the default IntrusionDetection to create the shared key pair")
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-a"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("is not created by the default IntrusionDetection yet"))
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("reconciling a request for the additional IntrusionDetection")
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			tlsSecret := corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionTLSSecretName, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, &tlsSecret)).To(BeNil())
+			Expect(tlsSecret.OwnerReferences).To(HaveLen(1))
+			Expect(tlsSecret.OwnerReferences[0].Name).To(Equal("tigera-secure"))
+
+			tenantDeployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerName, Namespace: "tigera-intrusion-detection-tenant-a"}}
+			Expect(test.GetResource(c, &tenantDeployment)).To(BeNil())
+			Expect(tenantDeployment.OwnerReferences).To(HaveLen(1))
+			Expect(tenantDeployment.OwnerReferences[0].Name).To(Equal("tenant-a"))
+			defaultDeployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerName, Namespace: render.IntrusionDetectionNamespace}}
+
+			By("reconciling any other request")
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: "tigera-operator"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, &defaultDeployment)).To(BeNil())
+			Expect(defaultDeployment.OwnerReferences[0].Name).To(Equal("tigera-secure"))
+			Expect(test.GetResource(c, &tenantDeployment)).To(BeNil())
+		})
+
+		It("should wait for the default IntrusionDetection before reconciling an additional one", func() {
+			Expect(c.Delete(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}})).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})).NotTo(HaveOccurred())
+
+			result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-a"}})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound, "Waiting for the default IntrusionDetection tigera-secure to be created", mock.Anything, mock.Anything)
+
+			ns := corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tigera-intrusion-detection-tenant-a"}}
+			Expect(test.GetResource(c, &ns)).NotTo(BeNil())
+		})
+
+		It("should report additional IntrusionDetections in TigeraStatuses of their own", func() {
+			tenantStatus := &status.MockStatus{}
+			tenantStatus.On("OnCRFound").Return()
+			tenantStatus.On("SetMetaData", mock.Anything).Return()
+			tenantStatus.On("SetDegraded", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			var components []string
+			r.statuses = newInstanceStatuses(func(component string) status.StatusManager {
+				components = append(components, component)
+				return tenantStatus
+			})
+			name := strings.Repeat("a", 50)
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: name}})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+			Expect(err).To(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+			Expect(err).To(HaveOccurred())
+			Expect(components).To(Equal([]string{"intrusion-detection-" + name}))
+			tenantStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+			mockStatus.AssertNotCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should track the failures of each IntrusionDetection separately", func() {
+			mockStatus.On("SetDegraded", operatorv1.Unknown, mock.Anything, mock.Anything, mock.Anything).Return()
+			r.failures = newFailureBreaker()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			name := strings.Repeat("a", 50)
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: name}})).NotTo(HaveOccurred())
+			request := reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+
+			for i := 1; i < failureBreakerThreshold; i++ {
+				_, err := r.Reconcile(ctx, request)
+				Expect(err).To(HaveOccurred())
+			}
+
+			By("not resetting the failures when another IntrusionDetection succeeds")
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}})
+			Expect(err).NotTo(HaveOccurred())
+
+			result, err := r.Reconcile(ctx, request)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(30 * time.Second))
+		})
+
+		It("should reconcile every IntrusionDetection when one of them fails", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			// The default IntrusionDetection creates the key pair that the others use.
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}})
+			Expect(err).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			filter := " "
+			ids.Spec.DPIBPFFilter = &filter
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})).NotTo(HaveOccurred())
+
+			// The default IntrusionDetection is reconciled first, and fails.
+			_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: "tigera-operator"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.DPIBPFFilter"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+
+			tenantDeployment := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerName, Namespace: "tigera-intrusion-detection-tenant-a"}}
+			Expect(test.GetResource(c, &tenantDeployment)).To(BeNil())
+		})
+
+		It("should degrade with a precise message when a certificate is invalid", func() {
+			msg := fmt.Sprintf("Failed to retrieve / validate  %s", render.TigeraLinseedSecret)
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, msg, mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			linseedSecret := &corev1.Secret{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: render.TigeraLinseedSecret, Namespace: common.OperatorNamespace()}, linseedSecret)).NotTo(HaveOccurred())
+			linseedSecret.Data[corev1.TLSCertKey] = []byte("not a certificate")
+			Expect(c.Update(ctx, linseedSecret)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, msg, mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when an IntrusionDetection name can't be used for its namespace", func() {
+			name := strings.Repeat("a", 50)
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: name}})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade when the controller volumes conflict with the operator managed volumes", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+					Namespace: "tigera-operator",
+				},
+			})).NotTo(HaveOccurred())
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ControllerVolumes = []corev1.Volume{{Name: "tigera-ca-bundle", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "The IntrusionDetection controller volumes conflict with the operator managed volumes", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade until a referenced service account exists", func() {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			ids.Spec.ServiceAccounts = &operatorv1.IntrusionDetectionServiceAccounts{DeepPacketInspection: "dpi-irsa"}
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound, "The referenced ServiceAccount tigera-dpi/dpi-irsa was not found", mock.Anything, mock.Anything)
+
+			Expect(c.Create(ctx, &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "dpi-irsa", Namespace: dpi.DeepPacketInspectionNamespace}})).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should scale the default DPI resources with the smallest node when enabled", func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			for name, allocatable := range map[string]corev1.ResourceList{
+				"small": {corev1.ResourceCPU: resource.MustParse("4"), corev1.ResourceMemory: resource.MustParse("8Gi")},
+				"large": {corev1.ResourceCPU: resource.MustParse("64"), corev1.ResourceMemory: resource.MustParse("256Gi")},
+			} {
+				Expect(c.Create(ctx, &corev1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"kubernetes.io/os": "linux"}},
+					Status:     corev1.NodeStatus{Allocatable: allocatable},
+				})).NotTo(HaveOccurred())
+			}
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			scale := true
+			ids.Spec.ScaleDPIResourceDefaults = &scale
+			Expect(c.Update(ctx, &ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Spec.ComponentResources).To(BeEmpty())
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+			Expect(*ds.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu()).To(Equal(resource.MustParse("200m")))
+			Expect(*ds.Spec.Template.Spec.Containers[0].Resources.Limits.Memory()).To(Equal(resource.MustParse("2Gi")))
+
+			By("following the nodes once the small node is gone")
+			Expect(c.Delete(ctx, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "small"}})).NotTo(HaveOccurred())
+			Expect(scaledDPIResourceRequests(c)(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "small"}})).
+				To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}}))
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+			Expect(ds.Spec.Template.Spec.Containers[0].Resources.Requests.Cpu().Cmp(resource.MustParse("200m"))).To(BeNumerically(">", 0))
+			Expect(ds.Spec.Template.Spec.Containers[0].Resources.Limits.Memory().Cmp(resource.MustParse("2Gi"))).To(BeNumerically(">", 0))
+		})
+
+		It("should not overwrite resource requirements if they are already set", func() {
+			By("Deleting the previous IntrusionDetection")
+			Expect(c.Delete(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}})).NotTo(HaveOccurred())
+
+			memoryLimit := "5Gi"
+			memoryRequest := "5Gi"
+			cpuLimit := "3"
+			cpuRequest := "2"
+
+			By("Creating IntrusionDetection resource with custom resource requirements")
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{
+				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+				Spec: operatorv1.IntrusionDetectionSpec{
+					ComponentResources: []operatorv1.IntrusionDetectionComponentResource{
+						{
+							ComponentName: operatorv1.ComponentNameDeepPacketInspection,
+							ResourceRequirements: &corev1.ResourceRequirements{
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse(memoryLimit),
+									corev1.ResourceCPU:    resource.MustParse(cpuLimit),
+								},
+								Requests: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse(memoryRequest),
+									corev1.ResourceCPU:    resource.MustParse(cpuRequest),
+								},
+							},
+						},
+					},
+				},
+			})).
+				NotTo(HaveOccurred())
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(0 * time.Second))
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Spec.ComponentResources).ShouldNot(BeNil())
+			Expect(len(ids.Spec.ComponentResources)).Should(Equal(1))
+			Expect(ids.Spec.ComponentResources[0].ComponentName).Should(Equal(operatorv1.ComponentNameDeepPacketInspection))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Cpu()).Should(Equal(resource.MustParse(cpuRequest)))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Cpu()).Should(Equal(resource.MustParse(cpuLimit)))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Memory()).Should(Equal(resource.MustParse(memoryRequest)))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Memory()).Should(Equal(resource.MustParse(memoryLimit)))
+		})
+	})
+
+	Context("Reconcile for Condition status", func() {
+		generation := int64(2)
+
+		It("should reconcile with creating new status condition with one item", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
+			ts := &operatorv1.TigeraStatus{
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
+				Spec:       operatorv1.TigeraStatusSpec{},
+				Status: operatorv1.TigeraStatusStatus{
+					Conditions: []operatorv1.TigeraStatusCondition{
+						{
+							Type:               operatorv1.ComponentAvailable,
+							Status:             operatorv1.ConditionTrue,
+							Reason:             string(operatorv1.AllObjectsAvailable),
+							Message:            "All Objects are available",
+							ObservedGeneration: generation,
+						},
+					},
+				},
+			}
+			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      "intrusion-detection",
+				Namespace: "",
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			instance := &operatorv1.IntrusionDetection{}
+			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(instance.Status.Conditions).To(HaveLen(2))
+			Expect(instance.Status.Conditions[0].Type).To(Equal("Ready"))
+			Expect(string(instance.Status.Conditions[0].Status)).To(Equal(string(operatorv1.ConditionTrue)))
+			Expect(instance.Status.Conditions[0].Reason).To(Equal(string(operatorv1.AllObjectsAvailable)))
+			Expect(instance.Status.Conditions[0].Message).To(Equal("All Objects are available"))
+			Expect(instance.Status.Conditions[0].ObservedGeneration).To(Equal(generation))
+		})
+
+		It("should reconcile with empty tigerastatus conditions ", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
+			ts := &operatorv1.TigeraStatus{
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
+				Spec:       operatorv1.TigeraStatusSpec{},
+				Status:     operatorv1.TigeraStatusStatus{},
+			}
+			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      "intrusion-detection",
+				Namespace: "",
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			instance := &operatorv1.IntrusionDetection{}
+			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(instance.Status.Conditions).To(HaveLen(1))
+		})
+
+		It("should reconcile with creating new status condition  with multiple conditions as true", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
+			ts := &operatorv1.TigeraStatus{
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
+				Spec:       operatorv1.TigeraStatusSpec{},
+				Status: operatorv1.TigeraStatusStatus{
+					Conditions: []operatorv1.TigeraStatusCondition{
+						{
+							Type:               operatorv1.ComponentAvailable,
+							Status:             operatorv1.ConditionTrue,
+							Reason:             string(operatorv1.AllObjectsAvailable),
+							Message:            "All Objects are available",
+							ObservedGeneration: generation,
+						},
+						{
+							Type:               operatorv1.ComponentProgressing,
+							Status:             operatorv1.ConditionTrue,
+							Reason:             string(operatorv1.ResourceNotReady),
+							Message:            "Progressing Installation.operatorv1.tigera.io",
+							ObservedGeneration: generation,
+						},
+						{
+							Type:               operatorv1.ComponentDegraded,
+							Status:             operatorv1.ConditionTrue,
+							Reason:             string(operatorv1.ResourceUpdateError),
+							Message:            "Error resolving ImageSet for components",
+							ObservedGeneration: generation,
+						},
+					},
+				},
+			}
+			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      "intrusion-detection",
+				Namespace: "",
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			instance := &operatorv1.IntrusionDetection{}
+			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(instance.Status.Conditions).To(HaveLen(4))
+			Expect(instance.Status.Conditions[0].Type).To(Equal("Ready"))
+			Expect(string(instance.Status.Conditions[0].Status)).To(Equal(string(operatorv1.ConditionTrue)))
+			Expect(instance.Status.Conditions[0].Reason).To(Equal(string(operatorv1.AllObjectsAvailable)))
+			Expect(instance.Status.Conditions[0].Message).To(Equal("All Objects are available"))
+			Expect(instance.Status.Conditions[0].ObservedGeneration).To(Equal(generation))
+
+			Expect(instance.Status.Conditions[1].Type).To(Equal("Progressing"))
+			Expect(string(instance.Status.Conditions[1].Status)).To(Equal(string(operatorv1.ConditionTrue)))
+			Expect(instance.Status.Conditions[1].Reason).To(Equal(string(operatorv1.ResourceNotReady)))
+			Expect(instance.Status.Conditions[1].Message).To(Equal("Progressing Installation.operatorv1.tigera.io"))
+			Expect(instance.Status.Conditions[1].ObservedGeneration).To(Equal(generation))
+
+			Expect(instance.Status.Conditions[2].Type).To(Equal("Degraded"))
+			Expect(string(instance.Status.Conditions[2].Status)).To(Equal(string(operatorv1.ConditionTrue)))
+			Expect(instance.Status.Conditions[2].Reason).To(Equal(string(operatorv1.ResourceUpdateError)))
+			Expect(instance.Status.Conditions[2].Message).To(Equal("Error resolving ImageSet for components"))
+			Expect(instance.Status.Conditions[2].ObservedGeneration).To(Equal(generation))
+		})
+
+		It("should reconcile with creating new status condition and toggle Available to true & others to false", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
+			ts := &operatorv1.TigeraStatus{
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
+				Spec:       operatorv1.TigeraStatusSpec{},
+				Status: operatorv1.TigeraStatusStatus{
+					Conditions: []operatorv1.TigeraStatusCondition{
+						{
+							Type:               operatorv1.ComponentAvailable,
+							Status:             operatorv1.ConditionTrue,
+							Reason:             string(operatorv1.AllObjectsAvailable),
+							Message:            "All Objects are available",
+							ObservedGeneration: generation,
+						},
+						{
+							Type:               operatorv1.ComponentProgressing,
+							Status:             operatorv1.ConditionFalse,
+							Reason:             string(operatorv1.NotApplicable),
+							Message:            "Not Applicable",
+							ObservedGeneration: generation,
+						},
+						{
+							Type:               operatorv1.ComponentDegraded,
+							Status:             operatorv1.ConditionFalse,
+							Reason:             string(operatorv1.NotApplicable),
+							Message:            "Not Applicable",
+							ObservedGeneration: generation,
+						},
+					},
+				},
+			}
+			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      "intrusion-detection",
+				Namespace: "",
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			instance := &operatorv1.IntrusionDetection{}
+			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
+			Expect(err).ShouldNot(HaveOccurred())
+
+			Expect(instance.Status.Conditions).To(HaveLen(4))
+			Expect(instance.Status.Conditions[0].Type).To(Equal("Ready"))
+			Expect(string(instance.Status.Conditions[0].Status)).To(Equal(string(operatorv1.ConditionTrue)))
+			Expect(instance.Status.Conditions[0].Reason).To(Equal(string(operatorv1.AllObjectsAvailable)))
+			Expect(instance.Status.Conditions[0].Message).To(Equal("All Objects are available"))
+			Expect(instance.Status.Conditions[0].ObservedGeneration).To(Equal(generation))
+
+			Expect(instance.Status.Conditions[1].Type).To(Equal("Progressing"))
+			Expect(string(instance.Status.Conditions[1].Status)).To(Equal(string(operatorv1.ConditionFalse)))
+			Expect(instance.Status.Conditions[1].Reason).To(Equal(string(operatorv1.NotApplicable)))
+			Expect(instance.Status.Conditions[1].Message).To(Equal("Not Applicable"))
+			Expect(instance.Status.Conditions[1].ObservedGeneration).To(Equal(generation))
+
+			Expect(instance.Status.Conditions[2].Type).To(Equal("Degraded"))
+			Expect(string(instance.Status.Conditions[2].Status)).To(Equal(string(operatorv1.ConditionFalse)))
+			Expect(instance.Status.Conditions[2].Reason).To(Equal(string(operatorv1.NotApplicable)))
+			Expect(instance.Status.Conditions[2].Message).To(Equal("Not Applicable"))
+			Expect(instance.Status.Conditions[2].ObservedGeneration).To(Equal(generation))
+		})
+
+		It("should keep the conditions copied from the TigeraStatus next to the DependenciesReady condition", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
+			ts := &operatorv1.TigeraStatus{
+				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
+				Spec:       operatorv1.TigeraStatusSpec{},
+				Status: operatorv1.TigeraStatusStatus{
+					Conditions: []operatorv1.TigeraStatusCondition{
+						{
+							Type:               operatorv1.ComponentProgressing,
+							Status:             operatorv1.ConditionTrue,
+							Reason:             string(operatorv1.ResourceNotReady),
+							Message:            "Progressing Installation.operatorv1.tigera.io",
+							ObservedGeneration: generation,
+						},
+					},
+				},
+			}
+			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
+				Name:      "intrusion-detection",
+				Namespace: "",
+			}})
+			Expect(err).ShouldNot(HaveOccurred())
+			instance := &operatorv1.IntrusionDetection{}
+			Expect(r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)).NotTo(HaveOccurred())
+
+			progressing := meta.FindStatusCondition(instance.Status.Conditions, string(operatorv1.ComponentProgressing))
+			Expect(progressing).NotTo(BeNil())
+			Expect(progressing.Message).To(Equal("Progressing Installation.operatorv1.tigera.io"))
+
+			// The reconcile is waiting on the Elasticsearch secrets, which is reported in a condition of its own.
+			dependencies := meta.FindStatusCondition(instance.Status.Conditions, DependenciesReadyConditionType)
+			Expect(dependencies).NotTo(BeNil())
+			Expect(dependencies.Status).To(Equal(metav1.ConditionFalse))
+			Expect(dependencies.Reason).To(Equal(string(operatorv1.ResourceNotFound)))
+			Expect(dependencies.Message).To(Equal("Elasticsearch secrets are not available yet, waiting until they become available"))
+		})
+	})
+
+	Context("External ES mode", func() {
+		BeforeEach(func() {
+			// Delete the Elasticsearch CR. This is created for ECK only.
+			Expect(c.Delete(ctx, &esv1.Elasticsearch{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace},
+			})).NotTo(HaveOccurred())
+
+			// Configure the operator to run in external ES mode for these tests.
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.BootstrapConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"ELASTIC_EXTERNAL": "true"},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should Reconcile with default values for intrusion detection resource", func() {
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(0 * time.Second))
+
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			Expect(ids.Spec.ComponentResources).ShouldNot(BeNil())
+			Expect(len(ids.Spec.ComponentResources)).Should(Equal(1))
+			Expect(ids.Spec.ComponentResources[0].ComponentName).Should(Equal(operatorv1.ComponentNameDeepPacketInspection))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Cpu()).Should(Equal(resource.MustParse(dpi.DefaultCPURequest)))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Cpu()).Should(Equal(resource.MustParse(dpi.DefaultCPULimit)))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Memory()).Should(Equal(resource.MustParse(dpi.DefaultMemoryRequest)))
+			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Memory()).Should(Equal(resource.MustParse(dpi.DefaultMemoryLimit)))
+		})
+
+		Context("with an external Elasticsearch CA bundle", func() {
+			var bundleRef *corev1.ConfigMapKeySelector
+
+			BeforeEach(func() {
+				Expect(c.Create(ctx, &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      render.ElasticsearchIntrusionDetectionJobUserSecret,
+						Namespace: common.OperatorNamespace(),
+					},
+				})).NotTo(HaveOccurred())
+
+				bundleRef = &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "external-es-ca"},
+					Key:                  "ca.crt",
+				}
+				ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+				Expect(test.GetResource(c, ids)).To(BeNil())
+				ids.Spec.ExternalElasticsearchCABundle = bundleRef
+				Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+			})
+
+			It("should degrade if the referenced bundle does not exist", func() {
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound, mock.AnythingOfType("string"), mock.Anything, mock.Anything)
+			})
+
+			It("should degrade if the referenced bundle is not valid PEM", func() {
+				mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+				Expect(c.Create(ctx, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: bundleRef.Name, Namespace: common.OperatorNamespace()},
+					Data:       map[string]string{bundleRef.Key: "not a certificate"},
+				})).NotTo(HaveOccurred())
+
+				_, err := r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).To(HaveOccurred())
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything)
+			})
+
+			It("should add every certificate of the bundle to the trusted bundle", func() {
+				ca1, err := tls.MakeCA("corporate-root-ca")
+				Expect(err).NotTo(HaveOccurred())
+				ca2, err := tls.MakeCA("corporate-intermediate-ca")
+				Expect(err).NotTo(HaveOccurred())
+				pem1, _, err := ca1.Config.GetPEMBytes()
+				Expect(err).NotTo(HaveOccurred())
+				pem2, _, err := ca2.Config.GetPEMBytes()
+				Expect(err).NotTo(HaveOccurred())
+				Expect(c.Create(ctx, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Name: bundleRef.Name, Namespace: common.OperatorNamespace()},
+					Data:       map[string]string{bundleRef.Key: string(pem1) + string(pem2)},
+				})).NotTo(HaveOccurred())
+
+				_, err = r.Reconcile(ctx, reconcile.Request{})
+				Expect(err).NotTo(HaveOccurred())
+
+				bundle := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: certificatemanagement.TrustedCertConfigMapName, Namespace: render.IntrusionDetectionNamespace}}
+				Expect(test.GetResource(c, bundle)).To(BeNil())
+				Expect(bundle.Data[certificatemanagement.TrustedCertConfigMapKeyName]).To(ContainSubstring(string(pem1)))
+				Expect(bundle.Data[certificatemanagement.TrustedCertConfigMapKeyName]).To(ContainSubstring(string(pem2)))
+			})
+		})
+	})
+
+	Context("Elasticsearch credential validation", func() {
+		var es *httptest.Server
+		var validatedEndpoints []string
+
+		BeforeEach(func() {
+			// A mock Elasticsearch that only accepts the current password of each user.
+			es = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				user, password, ok := req.BasicAuth()
+				if req.URL.Path != "/_security/_authenticate" || !ok || password != user+"-current" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			roots := x509.NewCertPool()
+			roots.AddCert(es.Certificate())
+			validatedEndpoints = nil
+			r.credentialValidator = func(ctx context.Context, endpoint string, _ *x509.CertPool, username, password string) error {
+				validatedEndpoints = append(validatedEndpoints, endpoint)
+				return validateElasticsearchCredentials(ctx, es.URL, roots, username, password)
+			}
+
+			for _, name := range []string{render.ElasticsearchIntrusionDetectionUserSecret, render.ElasticsearchPerformanceHotspotsUserSecret} {
+				s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: common.OperatorNamespace()}}
+				Expect(test.GetResource(c, s)).To(BeNil())
+				s.Data = map[string][]byte{"username": []byte(name), "password": []byte(name + "-current")}
+				Expect(c.Update(ctx, s)).NotTo(HaveOccurred())
+			}
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+				Data: map[string][]byte{
+					"username": []byte(render.ElasticsearchIntrusionDetectionJobUserSecret),
+					"password": []byte(render.ElasticsearchIntrusionDetectionJobUserSecret + "-current"),
+				},
+			})).NotTo(HaveOccurred())
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.ValidateElasticsearchCredentials = ptr.BoolToPtr(true)
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			es.Close()
+		})
+
+		It("should roll out credentials that Elasticsearch accepts", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			mockStatus.AssertNotCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, mock.Anything, mock.Anything, mock.Anything)
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+		})
 
-			memoryLimit := "5Gi"
-			memoryRequest := "5Gi"
-			cpuLimit := "3"
-			cpuRequest := "2"
+		It("should degrade and leave the components unchanged when Elasticsearch rejects rotated credentials", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
 
-			By("Creating IntrusionDetection resource with custom resource requirements")
-			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			annotations := d.Spec.Template.Annotations
+
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, s)).To(BeNil())
+			s.Data["password"] = []byte("rotated")
+			Expect(c.Update(ctx, s)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("rejected the credentials"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, "Elasticsearch did not accept the intrusion detection credentials", mock.Anything, mock.Anything)
+
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			Expect(d.Spec.Template.Annotations).To(Equal(annotations))
+		})
+
+		It("should validate the credentials against an external Elasticsearch directly", func() {
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.BootstrapConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"ELASTIC_EXTERNAL": "true"},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validatedEndpoints).NotTo(BeEmpty())
+			for _, endpoint := range validatedEndpoints {
+				Expect(endpoint).To(Equal(esgateway.ElasticsearchHTTPSEndpoint))
+			}
+		})
+
+		It("should only test credentials that Elasticsearch accepted again once they change or the sync period passes", func() {
+			r.syncPeriod = time.Minute
+			r.credentialChecks = newCredentialCache()
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validatedEndpoints).NotTo(BeEmpty())
+			Expect(validatedEndpoints[0]).To(Equal(relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain)))
+
+			validatedEndpoints = nil
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(validatedEndpoints).To(BeEmpty())
+
+			By("testing rotated credentials before they are rolled out")
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, s)).To(BeNil())
+			s.Data["password"] = []byte("rotated")
+			Expect(c.Update(ctx, s)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(validatedEndpoints).NotTo(BeEmpty())
+		})
+	})
+
+	Context("Elasticsearch connectivity", func() {
+		var checkErr error
+		var checkedEndpoint string
+
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", mock.Anything, mock.Anything).Return()
+			checkErr, checkedEndpoint = nil, ""
+			r.connectivityCheck = func(_ context.Context, endpoint string, _ *x509.CertPool, _, _ string) error {
+				checkedEndpoint = endpoint
+				return checkErr
+			}
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.CheckElasticsearchConnectivity = ptr.BoolToPtr(true)
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, s)).To(BeNil())
+			s.Data = map[string][]byte{"username": []byte("intrusion-detection"), "password": []byte("password")}
+			Expect(c.Update(ctx, s)).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+		})
+
+		reachable := func() *metav1.Condition {
+			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, &ids)).To(BeNil())
+			return meta.FindStatusCondition(ids.Status.Conditions, ElasticsearchReachableConditionType)
+		}
+
+		It("should report a reachable Elasticsearch", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			condition := reachable()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal(ElasticsearchReachableReason))
+			Expect(checkedEndpoint).To(Equal(relasticsearch.HTTPSEndpoint(rmeta.OSTypeLinux, r.clusterDomain)))
+		})
+
+		It("should not check Elasticsearch unless the check is enabled", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.CheckElasticsearchConnectivity = nil
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedEndpoint).To(BeEmpty())
+			Expect(reachable()).To(BeNil())
+		})
+
+		It("should check an external Elasticsearch directly", func() {
+			Expect(c.Create(ctx, &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: utils.BootstrapConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"ELASTIC_EXTERNAL": "true"},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedEndpoint).To(Equal(esgateway.ElasticsearchHTTPSEndpoint))
+			Expect(reachable().Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should check Elasticsearch at most once per sync period", func() {
+			r.syncPeriod = time.Minute
+			r.connectivityChecks = newCheckThrottle()
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reachable().Status).To(Equal(metav1.ConditionTrue))
+
+			checkErr = fmt.Errorf("failed to reach Elasticsearch: connection refused")
+			checkedEndpoint = ""
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(checkedEndpoint).To(BeEmpty())
+			Expect(reachable().Status).To(Equal(metav1.ConditionTrue))
+
+			By("checking again once the sync period has passed")
+			r.connectivityChecks.last["tigera-secure"] = time.Now().Add(-time.Minute)
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reachable().Status).To(Equal(metav1.ConditionFalse))
+		})
+
+		It("should report an unreachable Elasticsearch without failing the reconcile", func() {
+			checkErr = fmt.Errorf("failed to reach Elasticsearch: connection refused")
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			condition := reachable()
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal(ElasticsearchUnreachableReason))
+			Expect(condition.Message).To(ContainSubstring("connection refused"))
+
+			By("reporting rejected credentials apart from an unreachable Elasticsearch")
+			checkErr = fmt.Errorf("Elasticsearch rejected the credentials: %w", errInvalidCredentials)
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reachable().Reason).To(Equal(ElasticsearchAuthenticationFailedReason))
+		})
+
+		It("should not check Elasticsearch on managed clusters", func() {
+			Expect(c.Create(ctx, &operatorv1.ManagementClusterConnection{
 				ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
-				Spec: operatorv1.IntrusionDetectionSpec{
-					ComponentResources: []operatorv1.IntrusionDetectionComponentResource{
-						{
-							ComponentName: operatorv1.ComponentNameDeepPacketInspection,
-							ResourceRequirements: &corev1.ResourceRequirements{
-								Limits: corev1.ResourceList{
-									corev1.ResourceMemory: resource.MustParse(memoryLimit),
-									corev1.ResourceCPU:    resource.MustParse(cpuLimit),
-								},
-								Requests: corev1.ResourceList{
-									corev1.ResourceMemory: resource.MustParse(memoryRequest),
-									corev1.ResourceCPU:    resource.MustParse(cpuRequest),
-								},
-							},
-						},
+				Spec:       operatorv1.ManagementClusterConnectionSpec{ManagementClusterAddr: "127.0.0.1:12345"},
+			})).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reachable()).To(BeNil())
+		})
+	})
+
+	Context("Provided certificates", func() {
+		// createKeyPair creates a key pair the way cert-manager would, signed by a CA other than the one of the operator.
+		createKeyPair := func(name string, dnsNames []string) *corev1.Secret {
+			ca, err := tls.MakeCA("cert-manager")
+			Expect(err).NotTo(HaveOccurred())
+			s, err := secret.CreateTLSSecret(ca, name, common.OperatorNamespace(), corev1.TLSPrivateKeyKey, corev1.TLSCertKey, time.Hour, nil, dnsNames...)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, s)).NotTo(HaveOccurred())
+			return s
+		}
+
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.ProvidedCertificates = ptr.BoolToPtr(true)
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+		})
+
+		It("should use the provided key pairs rather than generate them", func() {
+			idsSecret := createKeyPair(render.IntrusionDetectionTLSSecretName, []string{render.IntrusionDetectionTLSSecretName})
+			dpiSecret := createKeyPair(render.DPITLSSecretName, []string{render.IntrusionDetectionTLSSecretName})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			mockStatus.AssertNotCalled(GinkgoT(), "SetDegraded", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+			for _, provided := range []*corev1.Secret{idsSecret, dpiSecret} {
+				s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: provided.Name, Namespace: common.OperatorNamespace()}}
+				Expect(test.GetResource(c, s)).To(BeNil())
+				Expect(s.Data).To(Equal(provided.Data))
+			}
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+		})
+
+		It("should degrade when a provided certificate lacks the required DNS names", func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+			createKeyPair(render.IntrusionDetectionTLSSecretName, []string{"intrusion-detection.example.com"})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("has the wrong DNS names"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+				"Error creating TLS certificate", mock.Anything, mock.Anything)
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).NotTo(BeNil())
+		})
+
+		It("should wait for a provided key pair instead of generating it", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound,
+				"Error creating TLS certificate", mock.Anything, mock.Anything)
+
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionTLSSecretName, Namespace: common.OperatorNamespace()}}
+			Expect(test.GetResource(c, s)).NotTo(BeNil())
+		})
+	})
+
+	Context("Concurrent reconciles", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return()
+			r.failures = newFailureBreaker()
+			r.missingSecrets = newAbsenceTracker()
+			r.instanceLocks = newInstanceLocks()
+
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+			for i := 0; i < 5; i++ {
+				Expect(c.Create(ctx, &v3.DeepPacketInspection{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("dpi-%d", i), Namespace: "test-dpi-ns"},
+				})).NotTo(HaveOccurred())
+			}
+		})
+
+		It("should reconcile requests for the same IntrusionDetection at the same time without errors", func() {
+			requests := []reconcile.Request{
+				{},
+				{NamespacedName: types.NamespacedName{Name: "tigera-secure"}},
+				{NamespacedName: types.NamespacedName{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: common.OperatorNamespace()}},
+			}
+			for i := 0; i < 5; i++ {
+				requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{Name: fmt.Sprintf("dpi-%d", i), Namespace: "test-dpi-ns"}})
+			}
+
+			var wg sync.WaitGroup
+			errs := make(chan error, len(requests))
+			for _, req := range requests {
+				wg.Add(1)
+				go func(req reconcile.Request) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					// The watches of the APIs become ready while reconciles are running.
+					r.tierWatchReady.MarkAsReady()
+					_, err := r.Reconcile(ctx, req)
+					errs <- err
+				}(req)
+			}
+			wg.Wait()
+			close(errs)
+			for err := range errs {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			d := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, &d)).To(BeNil())
+			ds := appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, &ds)).To(BeNil())
+		})
+	})
+
+	Context("Unrecognized ComponentResources", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.ComponentResources = []operatorv1.IntrusionDetectionComponentResource{
+				{ComponentName: "AnomalyDetectionAPI", ResourceRequirements: &corev1.ResourceRequirements{}},
+				{ComponentName: operatorv1.ComponentNameIntrusionDetectionController, ResourceRequirements: &corev1.ResourceRequirements{}},
+			}
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+		})
+
+		It("should report ComponentResources of unrecognized components until they are removed", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			condition := meta.FindStatusCondition(ids.Status.Conditions, UnrecognizedComponentResourcesConditionType)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Message).To(ContainSubstring("AnomalyDetectionAPI"))
+			Expect(render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, "AnomalyDetectionAPI")).NotTo(BeNil())
+
+			By("clearing the condition once the entry is removed")
+			ids.Spec.ComponentResources = ids.Spec.ComponentResources[1:]
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			Expect(meta.FindStatusCondition(ids.Status.Conditions, UnrecognizedComponentResourcesConditionType)).To(BeNil())
+		})
+
+		It("should prune ComponentResources of unrecognized components when asked to", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.PruneUnrecognizedComponentResources = ptr.BoolToPtr(true)
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			Expect(render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, "AnomalyDetectionAPI")).To(BeNil())
+			Expect(render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, operatorv1.ComponentNameIntrusionDetectionController)).NotTo(BeNil())
+			Expect(render.IntrusionDetectionComponentResources(ids.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection)).NotTo(BeNil())
+			Expect(meta.FindStatusCondition(ids.Status.Conditions, UnrecognizedComponentResourcesConditionType)).To(BeNil())
+		})
+	})
+
+	Context("Orphaned resources", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should prune the Deployment of a component that is no longer rendered", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			controller := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, controller)).To(BeNil())
+			Expect(controller.Labels).To(HaveKeyWithValue(ManagedByLabel, ManagedByValue))
+
+			// A Deployment that an earlier reconcile created for a component that has since been disabled, and one that
+			// a user created with the same label.
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			orphan := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name:            "intrusion-detection-disabled-component",
+				Namespace:       render.IntrusionDetectionNamespace,
+				Labels:          map[string]string{ManagedByLabel: ManagedByValue},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ids, operatorv1.GroupVersion.WithKind("IntrusionDetection"))},
+			}}
+			Expect(c.Create(ctx, orphan)).NotTo(HaveOccurred())
+			user := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+				Name:      "user-deployment",
+				Namespace: render.IntrusionDetectionNamespace,
+				Labels:    map[string]string{ManagedByLabel: ManagedByValue},
+			}}
+			Expect(c.Create(ctx, user)).NotTo(HaveOccurred())
+
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(test.GetResource(c, orphan)).NotTo(BeNil())
+			Expect(test.GetResource(c, user)).To(BeNil())
+			Expect(test.GetResource(c, controller)).To(BeNil())
+			mockStatus.AssertCalled(GinkgoT(), "RemoveDeployments", []types.NamespacedName{{Name: orphan.Name, Namespace: orphan.Namespace}})
+		})
+
+		It("should leave objects that other tools label as managed by them alone", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			other := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Name:            "intrusion-detection-helm-values",
+				Namespace:       render.IntrusionDetectionNamespace,
+				Labels:          map[string]string{"app.kubernetes.io/managed-by": "tigera-operator"},
+				OwnerReferences: []metav1.OwnerReference{*metav1.NewControllerRef(ids, operatorv1.GroupVersion.WithKind("IntrusionDetection"))},
+			}}
+			Expect(c.Create(ctx, other)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, other)).To(BeNil())
+		})
+	})
+
+	Context("Copied secrets", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should add the configured labels and annotations to the copied secrets", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.CopiedSecretMetadata = &operatorv1.Metadata{
+				Labels:      map[string]string{"secrets.example.com/rotate": "true", ManagedByLabel: "someone-else"},
+				Annotations: map[string]string{"secrets.example.com/owner": "security"},
+			}
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, s)).To(BeNil())
+			Expect(s.Labels).To(HaveKeyWithValue("secrets.example.com/rotate", "true"))
+			Expect(s.Labels).To(HaveKeyWithValue(ManagedByLabel, ManagedByValue))
+			Expect(s.Annotations).To(HaveKeyWithValue("secrets.example.com/owner", "security"))
+
+			// The labels of the operator are added to the copies, not to the spec.
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			Expect(ids.Spec.CopiedSecretMetadata.Labels).To(HaveKeyWithValue(ManagedByLabel, "someone-else"))
+		})
+
+		It("should degrade when the labels are invalid", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.CopiedSecretMetadata = &operatorv1.Metadata{Labels: map[string]string{"rotate": "not a label value"}}
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("spec.copiedSecretMetadata.labels"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+	})
+
+	Context("Pod Security Standard", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+		})
+
+		It("should degrade when syslog forwarding needs more than the configured Pod Security Standard", func() {
+			lc := &operatorv1.LogCollector{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, lc)).To(BeNil())
+			lc.Spec.AdditionalStores = &operatorv1.AdditionalLogStoreSpec{
+				Syslog: &operatorv1.SyslogStoreSpec{Endpoint: "tcp://syslog.example.com:514", LogTypes: []operatorv1.SyslogLogType{operatorv1.SyslogLogIDSEvents}},
+			}
+			Expect(c.Update(ctx, lc)).NotTo(HaveOccurred())
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			restricted := operatorv1.PodSecurityStandardRestricted
+			ids.Spec.PodSecurityStandard = &restricted
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("hostpath volume"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError,
+				"The intrusion detection pods don't meet the Pod Security Standard of their namespace", mock.Anything, mock.Anything)
+
+			By("reconciling once the namespace allows privileged pods")
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			privileged := operatorv1.PodSecurityStandardPrivileged
+			ids.Spec.PodSecurityStandard = &privileged
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, ns)).To(BeNil())
+			Expect(ns.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "privileged"))
+		})
+	})
+
+	Context("Resource quotas", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.ComponentResources = []operatorv1.IntrusionDetectionComponentResource{{
+				ComponentName: operatorv1.ComponentNameIntrusionDetectionController,
+				ResourceRequirements: &corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("256Mi")},
+				},
+			}}
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+		})
+
+		newQuota := func(memory string) *corev1.ResourceQuota {
+			return &corev1.ResourceQuota{
+				ObjectMeta: metav1.ObjectMeta{Name: "ids-quota", Namespace: render.IntrusionDetectionNamespace},
+				Spec: corev1.ResourceQuotaSpec{
+					Hard: corev1.ResourceList{
+						corev1.ResourcePods:           resource.MustParse("10"),
+						corev1.ResourceRequestsMemory: resource.MustParse(memory),
 					},
 				},
-			})).
-				NotTo(HaveOccurred())
+			}
+		}
+
+		It("should degrade before applying the workloads when they exceed a ResourceQuota", func() {
+			Expect(c.Create(ctx, newQuota("128Mi"))).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("requests.memory 256Mi needed, 128Mi allowed"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+				"The intrusion detection workloads exceed the ResourceQuota tigera-intrusion-detection/ids-quota", mock.Anything, mock.Anything)
+
+			controller := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, controller)).NotTo(BeNil())
+		})
+
+		// newLimitRange returns a LimitRange that defaults the memory request of the containers that don't set one.
+		newLimitRange := func(memory string) *corev1.LimitRange {
+			return &corev1.LimitRange{
+				ObjectMeta: metav1.ObjectMeta{Name: "ids-defaults", Namespace: render.IntrusionDetectionNamespace},
+				Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+					Type:           corev1.LimitTypeContainer,
+					DefaultRequest: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse(memory)},
+				}}},
+			}
+		}
+
+		It("should degrade when the workloads don't set a resource that the ResourceQuota requires", func() {
+			Expect(c.Create(ctx, newQuota("1Gi"))).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ResourceQuota tigera-intrusion-detection/ids-quota requires resources that are not set"))
+			Expect(err.Error()).To(ContainSubstring("requests.memory of container elasticsearch-job-installer of intrusion-detection-es-job-installer"))
+			Expect(err.Error()).NotTo(ContainSubstring("of container controller of"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+				"The intrusion detection workloads don't set the resources that the ResourceQuota tigera-intrusion-detection/ids-quota requires", mock.Anything, mock.Anything)
+		})
+
+		It("should count the requests that a LimitRange defaults the containers to", func() {
+			Expect(c.Create(ctx, newQuota("1Gi"))).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, newLimitRange("1Gi"))).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("ResourceQuota tigera-intrusion-detection/ids-quota is exceeded: requests.memory"))
+		})
+
+		It("should apply the workloads when they fit in the ResourceQuota", func() {
+			Expect(c.Create(ctx, newQuota("1Gi"))).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, newLimitRange("16Mi"))).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			controller := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, controller)).To(BeNil())
+		})
+	})
+
+	Context("Findings webhook", func() {
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
+		})
+
+		setWebhook := func(webhook *operatorv1.IntrusionDetectionFindingsWebhook) {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.FindingsWebhook = webhook
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+		}
+		authSecret := &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "siem-auth"}, Key: "header"}
 
-			result, err := r.Reconcile(ctx, reconcile.Request{})
+		It("should degrade when the webhook URL is not an http or https URL", func() {
+			setWebhook(&operatorv1.IntrusionDetectionFindingsWebhook{URL: "siem.example.com/ingest"})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("must be an http or https URL with a host"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.InvalidConfigurationError, "IntrusionDetection is invalid", mock.Anything, mock.Anything)
+		})
+
+		It("should degrade until the auth Secret exists and then copy it", func() {
+			setWebhook(&operatorv1.IntrusionDetectionFindingsWebhook{URL: "https://siem.example.com/ingest", AuthSecret: authSecret})
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound,
+				"The findings webhook auth Secret tigera-operator/siem-auth was not found", mock.Anything, mock.Anything)
+
+			By("degrading while the Secret doesn't have the key")
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "siem-auth", Namespace: common.OperatorNamespace()},
+				Data:       map[string][]byte{"token": []byte("Bearer token")},
+			}
+			Expect(c.Create(ctx, secret)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+				"The findings webhook auth Secret siem-auth has no key \"header\"", mock.Anything, mock.Anything)
+
+			By("copying the Secret once it has the key")
+			secret.Data = map[string][]byte{"header": []byte("Bearer token")}
+			Expect(c.Update(ctx, secret)).NotTo(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.RequeueAfter).To(Equal(0 * time.Second))
 
-			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
-			Expect(test.GetResource(c, &ids)).To(BeNil())
-			Expect(ids.Spec.ComponentResources).ShouldNot(BeNil())
-			Expect(len(ids.Spec.ComponentResources)).Should(Equal(1))
-			Expect(ids.Spec.ComponentResources[0].ComponentName).Should(Equal(operatorv1.ComponentNameDeepPacketInspection))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Cpu()).Should(Equal(resource.MustParse(cpuRequest)))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Cpu()).Should(Equal(resource.MustParse(cpuLimit)))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Memory()).Should(Equal(resource.MustParse(memoryRequest)))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Memory()).Should(Equal(resource.MustParse(memoryLimit)))
+			copied := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "siem-auth", Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, copied)).To(BeNil())
+			Expect(copied.Data).To(HaveKeyWithValue("header", []byte("Bearer token")))
 		})
 	})
 
-	Context("Reconcile for Condition status", func() {
-		generation := int64(2)
+	Context("ECK license", func() {
+		var requirements []eckLicenseRequirement
 
-		It("should reconcile with creating new status condition with one item", func() {
-			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
-			ts := &operatorv1.TigeraStatus{
-				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
-				Spec:       operatorv1.TigeraStatusSpec{},
-				Status: operatorv1.TigeraStatusStatus{
-					Conditions: []operatorv1.TigeraStatusCondition{
-						{
-							Type:               operatorv1.ComponentAvailable,
-							Status:             operatorv1.ConditionTrue,
-							Reason:             string(operatorv1.AllObjectsAvailable),
-							Message:            "All Objects are available",
-							ObservedGeneration: generation,
-						},
-					},
-				},
-			}
-			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+		BeforeEach(func() {
+			mockStatus.On("SetDegraded", operatorv1.ResourceValidationError, mock.AnythingOfType("string"), mock.Anything, mock.Anything).Return()
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
 
-			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
-				Name:      "intrusion-detection",
-				Namespace: "",
-			}})
-			Expect(err).ShouldNot(HaveOccurred())
-			instance := &operatorv1.IntrusionDetection{}
-			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
-			Expect(err).ShouldNot(HaveOccurred())
+			// None of the features need more than a basic license yet, so check against one that does.
+			requirements = eckLicenseRequirements
+			eckLicenseRequirements = []eckLicenseRequirement{{
+				field:   "spec.findingsWebhook",
+				license: render.ElasticsearchLicenseTypeEnterprise,
+				enabled: func(ids *operatorv1.IntrusionDetection) bool { return ids.Spec.FindingsWebhook != nil },
+			}}
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.FindingsWebhook = &operatorv1.IntrusionDetectionFindingsWebhook{URL: "https://siem.example.com/findings"}
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+		})
 
-			Expect(instance.Status.Conditions).To(HaveLen(1))
-			Expect(instance.Status.Conditions[0].Type).To(Equal("Ready"))
-			Expect(string(instance.Status.Conditions[0].Status)).To(Equal(string(operatorv1.ConditionTrue)))
-			Expect(instance.Status.Conditions[0].Reason).To(Equal(string(operatorv1.AllObjectsAvailable)))
-			Expect(instance.Status.Conditions[0].Message).To(Equal("All Objects are available"))
-			Expect(instance.Status.Conditions[0].ObservedGeneration).To(Equal(generation))
+		AfterEach(func() {
+			eckLicenseRequirements = requirements
 		})
 
-		It("should reconcile with empty tigerastatus conditions ", func() {
-			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
-			ts := &operatorv1.TigeraStatus{
-				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
-				Spec:       operatorv1.TigeraStatusSpec{},
-				Status:     operatorv1.TigeraStatusStatus{},
-			}
-			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+		setLicense := func(license render.ElasticsearchLicenseType) {
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: render.ECKLicenseConfigMapName, Namespace: render.ECKOperatorNamespace}}
+			Expect(test.GetResource(c, cm)).To(BeNil())
+			cm.Data = map[string]string{"eck_license_level": string(license)}
+			Expect(c.Update(ctx, cm)).NotTo(HaveOccurred())
+		}
 
-			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
-				Name:      "intrusion-detection",
-				Namespace: "",
-			}})
-			Expect(err).ShouldNot(HaveOccurred())
-			instance := &operatorv1.IntrusionDetection{}
-			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
-			Expect(err).ShouldNot(HaveOccurred())
+		It("should degrade when a basic license doesn't support the configured features", func() {
+			setLicense(render.ElasticsearchLicenseTypeBasic)
 
-			Expect(instance.Status.Conditions).To(HaveLen(0))
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("the ECK license level is basic, but spec.findingsWebhook needs an enterprise license; " +
+				"upgrade the ECK license or disable these features"))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError,
+				"The Elasticsearch license doesn't support the configured intrusion detection features", mock.Anything, mock.Anything)
+
+			controller := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, controller)).NotTo(BeNil())
 		})
 
-		It("should reconcile with creating new status condition  with multiple conditions as true", func() {
-			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
-			ts := &operatorv1.TigeraStatus{
-				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
-				Spec:       operatorv1.TigeraStatusSpec{},
-				Status: operatorv1.TigeraStatusStatus{
-					Conditions: []operatorv1.TigeraStatusCondition{
-						{
-							Type:               operatorv1.ComponentAvailable,
-							Status:             operatorv1.ConditionTrue,
-							Reason:             string(operatorv1.AllObjectsAvailable),
-							Message:            "All Objects are available",
-							ObservedGeneration: generation,
-						},
-						{
-							Type:               operatorv1.ComponentProgressing,
-							Status:             operatorv1.ConditionTrue,
-							Reason:             string(operatorv1.ResourceNotReady),
-							Message:            "Progressing Installation.operatorv1.tigera.io",
-							ObservedGeneration: generation,
-						},
-						{
-							Type:               operatorv1.ComponentDegraded,
-							Status:             operatorv1.ConditionTrue,
-							Reason:             string(operatorv1.ResourceUpdateError),
-							Message:            "Error resolving ImageSet for components",
-							ObservedGeneration: generation,
-						},
-					},
-				},
+		It("should tolerate the configured features with a trial license", func() {
+			setLicense(render.ElasticsearchLicenseTypeEnterpriseTrial)
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should not need more than a basic license for the features it configures", func() {
+			eckLicenseRequirements = nil
+			setLicense(render.ElasticsearchLicenseTypeBasic)
+
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.IndexLifecycle = &operatorv1.IntrusionDetectionIndexLifecycle{
+				Cold: &operatorv1.IndexLifecyclePhase{MinAge: "30d"},
 			}
-			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
 
-			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
-				Name:      "intrusion-detection",
-				Namespace: "",
-			}})
-			Expect(err).ShouldNot(HaveOccurred())
-			instance := &operatorv1.IntrusionDetection{}
-			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
-			Expect(err).ShouldNot(HaveOccurred())
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		})
 
-			Expect(instance.Status.Conditions).To(HaveLen(3))
-			Expect(instance.Status.Conditions[0].Type).To(Equal("Ready"))
-			Expect(string(instance.Status.Conditions[0].Status)).To(Equal(string(operatorv1.ConditionTrue)))
-			Expect(instance.Status.Conditions[0].Reason).To(Equal(string(operatorv1.AllObjectsAvailable)))
-			Expect(instance.Status.Conditions[0].Message).To(Equal("All Objects are available"))
-			Expect(instance.Status.Conditions[0].ObservedGeneration).To(Equal(generation))
+		It("should tolerate the configured features with a license level it doesn't recognize", func() {
+			setLicense("platinum")
 
-			Expect(instance.Status.Conditions[1].Type).To(Equal("Progressing"))
-			Expect(string(instance.Status.Conditions[1].Status)).To(Equal(string(operatorv1.ConditionTrue)))
-			Expect(instance.Status.Conditions[1].Reason).To(Equal(string(operatorv1.ResourceNotReady)))
-			Expect(instance.Status.Conditions[1].Message).To(Equal("Progressing Installation.operatorv1.tigera.io"))
-			Expect(instance.Status.Conditions[1].ObservedGeneration).To(Equal(generation))
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
 
-			Expect(instance.Status.Conditions[2].Type).To(Equal("Degraded"))
-			Expect(string(instance.Status.Conditions[2].Status)).To(Equal(string(operatorv1.ConditionTrue)))
-			Expect(instance.Status.Conditions[2].Reason).To(Equal(string(operatorv1.ResourceUpdateError)))
-			Expect(instance.Status.Conditions[2].Message).To(Equal("Error resolving ImageSet for components"))
-			Expect(instance.Status.Conditions[2].ObservedGeneration).To(Equal(generation))
+	Context("Replica skew", func() {
+		BeforeEach(func() {
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
+			})).NotTo(HaveOccurred())
 		})
 
-		It("should reconcile with creating new status condition and toggle Available to true & others to false", func() {
-			mockStatus.On("SetDegraded", operatorv1.ResourceNotFound, "Elasticsearch secrets are not available yet, waiting until they become available - Error: secrets \"tigera-ee-installer-elasticsearch-access\" not found").Return().Maybe()
-			ts := &operatorv1.TigeraStatus{
-				ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection"},
-				Spec:       operatorv1.TigeraStatusSpec{},
-				Status: operatorv1.TigeraStatusStatus{
-					Conditions: []operatorv1.TigeraStatusCondition{
-						{
-							Type:               operatorv1.ComponentAvailable,
-							Status:             operatorv1.ConditionTrue,
-							Reason:             string(operatorv1.AllObjectsAvailable),
-							Message:            "All Objects are available",
-							ObservedGeneration: generation,
-						},
-						{
-							Type:               operatorv1.ComponentProgressing,
-							Status:             operatorv1.ConditionFalse,
-							Reason:             string(operatorv1.NotApplicable),
-							Message:            "Not Applicable",
-							ObservedGeneration: generation,
-						},
-						{
-							Type:               operatorv1.ComponentDegraded,
-							Status:             operatorv1.ConditionFalse,
-							Reason:             string(operatorv1.NotApplicable),
-							Message:            "Not Applicable",
-							ObservedGeneration: generation,
-						},
-					},
+		It("should report the replicas a Deployment is short of and why its pods can't be scheduled", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+
+			deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, deploy)).To(BeNil())
+			deploy.Status = appsv1.DeploymentStatus{ObservedGeneration: deploy.Generation, Replicas: 1, UnavailableReplicas: 1}
+			Expect(c.Status().Update(ctx, deploy)).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "intrusion-detection-controller-abc",
+					Namespace: render.IntrusionDetectionNamespace,
+					Labels:    deploy.Spec.Selector.MatchLabels,
 				},
-			}
-			Expect(c.Create(ctx, ts)).NotTo(HaveOccurred())
+				Status: corev1.PodStatus{
+					Phase: corev1.PodPending,
+					Conditions: []corev1.PodCondition{{
+						Type:    corev1.PodScheduled,
+						Status:  corev1.ConditionFalse,
+						Reason:  corev1.PodReasonUnschedulable,
+						Message: "0/3 nodes are available: 3 Insufficient memory.",
+					}},
+				},
+			})).NotTo(HaveOccurred())
 
-			_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{
-				Name:      "intrusion-detection",
-				Namespace: "",
-			}})
-			Expect(err).ShouldNot(HaveOccurred())
-			instance := &operatorv1.IntrusionDetection{}
-			err = r.client.Get(ctx, utils.DefaultTSEEInstanceKey, instance)
-			Expect(err).ShouldNot(HaveOccurred())
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
 
-			Expect(instance.Status.Conditions).To(HaveLen(3))
-			Expect(instance.Status.Conditions[0].Type).To(Equal("Ready"))
-			Expect(string(instance.Status.Conditions[0].Status)).To(Equal(string(operatorv1.ConditionTrue)))
-			Expect(instance.Status.Conditions[0].Reason).To(Equal(string(operatorv1.AllObjectsAvailable)))
-			Expect(instance.Status.Conditions[0].Message).To(Equal("All Objects are available"))
-			Expect(instance.Status.Conditions[0].ObservedGeneration).To(Equal(generation))
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			cond := meta.FindStatusCondition(ids.Status.Conditions, ReplicasReadyConditionType)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+			Expect(cond.Reason).To(Equal(ReplicasUnavailableReason))
+			Expect(cond.Message).To(Equal("Deployment tigera-intrusion-detection/intrusion-detection-controller has 0/1 replicas ready, " +
+				"pod intrusion-detection-controller-abc can't be scheduled: Unschedulable: 0/3 nodes are available: 3 Insufficient memory."))
+
+			By("reporting the replicas as ready once the Deployment catches up")
+			Expect(test.GetResource(c, deploy)).To(BeNil())
+			deploy.Status = appsv1.DeploymentStatus{ObservedGeneration: deploy.Generation, Replicas: 1, ReadyReplicas: 1, AvailableReplicas: 1}
+			Expect(c.Status().Update(ctx, deploy)).NotTo(HaveOccurred())
+
+			summary := &reconcileSummary{rendered: &renderedObjects{workloads: []client.Object{
+				&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}},
+			}}}
+			r.setSummary(ctx, ids, summary, nil, log)
+
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			cond = meta.FindStatusCondition(ids.Status.Conditions, ReplicasReadyConditionType)
+			Expect(cond).NotTo(BeNil())
+			Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+			Expect(cond.Reason).To(Equal(AllReplicasReadyReason))
+		})
+	})
 
-			Expect(instance.Status.Conditions[1].Type).To(Equal("Progressing"))
-			Expect(string(instance.Status.Conditions[1].Status)).To(Equal(string(operatorv1.ConditionFalse)))
-			Expect(instance.Status.Conditions[1].Reason).To(Equal(string(operatorv1.NotApplicable)))
-			Expect(instance.Status.Conditions[1].Message).To(Equal("Not Applicable"))
-			Expect(instance.Status.Conditions[1].ObservedGeneration).To(Equal(generation))
+	Context("Workload watches", func() {
+		It("should map the Deployments and pods of the components to the IntrusionDetection they belong to", func() {
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})).NotTo(HaveOccurred())
+			mapFunc := instanceRequestsForNamespace(c)
+
+			Expect(mapFunc(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}})).
+				To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}}))
+			Expect(mapFunc(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller-abc", Namespace: render.IntrusionDetectionInstanceNamespace("tenant-a")}})).
+				To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tenant-a"}}))
+			Expect(mapFunc(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "intrusion-detection-controller-abc", Namespace: render.IntrusionDetectionInstanceNamespace("tenant-b")}})).
+				To(BeEmpty())
+		})
+	})
 
-			Expect(instance.Status.Conditions[2].Type).To(Equal("Degraded"))
-			Expect(string(instance.Status.Conditions[2].Status)).To(Equal(string(operatorv1.ConditionFalse)))
-			Expect(instance.Status.Conditions[2].Reason).To(Equal(string(operatorv1.NotApplicable)))
-			Expect(instance.Status.Conditions[2].Message).To(Equal("Not Applicable"))
-			Expect(instance.Status.Conditions[2].ObservedGeneration).To(Equal(generation))
+	Context("Component watches", func() {
+		It("should match the objects of the components in the namespace of any IntrusionDetection", func() {
+			pred := inInstanceNamespaceNamed(render.TigeraLinseedSecret, render.IntrusionDetectionControllerPolicyName)
+
+			Expect(pred.Create(event.CreateEvent{Object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraLinseedSecret, Namespace: render.IntrusionDetectionNamespace}}})).To(BeTrue())
+			Expect(pred.Create(event.CreateEvent{Object: &v3.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionControllerPolicyName, Namespace: render.IntrusionDetectionInstanceNamespace("tenant-a")}}})).To(BeTrue())
+			Expect(pred.Create(event.CreateEvent{Object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: render.TigeraLinseedSecret, Namespace: "tigera-elasticsearch"}}})).To(BeFalse())
+			Expect(pred.Create(event.CreateEvent{Object: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: render.IntrusionDetectionInstanceNamespace("tenant-a")}}})).To(BeFalse())
 		})
 	})
 
-	Context("External ES mode", func() {
+	Context("Namespace watch", func() {
+		It("should only map a namespace label change to the IntrusionDetections whose DPI namespace selection it changes", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.DPINamespaceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"dpi": "enabled"}}
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+			Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})).NotTo(HaveOccurred())
+			mapFunc := dpiNamespaceSelectionRequests(c)
+
+			unselected := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-ns", Labels: map[string]string{"team": "a"}}}
+			selected := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-ns", Labels: map[string]string{"team": "a", "dpi": "enabled"}}}
+			relabeled := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "tenant-ns", Labels: map[string]string{"team": "b", "dpi": "enabled"}}}
+
+			Expect(mapFunc(unselected, selected)).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}}))
+			Expect(mapFunc(selected, unselected)).To(ConsistOf(reconcile.Request{NamespacedName: types.NamespacedName{Name: "tigera-secure"}}))
+			Expect(mapFunc(selected, relabeled)).To(BeEmpty())
+		})
+	})
+
+	Context("Summary", func() {
 		BeforeEach(func() {
-			// Delete the Elasticsearch CR. This is created for ECK only.
-			Expect(c.Delete(ctx, &esv1.Elasticsearch{
-				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchName, Namespace: render.ElasticsearchNamespace},
+			Expect(c.Create(ctx, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionJobUserSecret, Namespace: common.OperatorNamespace()},
 			})).NotTo(HaveOccurred())
+		})
+
+		It("should summarize the components that are not ready", func() {
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
 
-			// Update the reconciler to run in external ES mode for these tests.
-			r.elasticExternal = true
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			Expect(ids.Status.Summary).To(Equal("1/2 components ready (not ready: intrusion-detection-controller), license OK"))
+
+			By("not writing the status again when the summary is unchanged")
+			resourceVersion := ids.ResourceVersion
+			_, err = r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			Expect(ids.ResourceVersion).To(Equal(resourceVersion))
+
+			By("reporting a component that becomes degraded")
+			deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}}
+			Expect(test.GetResource(c, deploy)).To(BeNil())
+			deploy.Status = appsv1.DeploymentStatus{ObservedGeneration: deploy.Generation, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+			Expect(c.Status().Update(ctx, deploy)).NotTo(HaveOccurred())
+			ds := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}}
+			Expect(test.GetResource(c, ds)).To(BeNil())
+			ds.Status = appsv1.DaemonSetStatus{ObservedGeneration: ds.Generation, DesiredNumberScheduled: 2, UpdatedNumberScheduled: 2, NumberAvailable: 1}
+			Expect(c.Status().Update(ctx, ds)).NotTo(HaveOccurred())
+
+			summary := &reconcileSummary{
+				rendered: &renderedObjects{workloads: []client.Object{
+					&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: render.IntrusionDetectionName, Namespace: render.IntrusionDetectionNamespace}},
+					&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: dpi.DeepPacketInspectionName, Namespace: dpi.DeepPacketInspectionNamespace}},
+				}},
+				license: summaryLicenseOK,
+			}
+			current, err := r.currentWorkloads(ctx, summary.rendered)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(summarize(ids, summary, current, nil)).To(Equal("1/2 components ready (not ready: tigera-dpi), license OK"))
 		})
 
-		It("should Reconcile with default values for intrusion detection resource", func() {
+		It("should summarize a reconcile that fails before the components are rendered", func() {
+			ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			ids.Spec.ElasticsearchQueryTimeout = "90"
+			Expect(c.Update(ctx, ids)).NotTo(HaveOccurred())
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+
+			Expect(test.GetResource(c, ids)).To(BeNil())
+			Expect(ids.Status.Summary).To(Equal("reconcile failed, components not rendered"))
+		})
+	})
+
+	Context("Missing CRDs", func() {
+		It("should degrade naming the CRD that is not installed", func() {
+			// A scheme without the DeepPacketInspection kind behaves like a cluster without its CRD.
+			crdScheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(crdScheme)).NotTo(HaveOccurred())
+			Expect(operatorv1.SchemeBuilder.AddToScheme(crdScheme)).NotTo(HaveOccurred())
+			crdScheme.AddKnownTypes(v3.SchemeGroupVersion, &v3.LicenseKey{}, &v3.LicenseKeyList{})
+			metav1.AddToGroupVersion(crdScheme, v3.SchemeGroupVersion)
+			crdClient := fake.NewClientBuilder().WithScheme(crdScheme).Build()
+			Expect(crdClient.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}})).NotTo(HaveOccurred())
+			r.client = crdClient
+			r.scheme = crdScheme
+
+			_, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).To(HaveOccurred())
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceNotFound, "DeepPacketInspection CRD not found; install the Calico Enterprise CRDs", mock.Anything, mock.Anything)
+		})
+	})
+
+	Context("API server throttling", func() {
+		It("should requeue after the delay that the API server hints at instead of returning the error", func() {
+			r.client = &throttlingClient{Client: c, retryAfterSeconds: 7}
+
 			result, err := r.Reconcile(ctx, reconcile.Request{})
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.RequeueAfter).To(Equal(0 * time.Second))
+			Expect(result.RequeueAfter).To(Equal(7 * time.Second))
+			mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceReadError, "Error querying installation", mock.Anything, mock.Anything)
+		})
 
-			ids := operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
-			Expect(test.GetResource(c, &ids)).To(BeNil())
-			Expect(ids.Spec.ComponentResources).ShouldNot(BeNil())
-			Expect(len(ids.Spec.ComponentResources)).Should(Equal(1))
-			Expect(ids.Spec.ComponentResources[0].ComponentName).Should(Equal(operatorv1.ComponentNameDeepPacketInspection))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Cpu()).Should(Equal(resource.MustParse(dpi.DefaultCPURequest)))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Cpu()).Should(Equal(resource.MustParse(dpi.DefaultCPULimit)))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Requests.Memory()).Should(Equal(resource.MustParse(dpi.DefaultMemoryRequest)))
-			Expect(*ids.Spec.ComponentResources[0].ResourceRequirements.Limits.Memory()).Should(Equal(resource.MustParse(dpi.DefaultMemoryLimit)))
+		It("should requeue after a default delay when the API server doesn't hint at one", func() {
+			r.client = &throttlingClient{Client: c}
+
+			result, err := r.Reconcile(ctx, reconcile.Request{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(Equal(defaultThrottleDelay))
 		})
 	})
 })
+
+// throttlingClient is a client that fails to get the Installation like an API server that is overloaded.
+type throttlingClient struct {
+	client.Client
+	retryAfterSeconds int
+}
+
+func (c *throttlingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if _, ok := obj.(*operatorv1.Installation); ok {
+		return errors.NewTooManyRequests("the server is overloaded", c.retryAfterSeconds)
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
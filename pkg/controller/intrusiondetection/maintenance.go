@@ -0,0 +1,56 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// MaintenanceConditionType is the type of the IntrusionDetection status condition that tells whether an operator-wide
+// maintenance window is keeping changes from being applied, along with the reasons it is set with.
+const (
+	MaintenanceConditionType = "Maintenance"
+
+	MaintenanceActiveReason   = "MaintenanceActive"
+	MaintenanceInactiveReason = "MaintenanceInactive"
+)
+
+// setMaintenance records in the Maintenance condition of the IntrusionDetection whether a maintenance window is active.
+// The condition is only added once a maintenance window starts, and is then kept up to date.
+func (r *ReconcileIntrusionDetection) setMaintenance(ctx context.Context, instance *operatorv1.IntrusionDetection, active bool) error {
+	current := meta.FindStatusCondition(instance.Status.Conditions, MaintenanceConditionType)
+	if current == nil && !active {
+		return nil
+	}
+	condition := metav1.Condition{
+		Type:               MaintenanceConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             MaintenanceActiveReason,
+		Message:            "Operator maintenance is active, changes are not being applied",
+		ObservedGeneration: instance.Generation,
+	}
+	if !active {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = MaintenanceInactiveReason
+		condition.Message = "Operator maintenance is not active"
+	}
+
+	return r.setCondition(ctx, instance, condition)
+}
@@ -0,0 +1,162 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/status"
+	"github.com/tigera/operator/pkg/render"
+)
+
+// ManagedByLabel is set, with the value ManagedByValue, on the objects that the operator renders into the namespace of
+// the intrusion detection components, so that the ones that are no longer rendered can be found and pruned. The key is
+// the operator's own, so that objects that other tools label as theirs with app.kubernetes.io/managed-by are not
+// mistaken for the operator's.
+const (
+	ManagedByLabel = "operator.tigera.io/managed-by"
+	ManagedByValue = "intrusion-detection"
+)
+
+// prunableLists are the kinds of objects that are pruned from the namespace of the intrusion detection components once
+// they are no longer rendered. Secrets are left alone, since losing a certificate or a credential is harder to recover
+// from than a leftover one.
+func prunableLists() []client.ObjectList {
+	return []client.ObjectList{
+		&appsv1.DeploymentList{},
+		&appsv1.DaemonSetList{},
+		&appsv1.StatefulSetList{},
+		&batchv1.CronJobList{},
+		&batchv1.JobList{},
+		&corev1.ServiceList{},
+		&corev1.ServiceAccountList{},
+		&corev1.ConfigMapList{},
+	}
+}
+
+// renderedObjects records the objects that the components of a reconcile render into a namespace, along with the
+// workloads they render into any namespace.
+type renderedObjects struct {
+	namespace string
+	scheme    *runtime.Scheme
+	keys      map[string]bool
+	workloads []client.Object
+}
+
+func newRenderedObjects(namespace string, scheme *runtime.Scheme) *renderedObjects {
+	return &renderedObjects{namespace: namespace, scheme: scheme, keys: map[string]bool{}}
+}
+
+// objectKey identifies an object by its GroupVersionKind, namespace and name. Rendered objects don't always have their
+// TypeMeta set, so the GroupVersionKind is looked up in the scheme.
+func (o *renderedObjects) objectKey(obj client.Object) string {
+	gvk, err := apiutil.GVKForObject(obj, o.scheme)
+	if err != nil {
+		gvk = obj.GetObjectKind().GroupVersionKind()
+	}
+	return fmt.Sprintf("%s %s/%s", gvk, obj.GetNamespace(), obj.GetName())
+}
+
+// track returns a component that renders the same objects as the given one, labeling those in the namespace as
+// managed by the operator and recording them. The objects are only rendered once, so that the checks that run on them
+// before they are applied see the objects that are applied.
+func (o *renderedObjects) track(comp render.Component) render.Component {
+	return &trackedComponent{Component: comp, rendered: o}
+}
+
+type trackedComponent struct {
+	render.Component
+	rendered *renderedObjects
+
+	objectsRendered    bool
+	toCreate, toDelete []client.Object
+}
+
+func (c *trackedComponent) Objects() ([]client.Object, []client.Object) {
+	if c.objectsRendered {
+		return c.toCreate, c.toDelete
+	}
+	c.toCreate, c.toDelete = c.Component.Objects()
+	c.objectsRendered = true
+	for _, obj := range c.toCreate {
+		switch obj.(type) {
+		case *appsv1.Deployment, *appsv1.DaemonSet, *appsv1.StatefulSet:
+			c.rendered.workloads = append(c.rendered.workloads, obj)
+		}
+		if obj.GetNamespace() != c.rendered.namespace {
+			continue
+		}
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		labels[ManagedByLabel] = ManagedByValue
+		obj.SetLabels(labels)
+		c.rendered.keys[c.rendered.objectKey(obj)] = true
+	}
+	return c.toCreate, c.toDelete
+}
+
+// pruneOrphans deletes the objects in the namespace that are labeled as managed by the operator but were not rendered,
+// such as the objects of a component that has been disabled, and stops reporting the status of the workloads among
+// them. Only objects that the IntrusionDetection controls are deleted, so that objects that a user labeled themselves
+// are left alone.
+func (r *ReconcileIntrusionDetection) pruneOrphans(ctx context.Context, instance *operatorv1.IntrusionDetection, rendered *renderedObjects, statusManager status.StatusManager) ([]string, error) {
+	var pruned []string
+	for _, list := range prunableLists() {
+		if err := r.client.List(ctx, list, client.InNamespace(rendered.namespace), client.MatchingLabels{ManagedByLabel: ManagedByValue}); err != nil {
+			return pruned, err
+		}
+		items, err := meta.ExtractList(list)
+		if err != nil {
+			return pruned, err
+		}
+		for _, item := range items {
+			obj, ok := item.(client.Object)
+			if !ok || rendered.keys[rendered.objectKey(obj)] || !metav1.IsControlledBy(obj, instance) {
+				continue
+			}
+			if err := r.client.Delete(ctx, obj, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				return pruned, err
+			}
+			key := types.NamespacedName{Name: obj.GetName(), Namespace: obj.GetNamespace()}
+			switch obj.(type) {
+			case *appsv1.Deployment:
+				statusManager.RemoveDeployments(key)
+			case *appsv1.DaemonSet:
+				statusManager.RemoveDaemonsets(key)
+			case *appsv1.StatefulSet:
+				statusManager.RemoveStatefulSets(key)
+			case *batchv1.CronJob:
+				statusManager.RemoveCronJobs(key)
+			}
+			pruned = append(pruned, rendered.objectKey(obj))
+		}
+	}
+	return pruned, nil
+}
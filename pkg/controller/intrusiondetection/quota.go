@@ -0,0 +1,252 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// quotaExceededError is returned by checkResourceQuotas for a ResourceQuota that the rendered workloads don't fit in,
+// or that requires resources to be set that some of their containers don't set.
+type quotaExceededError struct {
+	quota    *corev1.ResourceQuota
+	exceeded []string
+	unset    []string
+}
+
+func (e *quotaExceededError) Error() string {
+	if len(e.exceeded) == 0 {
+		return fmt.Sprintf("ResourceQuota %s/%s requires resources that are not set: %s", e.quota.Namespace, e.quota.Name, strings.Join(e.unset, ", "))
+	}
+	return fmt.Sprintf("ResourceQuota %s/%s is exceeded: %s", e.quota.Namespace, e.quota.Name, strings.Join(e.exceeded, ", "))
+}
+
+// quotaRequiredResources maps the resources of a ResourceQuota that make every container of a pod set a request or a
+// limit to whether it is a limit, and the resource that must be set.
+var quotaRequiredResources = map[corev1.ResourceName]struct {
+	limit    bool
+	resource corev1.ResourceName
+}{
+	corev1.ResourceCPU:            {false, corev1.ResourceCPU},
+	corev1.ResourceMemory:         {false, corev1.ResourceMemory},
+	corev1.ResourceRequestsCPU:    {false, corev1.ResourceCPU},
+	corev1.ResourceRequestsMemory: {false, corev1.ResourceMemory},
+	corev1.ResourceLimitsCPU:      {true, corev1.ResourceCPU},
+	corev1.ResourceLimitsMemory:   {true, corev1.ResourceMemory},
+}
+
+// checkResourceQuotas returns a quotaExceededError if the pods of the given workloads that are rendered into the
+// namespace would need more than one of the ResourceQuotas in the namespace allows, or don't set a request or limit
+// that the quota requires every container to set, so that the reconcile reports the quota instead of failing to
+// create the pods. The rendered workloads are compared with the hard limits of the quota on their own, since the pods
+// they replace are counted in its usage. The containers get the requests and limits that the LimitRanges in the
+// namespace default them to first. Quotas with scopes are skipped, since they only apply to some of the pods.
+func (r *ReconcileIntrusionDetection) checkResourceQuotas(ctx context.Context, namespace string, objs []client.Object) error {
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.client.List(ctx, quotas, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	if len(quotas.Items) == 0 {
+		return nil
+	}
+	limitRanges := &corev1.LimitRangeList{}
+	if err := r.client.List(ctx, limitRanges, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+
+	needed := corev1.ResourceList{}
+	var workloads []namedPodSpec
+	for _, obj := range objs {
+		if obj.GetNamespace() != namespace {
+			continue
+		}
+		if spec, replicas := workloadPodSpec(obj); spec != nil {
+			spec = defaultedPodSpec(spec, limitRanges.Items)
+			addResources(needed, podQuotaUsage(spec), replicas)
+			workloads = append(workloads, namedPodSpec{name: obj.GetName(), spec: spec})
+		}
+	}
+
+	for i := range quotas.Items {
+		quota := &quotas.Items[i]
+		if len(quota.Spec.Scopes) > 0 || quota.Spec.ScopeSelector != nil {
+			continue
+		}
+		var exceeded, unset []string
+		for name, hard := range quota.Spec.Hard {
+			if used, ok := needed[name]; ok && used.Cmp(hard) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("%s %s needed, %s allowed", name, used.String(), hard.String()))
+			}
+			required, ok := quotaRequiredResources[name]
+			if !ok {
+				continue
+			}
+			for _, w := range workloads {
+				for _, c := range append(append([]corev1.Container{}, w.spec.InitContainers...), w.spec.Containers...) {
+					list := c.Resources.Requests
+					if required.limit {
+						list = c.Resources.Limits
+					}
+					if _, ok := list[required.resource]; !ok {
+						unset = append(unset, fmt.Sprintf("%s of container %s of %s", name, c.Name, w.name))
+					}
+				}
+			}
+		}
+		if len(exceeded) > 0 || len(unset) > 0 {
+			sort.Strings(exceeded)
+			sort.Strings(unset)
+			return &quotaExceededError{quota: quota, exceeded: exceeded, unset: unset}
+		}
+	}
+	return nil
+}
+
+// namedPodSpec is the pod spec of the workload with the given name.
+type namedPodSpec struct {
+	name string
+	spec *corev1.PodSpec
+}
+
+// defaultedPodSpec returns a copy of the pod spec with the requests and limits that the API server and the given
+// LimitRanges set on containers that don't set them: a request defaults to the limit that the container sets, and
+// otherwise to the default request of a LimitRange, while a limit defaults to the default limit of a LimitRange.
+func defaultedPodSpec(spec *corev1.PodSpec, limitRanges []corev1.LimitRange) *corev1.PodSpec {
+	defaultRequests, defaultLimits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, lr := range limitRanges {
+		for _, item := range lr.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			for name, q := range item.Default {
+				defaultLimits[name] = q.DeepCopy()
+				// A LimitRange without a default request defaults it to the default limit.
+				if _, ok := item.DefaultRequest[name]; !ok {
+					defaultRequests[name] = q.DeepCopy()
+				}
+			}
+			for name, q := range item.DefaultRequest {
+				defaultRequests[name] = q.DeepCopy()
+			}
+		}
+	}
+
+	spec = spec.DeepCopy()
+	for _, containers := range [][]corev1.Container{spec.InitContainers, spec.Containers} {
+		for i := range containers {
+			res := &containers[i].Resources
+			if res.Limits == nil {
+				res.Limits = corev1.ResourceList{}
+			}
+			if res.Requests == nil {
+				res.Requests = corev1.ResourceList{}
+			}
+			for name, q := range res.Limits {
+				if _, ok := res.Requests[name]; !ok {
+					res.Requests[name] = q.DeepCopy()
+				}
+			}
+			for name, q := range defaultLimits {
+				if _, ok := res.Limits[name]; !ok {
+					res.Limits[name] = q.DeepCopy()
+				}
+			}
+			for name, q := range defaultRequests {
+				if _, ok := res.Requests[name]; !ok {
+					res.Requests[name] = q.DeepCopy()
+				}
+			}
+		}
+	}
+	return spec
+}
+
+// workloadPodSpec returns the pod spec of the given workload, along with how many of its pods run at the same time.
+// DaemonSets are not returned, since how many pods they run depends on the nodes.
+func workloadPodSpec(obj client.Object) (*corev1.PodSpec, int64) {
+	switch o := obj.(type) {
+	case *appsv1.Deployment:
+		return &o.Spec.Template.Spec, int64(replicasOrDefault(o.Spec.Replicas))
+	case *appsv1.StatefulSet:
+		return &o.Spec.Template.Spec, int64(replicasOrDefault(o.Spec.Replicas))
+	case *batchv1.Job:
+		return &o.Spec.Template.Spec, int64(replicasOrDefault(o.Spec.Parallelism))
+	case *batchv1.CronJob:
+		return &o.Spec.JobTemplate.Spec.Template.Spec, int64(replicasOrDefault(o.Spec.JobTemplate.Spec.Parallelism))
+	}
+	return nil, 0
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// podQuotaUsage returns what a pod with the given spec counts against a ResourceQuota: the pod itself, along with
+// the larger of the sum of the resources of its containers and the resources of any of its init containers.
+func podQuotaUsage(spec *corev1.PodSpec) corev1.ResourceList {
+	requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+	for _, c := range spec.Containers {
+		addResources(requests, c.Resources.Requests, 1)
+		addResources(limits, c.Resources.Limits, 1)
+	}
+	for _, c := range spec.InitContainers {
+		maxResources(requests, c.Resources.Requests)
+		maxResources(limits, c.Resources.Limits)
+	}
+
+	usage := corev1.ResourceList{corev1.ResourcePods: resource.MustParse("1")}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourceEphemeralStorage} {
+		if q, ok := requests[name]; ok {
+			usage[name] = q.DeepCopy()
+			usage[corev1.ResourceName("requests."+string(name))] = q.DeepCopy()
+		}
+		if q, ok := limits[name]; ok {
+			usage[corev1.ResourceName("limits."+string(name))] = q.DeepCopy()
+		}
+	}
+	return usage
+}
+
+// addResources adds count times each of the resources in add to list.
+func addResources(list, add corev1.ResourceList, count int64) {
+	for name, q := range add {
+		sum := list[name]
+		for i := int64(0); i < count; i++ {
+			sum.Add(q)
+		}
+		list[name] = sum
+	}
+}
+
+// maxResources raises each of the resources in list to the one in other, if that is larger.
+func maxResources(list, other corev1.ResourceList) {
+	for name, q := range other {
+		if current, ok := list[name]; !ok || q.Cmp(current) > 0 {
+			list[name] = q.DeepCopy()
+		}
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// ReplicasReadyConditionType is the type of the IntrusionDetection status condition that tells whether each of the
+// intrusion detection Deployments has all of its desired replicas ready, along with the reasons it is set with.
+const (
+	ReplicasReadyConditionType = "ReplicasReady"
+
+	AllReplicasReadyReason    = "AllReplicasReady"
+	ReplicasUnavailableReason = "ReplicasUnavailable"
+)
+
+// replicasReadyCondition compares the desired and ready replicas of each of the given current Deployments, and returns
+// the ReplicasReady condition that records the shortfalls, along with why their pending pods can't be scheduled. The
+// Deployments and pods of the components are watched, so the condition follows their rollout.
+func (r *ReconcileIntrusionDetection) replicasReadyCondition(ctx context.Context, instance *operatorv1.IntrusionDetection, current []client.Object) (metav1.Condition, error) {
+	var shortfalls []string
+	for _, w := range current {
+		d, ok := w.(*appsv1.Deployment)
+		if !ok {
+			continue
+		}
+		desired := replicasOrDefault(d.Spec.Replicas)
+		if d.Status.ReadyReplicas >= desired {
+			continue
+		}
+		shortfall := fmt.Sprintf("Deployment %s/%s has %d/%d replicas ready", d.Namespace, d.Name, d.Status.ReadyReplicas, desired)
+		reason, err := r.schedulingFailure(ctx, d)
+		if err != nil {
+			return metav1.Condition{}, err
+		}
+		if reason != "" {
+			shortfall += ", " + reason
+		}
+		shortfalls = append(shortfalls, shortfall)
+	}
+
+	condition := metav1.Condition{
+		Type:               ReplicasReadyConditionType,
+		Status:             metav1.ConditionTrue,
+		Reason:             AllReplicasReadyReason,
+		Message:            "All intrusion detection Deployments have their desired replicas ready",
+		ObservedGeneration: instance.Generation,
+	}
+	if len(shortfalls) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReplicasUnavailableReason
+		condition.Message = strings.Join(shortfalls, "; ")
+	}
+	return condition, nil
+}
+
+// instanceRequestsForNamespace returns a function that maps an object to a request for the IntrusionDetection whose
+// components are installed into the namespace of the object, if any.
+func instanceRequestsForNamespace(cli client.Client) handler.MapFunc {
+	return func(obj client.Object) []reconcile.Request {
+		list := &operatorv1.IntrusionDetectionList{}
+		if err := cli.List(context.Background(), list); err != nil {
+			log.Error(err, "Failed to list IntrusionDetections for a change in their namespaces", "object", client.ObjectKeyFromObject(obj))
+			return nil
+		}
+		for i := range list.Items {
+			if instanceNamespace(&list.Items[i]) == obj.GetNamespace() {
+				return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: list.Items[i].Name}}}
+			}
+		}
+		return nil
+	}
+}
+
+// schedulingFailure returns why the first of the pending pods of the given Deployment can't be scheduled, or an empty
+// reason when none of its pods is failing to schedule.
+func (r *ReconcileIntrusionDetection) schedulingFailure(ctx context.Context, d *appsv1.Deployment) (string, error) {
+	if d.Spec.Selector == nil {
+		return "", nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(d.Spec.Selector)
+	if err != nil {
+		return "", err
+	}
+	pods := &corev1.PodList{}
+	if err := r.client.List(ctx, pods, client.InNamespace(d.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+	for _, p := range pods.Items {
+		if p.Status.Phase != corev1.PodPending {
+			continue
+		}
+		for _, c := range p.Status.Conditions {
+			if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionFalse {
+				return fmt.Sprintf("pod %s can't be scheduled: %s: %s", p.Name, c.Reason, c.Message), nil
+			}
+		}
+	}
+	return "", nil
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"fmt"
+	"sync"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/status"
+)
+
+// instanceStatuses holds a status manager for each additional IntrusionDetection, so that each one reports in a
+// TigeraStatus of its own rather than in that of the default IntrusionDetection. A nil instanceStatuses reports every
+// IntrusionDetection in the status manager of the default one.
+type instanceStatuses struct {
+	lock      sync.Mutex
+	newStatus func(component string) status.StatusManager
+	statuses  map[string]status.StatusManager
+}
+
+// newInstanceStatuses returns an instanceStatuses that creates the status manager of an IntrusionDetection with
+// newStatus the first time it is needed.
+func newInstanceStatuses(newStatus func(component string) status.StatusManager) *instanceStatuses {
+	return &instanceStatuses{newStatus: newStatus, statuses: map[string]status.StatusManager{}}
+}
+
+// get returns the status manager of the given additional IntrusionDetection.
+func (s *instanceStatuses) get(instance *operatorv1.IntrusionDetection) status.StatusManager {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	sm, ok := s.statuses[instance.Name]
+	if !ok {
+		sm = s.newStatus(tigeraStatusName(instance))
+		s.statuses[instance.Name] = sm
+	}
+	return sm
+}
+
+// tigeraStatusName returns the name of the TigeraStatus that the given IntrusionDetection reports in.
+func tigeraStatusName(instance *operatorv1.IntrusionDetection) string {
+	if isDefaultInstance(instance) {
+		return ResourceName
+	}
+	return fmt.Sprintf("%s-%s", ResourceName, instance.Name)
+}
+
+// statusFor returns the status manager of the given IntrusionDetection.
+func (r *ReconcileIntrusionDetection) statusFor(instance *operatorv1.IntrusionDetection) status.StatusManager {
+	if isDefaultInstance(instance) || r.statuses == nil {
+		return r.status
+	}
+	return r.statuses.get(instance)
+}
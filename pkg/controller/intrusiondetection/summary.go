@@ -0,0 +1,177 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/go-logr/logr"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/controller/status"
+)
+
+// reconcileSummary collects the health signals of a reconcile of an IntrusionDetection as it goes, so that they can be
+// summarized in its status once the reconcile is over.
+type reconcileSummary struct {
+	// rendered is set once the components have been rendered.
+	rendered *renderedObjects
+	// license is set once the license has been checked.
+	license string
+	// degradedReason is the reason of the latest degraded status that the reconcile set.
+	degradedReason operatorv1.TigeraStatusReason
+}
+
+// degradedRecorder is a status manager that records the reason of each degraded status it is set to in a summary.
+type degradedRecorder struct {
+	status.StatusManager
+	summary *reconcileSummary
+}
+
+func (d degradedRecorder) SetDegraded(reason operatorv1.TigeraStatusReason, msg string, err error, log logr.Logger) {
+	d.summary.degradedReason = reason
+	d.StatusManager.SetDegraded(reason, msg, err, log)
+}
+
+// License states of the summary.
+const (
+	summaryLicenseOK      = "license OK"
+	summaryLicenseGrace   = "license lost, removing components after the grace period"
+	summaryLicenseMissing = "license does not include intrusion detection"
+)
+
+// setSummary writes the summary of the given reconcile, along with the ReplicasReady condition, to the status of the
+// IntrusionDetection, if either has changed. Both are taken from the same read of the rendered workloads, and written
+// together. The summary only holds signals that change when the health does, so that a reconcile that changes nothing
+// doesn't write the status.
+func (r *ReconcileIntrusionDetection) setSummary(ctx context.Context, instance *operatorv1.IntrusionDetection, summary *reconcileSummary, reconcileErr error, reqLogger logr.Logger) {
+	var current []client.Object
+	var replicas *metav1.Condition
+	if summary.rendered != nil {
+		var err error
+		if current, err = r.currentWorkloads(ctx, summary.rendered); err != nil {
+			reqLogger.Error(err, "Failed to summarize the IntrusionDetection")
+			return
+		}
+		condition, err := r.replicasReadyCondition(ctx, instance, current)
+		if err != nil {
+			reqLogger.Error(err, "Failed to summarize the IntrusionDetection")
+			return
+		}
+		if conditionChanged(instance.Status.Conditions, condition) {
+			replicas = &condition
+		}
+	}
+	text := summarize(instance, summary, current, reconcileErr)
+	if instance.Status.Summary == text && replicas == nil {
+		return
+	}
+	if err := r.updateStatus(ctx, instance, func(s *operatorv1.IntrusionDetectionStatus) {
+		s.Summary = text
+		if replicas != nil {
+			meta.SetStatusCondition(&s.Conditions, *replicas)
+		}
+	}); err != nil && !errors.IsNotFound(err) {
+		reqLogger.Error(err, "Failed to update the IntrusionDetection summary")
+	}
+}
+
+// currentWorkloads reads the current state of each of the rendered workloads. The returned objects are in the order of
+// the rendered ones, with nil for the ones that don't exist.
+func (r *ReconcileIntrusionDetection) currentWorkloads(ctx context.Context, rendered *renderedObjects) ([]client.Object, error) {
+	current := make([]client.Object, len(rendered.workloads))
+	for i, w := range rendered.workloads {
+		obj := w.DeepCopyObject().(client.Object)
+		if err := r.client.Get(ctx, client.ObjectKeyFromObject(w), obj); err != nil {
+			if errors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		current[i] = obj
+	}
+	return current, nil
+}
+
+// summarize returns the summary of the given reconcile, given the current state of the rendered workloads.
+func summarize(instance *operatorv1.IntrusionDetection, summary *reconcileSummary, current []client.Object, reconcileErr error) string {
+	var parts []string
+	if reconcileErr != nil {
+		parts = append(parts, "reconcile failed")
+	}
+
+	if summary.rendered == nil {
+		parts = append(parts, "components not rendered")
+	} else {
+		var notReady []string
+		for i, w := range summary.rendered.workloads {
+			if !workloadReady(current[i]) {
+				notReady = append(notReady, w.GetName())
+			}
+		}
+		total := len(summary.rendered.workloads)
+		components := fmt.Sprintf("%d/%d components ready", total-len(notReady), total)
+		if len(notReady) > 0 {
+			sort.Strings(notReady)
+			components += fmt.Sprintf(" (not ready: %s)", strings.Join(notReady, ", "))
+		}
+		parts = append(parts, components)
+	}
+
+	if condition := meta.FindStatusCondition(instance.Status.Conditions, ElasticsearchReachableConditionType); condition != nil {
+		switch condition.Reason {
+		case ElasticsearchReachableReason:
+			parts = append(parts, "Elasticsearch reachable")
+		case ElasticsearchAuthenticationFailedReason:
+			parts = append(parts, "Elasticsearch rejects the credentials")
+		default:
+			parts = append(parts, "Elasticsearch unreachable")
+		}
+	}
+
+	if summary.license != "" {
+		parts = append(parts, summary.license)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// workloadReady returns whether the given Deployment, DaemonSet or StatefulSet exists, has rolled out and all of its
+// pods are available.
+func workloadReady(obj client.Object) bool {
+	switch w := obj.(type) {
+	case nil:
+		return false
+	case *appsv1.Deployment:
+		replicas := replicasOrDefault(w.Spec.Replicas)
+		return w.Status.ObservedGeneration >= w.Generation && w.Status.UpdatedReplicas >= replicas &&
+			w.Status.AvailableReplicas >= replicas
+	case *appsv1.DaemonSet:
+		return w.Status.ObservedGeneration >= w.Generation && w.Status.UpdatedNumberScheduled >= w.Status.DesiredNumberScheduled &&
+			w.Status.NumberAvailable >= w.Status.DesiredNumberScheduled
+	case *appsv1.StatefulSet:
+		replicas := replicasOrDefault(w.Spec.Replicas)
+		return w.Status.ObservedGeneration >= w.Generation && w.Status.UpdatedReplicas >= replicas &&
+			w.Status.AvailableReplicas >= replicas
+	}
+	return true
+}
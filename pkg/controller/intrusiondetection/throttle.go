@@ -0,0 +1,66 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/tigera/operator/pkg/controller/options"
+)
+
+// defaultThrottleDelay is how long a reconcile that the API server throttled waits before it is retried when the API
+// server doesn't say how long to wait.
+const defaultThrottleDelay = 5 * time.Second
+
+// newRateLimitedClient returns a client whose requests to the API server are limited to the given QPS and burst
+// across all kinds, so that the bursty reads and writes of the intrusion detection controller don't add to the load
+// of an overloaded API server. Like the client of the manager, it reads from the cache of the manager.
+func newRateLimitedClient(mgr manager.Manager, qps float32, burst int) (client.Client, error) {
+	cfg := rest.CopyConfig(mgr.GetConfig())
+	if burst == 0 {
+		burst = rest.DefaultBurst
+	}
+	cfg.QPS, cfg.Burst = qps, burst
+	cfg.RateLimiter = flowcontrol.NewTokenBucketRateLimiter(qps, burst)
+
+	c, err := client.New(cfg, client.Options{Scheme: mgr.GetScheme(), Mapper: mgr.GetRESTMapper()})
+	if err != nil {
+		return nil, err
+	}
+	return client.NewDelegatingClient(client.NewDelegatingClientInput{
+		CacheReader: mgr.GetCache(),
+		Client:      c,
+		// Like the client of the manager, it reads some kinds from the API server.
+		UncachedObjects: options.UncachedObjects(),
+	})
+}
+
+// throttleDelay returns whether the given error is the API server rejecting a request because it is overloaded, along
+// with how long to wait before retrying, as hinted by the Retry-After of its response.
+func throttleDelay(err error) (time.Duration, bool) {
+	if !errors.IsTooManyRequests(err) {
+		return 0, false
+	}
+	if seconds, ok := errors.SuggestsClientDelay(err); ok && seconds > 0 {
+		return time.Duration(seconds) * time.Second, true
+	}
+	return defaultThrottleDelay, true
+}
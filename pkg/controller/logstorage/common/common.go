@@ -125,3 +125,23 @@ func CalculateFlowShards(nodesSpecifications *operatorv1.Nodes, defaultShards in
 
 	return int(nodes) * shardPerNode
 }
+
+// AwarenessAttributes returns the names of the Elasticsearch node attributes that are configured through the
+// selection attributes of the node sets, in the order they first appear.
+func AwarenessAttributes(nodesSpecifications *operatorv1.Nodes) []string {
+	if nodesSpecifications == nil {
+		return nil
+	}
+
+	var attrs []string
+	seen := map[string]bool{}
+	for _, nodeSet := range nodesSpecifications.NodeSets {
+		for _, attr := range nodeSet.SelectionAttributes {
+			if !seen[attr.Name] {
+				seen[attr.Name] = true
+				attrs = append(attrs, attr.Name)
+			}
+		}
+	}
+	return attrs
+}
@@ -399,7 +399,10 @@ func (r *ElasticSubController) Reconcile(ctx context.Context, request reconcile.
 	var esAdminUserSecret *corev1.Secret
 
 	flowShards := logstoragecommon.CalculateFlowShards(ls.Spec.Nodes, logstoragecommon.DefaultElasticsearchShards)
-	clusterConfig = relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards)
+	awarenessAttributes := logstoragecommon.AwarenessAttributes(ls.Spec.Nodes)
+	clusterConfig = relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards, awarenessAttributes...).
+		WithIndexMappingLimits(ls.IndexMappingLimits()).
+		WithIndexPrefix(ls.IndexPrefix())
 
 	// Check if there is a StorageClass available to run Elasticsearch on.
 	if err = r.client.Get(ctx, client.ObjectKey{Name: ls.Spec.StorageClassName}, &storagev1.StorageClass{}); err != nil {
@@ -137,7 +137,9 @@ func (r *ExternalESController) Reconcile(ctx context.Context, request reconcile.
 	}
 
 	flowShards := logstoragecommon.CalculateFlowShards(ls.Spec.Nodes, logstoragecommon.DefaultElasticsearchShards)
-	clusterConfig := relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards)
+	clusterConfig := relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, ls.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards).
+		WithIndexMappingLimits(ls.IndexMappingLimits()).
+		WithIndexPrefix(ls.IndexPrefix())
 
 	hdler := utils.NewComponentHandler(reqLogger, r.client, r.scheme, ls)
 	externalElasticsearch := externalelasticsearch.ExternalElasticsearch(install, clusterConfig, pullSecrets)
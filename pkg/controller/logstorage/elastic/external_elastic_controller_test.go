@@ -27,6 +27,7 @@ import (
 	"github.com/tigera/operator/pkg/controller/utils"
 	"github.com/tigera/operator/pkg/dns"
 	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/secret"
 
@@ -41,6 +42,7 @@ import (
 	storagev1 "k8s.io/api/storage/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
@@ -186,6 +188,27 @@ var _ = Describe("External ES Controller", func() {
 		Expect(result).Should(Equal(reconcile.Result{}))
 		mockStatus.AssertExpectations(GinkgoT())
 	})
+
+	It("writes the index prefix to the cluster config ConfigMap", func() {
+		CreateLogStorage(cli, &operatorv1.LogStorage{
+			ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"},
+			Spec:       operatorv1.LogStorageSpec{Indices: &operatorv1.Indices{Prefix: "cluster-a"}},
+			Status:     operatorv1.LogStorageStatus{State: operatorv1.TigeraStatusReady},
+		})
+
+		mockStatus.On("ClearDegraded")
+		r, err := NewExternalESReconcilerWithShims(cli, scheme, mockStatus, operatorv1.ProviderNone, dns.DefaultClusterDomain)
+		Expect(err).ShouldNot(HaveOccurred())
+		_, err = r.Reconcile(ctx, reconcile.Request{})
+		Expect(err).ToNot(HaveOccurred())
+
+		cm := &corev1.ConfigMap{}
+		Expect(cli.Get(ctx, types.NamespacedName{Name: relasticsearch.ClusterConfigConfigMapName, Namespace: common.OperatorNamespace()}, cm)).NotTo(HaveOccurred())
+		Expect(cm.Data).To(HaveKeyWithValue("indexPrefix", "cluster-a"))
+		clusterConfig, err := relasticsearch.NewClusterConfigFromConfigMap(cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(clusterConfig.IndexPrefix()).To(Equal("cluster-a"))
+	})
 })
 
 func NewExternalESReconcilerWithShims(
@@ -89,6 +89,9 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	if err = utils.AddInstallationWatch(c); err != nil {
 		return fmt.Errorf("log-storage-esmetrics-controller failed to watch Installation resource: %w", err)
 	}
+	if err = imageset.AddDigestAllowlistWatch(c, &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("log-storage-esmetrics-controller failed to watch the image digest allowlist: %w", err)
+	}
 	if err = c.Watch(&source.Kind{Type: &operatorv1.ManagementClusterConnection{}}, &handler.EnqueueRequestForObject{}); err != nil {
 		return fmt.Errorf("log-storage-esmetrics-controller failed to watch ManagementClusterConnection resource: %w", err)
 	}
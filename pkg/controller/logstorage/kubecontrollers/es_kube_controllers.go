@@ -100,6 +100,9 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	if err = utils.AddTigeraStatusWatch(c, "log-storage-kubecontrollers"); err != nil {
 		return fmt.Errorf("logstorage-controller failed to watch logstorage Tigerastatus: %w", err)
 	}
+	if err = imageset.AddDigestAllowlistWatch(c, eventHandler); err != nil {
+		return fmt.Errorf("log-storage-kubecontrollers failed to watch the image digest allowlist: %w", err)
+	}
 
 	// Watch secrets this controller cares about.
 	secretsToWatch := []string{
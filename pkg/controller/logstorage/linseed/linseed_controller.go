@@ -53,6 +53,7 @@ import (
 	"github.com/tigera/operator/pkg/render/logstorage/linseed"
 	"github.com/tigera/operator/pkg/render/monitor"
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
+	operatorurl "github.com/tigera/operator/pkg/url"
 )
 
 var log = logf.Log.WithName("controller_logstorage_linseed")
@@ -116,6 +117,9 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	if err = utils.AddTigeraStatusWatch(c, "log-storage-access"); err != nil {
 		return fmt.Errorf("logstorage-access-controller failed to watch logstorage Tigerastatus: %w", err)
 	}
+	if err = imageset.AddDigestAllowlistWatch(c, eventHandler); err != nil {
+		return fmt.Errorf("log-storage-access-controller failed to watch the image digest allowlist: %w", err)
+	}
 	if opts.MultiTenant {
 		if err = c.Watch(&source.Kind{Type: &operatorv1.Tenant{}}, &handler.EnqueueRequestForObject{}); err != nil {
 			return fmt.Errorf("log-storage-access-controller failed to watch Tenant resource: %w", err)
@@ -319,13 +323,25 @@ func (r *LinseedSubController) Reconcile(ctx context.Context, request reconcile.
 			return reconcile.Result{}, nil
 		}
 
-		// Determine the host and port from the URL.
-		url, err := url.Parse(tenant.Spec.Elastic.URL)
+		// Determine the host and port from the URL, which may be given as host:port or as a full URL.
+		elasticURL, err := operatorurl.NormalizeURL(tenant.Spec.Elastic.URL)
 		if err != nil {
 			reqLogger.Error(err, "Elasticsearch URL is invalid")
 			r.status.SetDegraded(operatorv1.ResourceValidationError, "Elasticsearch URL is invalid", err, reqLogger)
 			return reconcile.Result{}, nil
 		}
+		if tenant.Spec.Elastic.KibanaURL != "" {
+			if _, err := operatorurl.NormalizeURL(tenant.Spec.Elastic.KibanaURL); err != nil {
+				reqLogger.Error(err, "Kibana URL is invalid")
+				r.status.SetDegraded(operatorv1.ResourceValidationError, "Kibana URL is invalid", err, reqLogger)
+				return reconcile.Result{}, nil
+			}
+		}
+		url, err := url.Parse(elasticURL)
+		if err != nil {
+			r.status.SetDegraded(operatorv1.ResourceValidationError, "Elasticsearch URL is invalid", err, reqLogger)
+			return reconcile.Result{}, nil
+		}
 		elasticHost = url.Hostname()
 		elasticPort = url.Port()
 
@@ -382,7 +398,8 @@ func (r *LinseedSubController) Reconcile(ctx context.Context, request reconcile.
 	var esClusterConfig *relasticsearch.ClusterConfig
 	if managementClusterConnection == nil {
 		flowShards := logstoragecommon.CalculateFlowShards(logStorage.Spec.Nodes, logstoragecommon.DefaultElasticsearchShards)
-		esClusterConfig = relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, logStorage.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards)
+		esClusterConfig = relasticsearch.NewClusterConfig(render.DefaultElasticsearchClusterName, logStorage.Replicas(), logstoragecommon.DefaultElasticsearchShards, flowShards).
+			WithIndexPrefix(logStorage.IndexPrefix())
 	}
 
 	// Query the username and password this Linseed instance should use to authenticate with Elasticsearch.
@@ -498,6 +498,48 @@ var _ = Describe("LogStorage Linseed controller", func() {
 				Expect(linseed.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_PORT", Value: "443"}))
 			})
 
+			It("should accept a host:port Elasticsearch URL", func() {
+				tenant.Spec.Elastic.URL = "External.Elastic:9243"
+				Expect(cli.Update(ctx, tenant)).ShouldNot(HaveOccurred())
+
+				result, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: tenant.Name, Namespace: tenant.Namespace}})
+				Expect(err).ShouldNot(HaveOccurred())
+				Expect(result).Should(Equal(successResult))
+
+				linseedDp := appsv1.Deployment{
+					TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      linseed.DeploymentName,
+						Namespace: tenant.Namespace,
+					},
+				}
+				Expect(test.GetResource(cli, &linseedDp)).To(BeNil())
+				linseed := test.GetContainer(linseedDp.Spec.Template.Spec.Containers, linseed.DeploymentName)
+				Expect(linseed).ToNot(BeNil())
+				Expect(linseed.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_HOST", Value: "external.elastic"}))
+				Expect(linseed.Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_PORT", Value: "9243"}))
+			})
+
+			It("should degrade when the Elasticsearch or Kibana URL is malformed", func() {
+				for _, elastic := range []operatorv1.TenantElasticSpec{
+					{URL: "ftp://external.elastic:443"},
+					{URL: "external.elastic:94OO"},
+					{URL: "https://external.elastic:443", KibanaURL: "https://:5601"},
+				} {
+					tenant.Spec.Elastic = elastic.DeepCopy()
+					Expect(cli.Update(ctx, tenant)).ShouldNot(HaveOccurred())
+
+					_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: types.NamespacedName{Name: tenant.Name, Namespace: tenant.Namespace}})
+					Expect(err).ShouldNot(HaveOccurred())
+				}
+				mockStatus.AssertNumberOfCalls(GinkgoT(), "SetDegraded", 3)
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, "Elasticsearch URL is invalid", mock.Anything, mock.Anything)
+				mockStatus.AssertCalled(GinkgoT(), "SetDegraded", operatorv1.ResourceValidationError, "Kibana URL is invalid", mock.Anything, mock.Anything)
+
+				linseedDp := appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: linseed.DeploymentName, Namespace: tenant.Namespace}}
+				Expect(test.GetResource(cli, &linseedDp)).NotTo(BeNil())
+			})
+
 			It("should reconcile with mTLS enabled", func() {
 				// Update the tenant with mTLS
 				tenant.Spec.Elastic.MutualTLS = true
@@ -31,6 +31,7 @@ import (
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	"github.com/tigera/operator/pkg/render/common/secret"
+	"github.com/tigera/operator/pkg/url"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -285,7 +286,11 @@ func (r *UserController) Reconcile(ctx context.Context, request reconcile.Reques
 	// Now that the secret has been created, also provision the user in ES.
 	elasticEndpoint := relasticsearch.ECKElasticEndpoint()
 	if tenant.Spec.Elastic != nil && tenant.Spec.Elastic.URL != "" {
-		elasticEndpoint = tenant.Spec.Elastic.URL
+		elasticEndpoint, err = url.NormalizeURL(tenant.Spec.Elastic.URL)
+		if err != nil {
+			r.status.SetDegraded(operatorv1.ResourceValidationError, "Elasticsearch URL is invalid", err, reqLogger)
+			return reconcile.Result{}, nil
+		}
 	}
 	if err = r.createLinseedLogin(ctx, elasticEndpoint, clusterID, tenantID, &basicCreds, reqLogger); err != nil {
 		r.status.SetDegraded(operatorv1.ResourceUpdateError, "Failed to create Linseed user in ES", err, reqLogger)
@@ -132,6 +132,9 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 	if err = managerController.Watch(&source.Kind{Type: &operatorv1.ImageSet{}}, eventHandler); err != nil {
 		return fmt.Errorf("manager-controller failed to watch ImageSet: %w", err)
 	}
+	if err = imageset.AddDigestAllowlistWatch(managerController, eventHandler); err != nil {
+		return fmt.Errorf("manager-controller failed to watch the image digest allowlist: %w", err)
+	}
 	if opts.MultiTenant {
 		if err = managerController.Watch(&source.Kind{Type: &operatorv1.Tenant{}}, &handler.EnqueueRequestForObject{}); err != nil {
 			return fmt.Errorf("manager-controller failed to watch Tenant resource: %w", err)
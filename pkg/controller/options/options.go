@@ -16,7 +16,11 @@ package options
 
 import (
 	"context"
+	"time"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	v1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/common"
 )
@@ -46,4 +50,27 @@ type AddOptions struct {
 
 	// Whether or not the cluster supports PodSecurityPolicies.
 	UsePSP bool
+
+	// How often the intrusion detection controller resyncs after a successful reconcile, in addition
+	// to reconciling on watch events. Zero disables the periodic resync.
+	IntrusionDetectionSyncPeriod time.Duration
+
+	// The maximum fraction by which the intrusion detection controller randomly lengthens the delay of a requeue, so
+	// that requeues of many reconciles that fire together are spread out. Zero disables the jitter.
+	IntrusionDetectionRequeueJitter float64
+
+	// The number of requests the intrusion detection controller reconciles at the same time. Zero is treated as one.
+	IntrusionDetectionMaxConcurrentReconciles int
+
+	// The number of requests per second the intrusion detection controller makes to the API server, apart from the
+	// reads served from the cache of the manager, and the burst it may exceed that by. A QPS of zero makes it share
+	// the client of the manager, and a burst of zero uses the client-go default.
+	IntrusionDetectionQPS   float32
+	IntrusionDetectionBurst int
+}
+
+// UncachedObjects returns the kinds that the clients of the operator read from the API server rather than from the
+// cache of the manager.
+func UncachedObjects() []client.Object {
+	return []client.Object{&v3.LicenseKey{}}
 }
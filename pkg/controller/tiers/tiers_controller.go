@@ -78,6 +78,11 @@ func Add(mgr manager.Manager, opts options.AddOptions) error {
 		{Name: tiers.ClusterDNSPolicyName, Namespace: "kube-system"},
 	})
 
+	// Additional IntrusionDetections install into namespaces of their own, which need access to the DNS service.
+	if err = c.Watch(&source.Kind{Type: &operatorv1.IntrusionDetection{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return fmt.Errorf("tiers-controller failed to watch IntrusionDetection resource: %w", err)
+	}
+
 	if opts.MultiTenant {
 		if err = c.Watch(&source.Kind{Type: &operatorv1.Tenant{}}, &handler.EnqueueRequestForObject{}); err != nil {
 			return fmt.Errorf("tiers-controller failed to watch Tenant resource: %w", err)
@@ -204,6 +209,16 @@ func (r *ReconcileTiers) prepareTiersConfig(ctx context.Context, reqLogger logr.
 		}
 		namespaces = append(namespaces, tenantNamespaces...)
 	}
+	intrusionDetections := operatorv1.IntrusionDetectionList{}
+	if err := r.client.List(ctx, &intrusionDetections); err != nil {
+		r.status.SetDegraded(operatorv1.ResourceReadError, "Error querying IntrusionDetections", err, reqLogger)
+		return nil, &reconcile.Result{RequeueAfter: utils.StandardRetry}
+	}
+	for _, ids := range intrusionDetections.Items {
+		if ids.Name != utils.DefaultTSEEInstanceKey.Name {
+			namespaces = append(namespaces, render.IntrusionDetectionInstanceNamespace(ids.Name))
+		}
+	}
 	tiersConfig.CalicoNamespaces = namespaces
 
 	// node-local-dns is not supported on openshift
@@ -122,6 +122,16 @@ var _ = Describe("tier controller tests", func() {
 		Expect(c.Get(ctx, client.ObjectKey{Name: "allow-tigera"}, &tier)).To(BeNil())
 	})
 
+	It("allows the namespaces of additional IntrusionDetections to access the DNS service", func() {
+		Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}})).NotTo(HaveOccurred())
+		Expect(c.Create(ctx, &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tenant-a"}})).NotTo(HaveOccurred())
+
+		cfg, result := r.prepareTiersConfig(ctx, log)
+		Expect(result).To(BeNil())
+		Expect(cfg.CalicoNamespaces).To(ContainElements("tigera-intrusion-detection", "tigera-intrusion-detection-tenant-a"))
+		Expect(cfg.CalicoNamespaces).NotTo(ContainElement("tigera-intrusion-detection-tigera-secure"))
+	})
+
 	It("waits for API server to be available before reconciling", func() {
 		err := c.Delete(ctx, &operatorv1.APIServer{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}})
 		Expect(err).ShouldNot(HaveOccurred())
@@ -18,7 +18,9 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 
@@ -30,9 +32,11 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
+	apimachineryvalidation "k8s.io/apimachinery/pkg/api/validation"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
@@ -47,15 +51,30 @@ type ComponentHandler interface {
 	CreateOrUpdateOrDelete(context.Context, render.Component, status.StatusManager) error
 }
 
+// ComponentHandlerOption configures optional behaviour of the ComponentHandler.
+type ComponentHandlerOption func(*componentHandler)
+
+// WithRecreateOnImmutableFieldChange makes the ComponentHandler delete and recreate an object when the API server
+// rejects its update because an immutable field has changed. By default such updates return an error.
+func WithRecreateOnImmutableFieldChange(recreate bool) ComponentHandlerOption {
+	return func(c *componentHandler) {
+		c.recreateOnImmutableFieldChange = recreate
+	}
+}
+
 // cr is allowed to be nil in the case we don't want to put ownership on a resource,
 // this is useful for CRD management so that they are not removed automatically.
-func NewComponentHandler(log logr.Logger, client client.Client, scheme *runtime.Scheme, cr metav1.Object) ComponentHandler {
-	return &componentHandler{
+func NewComponentHandler(log logr.Logger, client client.Client, scheme *runtime.Scheme, cr metav1.Object, opts ...ComponentHandlerOption) ComponentHandler {
+	c := &componentHandler{
 		client: client,
 		scheme: scheme,
 		cr:     cr,
 		log:    log,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
 type componentHandler struct {
@@ -63,6 +82,9 @@ type componentHandler struct {
 	scheme *runtime.Scheme
 	cr     metav1.Object
 	log    logr.Logger
+
+	// recreateOnImmutableFieldChange controls whether objects are recreated when an update changes an immutable field.
+	recreateOnImmutableFieldChange bool
 }
 
 func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.Object, osType rmeta.OSType) error {
@@ -154,6 +176,12 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 			// Do the Create() with the merged object so that we preserve external labels/annotations.
 			resetMetadataForCreate(mobj)
 			if err := c.client.Create(ctx, mobj); err != nil {
+				if errors.IsAlreadyExists(err) {
+					// A finalizer is holding the old Job back. It is created on a later reconcile, once the old
+					// one is gone.
+					logCtx.WithValues("key", key).Info("Job is still being deleted, it will be recreated once it is gone")
+					return nil
+				}
 				logCtx.WithValues("key", key).Error(err, "Failed to create Job.")
 				return err
 			}
@@ -236,6 +264,34 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 			}
 		}
 		if err := c.client.Update(ctx, mobj); err != nil {
+			if c.recreateOnImmutableFieldChange && isImmutableFieldError(err) {
+				if holdsData(obj) {
+					logCtx.WithValues("key", key).Info("Object has a change to an immutable field, but recreating it would lose its data.", "reason", err)
+					return err
+				}
+				logCtx.WithValues("key", key).Info("Object has a change to an immutable field, recreating it.", "reason", err)
+				// Only delete the version of the object that the update was based on, so that a change made to it in
+				// the meantime isn't lost.
+				uid, resourceVersion := cur.GetUID(), cur.GetResourceVersion()
+				if err := c.client.Delete(ctx, obj, client.Preconditions{UID: &uid, ResourceVersion: &resourceVersion}); err != nil {
+					logCtx.WithValues("key", key).Error(err, "Failed to delete object for recreation.")
+					return err
+				}
+				// The API server keeps the object around while its finalizers run, so wait for it to be gone before
+				// creating it again. If it takes longer, the error requeues the reconcile, which recreates it then.
+				if err := c.waitForDeletion(ctx, cur); err != nil {
+					logCtx.WithValues("key", key).Info("Object is still being deleted, it will be recreated once it is gone.")
+					return err
+				}
+
+				// Do the Create() with the merged object so that we preserve external labels/annotations.
+				resetMetadataForCreate(mobj)
+				if err := c.client.Create(ctx, mobj); err != nil {
+					logCtx.WithValues("key", key).Error(err, "Failed to recreate object.")
+					return err
+				}
+				return nil
+			}
 			logCtx.WithValues("key", key).Info("Failed to update object.")
 			return err
 		}
@@ -243,6 +299,46 @@ func (c componentHandler) createOrUpdateObject(ctx context.Context, obj client.O
 	return nil
 }
 
+// recreateDeletionTimeout is how long the ComponentHandler waits for an object it deleted to be gone before it
+// recreates it.
+var recreateDeletionTimeout = 10 * time.Second
+
+// waitForDeletion waits for the given version of an object to be gone, and returns an error if it is still there after
+// recreateDeletionTimeout.
+func (c componentHandler) waitForDeletion(ctx context.Context, obj client.Object) error {
+	key := client.ObjectKeyFromObject(obj)
+	err := wait.PollImmediate(250*time.Millisecond, recreateDeletionTimeout, func() (bool, error) {
+		cur := obj.DeepCopyObject().(client.Object)
+		if err := c.client.Get(ctx, key, cur); err != nil {
+			if errors.IsNotFound(err) {
+				return true, nil
+			}
+			return false, err
+		}
+		// An object that someone else created in its place makes the create fail, which requeues the reconcile.
+		return cur.GetUID() != obj.GetUID(), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return fmt.Errorf("%s is still being deleted after %s, it will be recreated once it is gone", key, recreateDeletionTimeout)
+	}
+	return err
+}
+
+// isImmutableFieldError returns true if the error is the API server rejecting a change to an immutable field.
+func isImmutableFieldError(err error) bool {
+	return errors.IsInvalid(err) && strings.Contains(err.Error(), apimachineryvalidation.FieldImmutableErrorMsg)
+}
+
+// holdsData returns true if deleting the object would delete data that recreating it doesn't restore, such as the
+// volume of a PersistentVolumeClaim.
+func holdsData(obj client.Object) bool {
+	switch obj.(type) {
+	case *v1.PersistentVolumeClaim, *v1.PersistentVolume:
+		return true
+	}
+	return false
+}
+
 func resetMetadataForCreate(obj client.Object) {
 	obj.SetResourceVersion("")
 	obj.SetUID("")
@@ -17,6 +17,8 @@ package utils
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"time"
 
 	rbacv1 "k8s.io/api/rbac/v1"
 
@@ -36,6 +38,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -1246,6 +1249,113 @@ var _ = Describe("Component handler tests", func() {
 			"Expected update of ClusterRoleBinding to rev resourceversion to 2")
 	})
 
+	Context("immutable field changes", func() {
+		var (
+			immutableClient client.Client
+			oldDep, newDep  *apps.Deployment
+			oldSel, newSel  *metav1.LabelSelector
+		)
+
+		BeforeEach(func() {
+			// The fake client doesn't validate immutable fields, so wrap it with a client that rejects updates to the
+			// Deployment selector the same way the API server does.
+			immutableClient = &immutableSelectorClient{Client: c}
+			oldSel = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "old"}}
+			newSel = &metav1.LabelSelector{MatchLabels: map[string]string{"app": "new"}}
+			oldDep = &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-deployment", Namespace: "default"},
+				Spec:       apps.DeploymentSpec{Selector: oldSel},
+			}
+			newDep = &apps.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-deployment", Namespace: "default"},
+				Spec:       apps.DeploymentSpec{Selector: newSel},
+			}
+			Expect(c.Create(ctx, oldDep)).NotTo(HaveOccurred())
+		})
+
+		It("returns the error by default", func() {
+			handler = NewComponentHandler(logf.Log.WithName("test_utils_logger"), immutableClient, scheme, instance)
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{newDep}}
+
+			err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+			Expect(errors.IsInvalid(err)).To(BeTrue())
+
+			dep := &apps.Deployment{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(oldDep), dep)).NotTo(HaveOccurred())
+			Expect(dep.Spec.Selector).To(Equal(oldSel))
+		})
+
+		It("recreates the object when enabled", func() {
+			handler = NewComponentHandler(logf.Log.WithName("test_utils_logger"), immutableClient, scheme, instance, WithRecreateOnImmutableFieldChange(true))
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{newDep}}
+
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+
+			dep := &apps.Deployment{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(oldDep), dep)).NotTo(HaveOccurred())
+			Expect(dep.Spec.Selector).To(Equal(newSel))
+			// The fake client resets the resource version to 1 on create.
+			Expect(dep.ResourceVersion).To(Equal("1"), "Expected recreation of Deployment to reset resourceVersion to 1")
+		})
+
+		It("waits for an object that finalizers hold back to be gone before it recreates it", func() {
+			defer func(timeout time.Duration) { recreateDeletionTimeout = timeout }(recreateDeletionTimeout)
+			recreateDeletionTimeout = time.Second
+			dep := &apps.Deployment{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(oldDep), dep)).NotTo(HaveOccurred())
+			dep.Finalizers = []string{"example.com/cleanup"}
+			Expect(c.Update(ctx, dep)).NotTo(HaveOccurred())
+			handler = NewComponentHandler(logf.Log.WithName("test_utils_logger"), immutableClient, scheme, instance, WithRecreateOnImmutableFieldChange(true))
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{newDep}}
+
+			err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+			Expect(err).To(MatchError(ContainSubstring("is still being deleted")))
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(oldDep), dep)).NotTo(HaveOccurred())
+			Expect(dep.Spec.Selector).To(Equal(oldSel))
+
+			By("recreating it on a later reconcile, once the finalizer is removed")
+			dep.Finalizers = nil
+			Expect(c.Update(ctx, dep)).NotTo(HaveOccurred())
+			Expect(handler.CreateOrUpdateOrDelete(ctx, fc, sm)).NotTo(HaveOccurred())
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(oldDep), dep)).NotTo(HaveOccurred())
+			Expect(dep.Spec.Selector).To(Equal(newSel))
+		})
+
+		It("doesn't delete an object that was modified since the update was rejected", func() {
+			immutableClient = &immutableSelectorClient{Client: c, modifiedConcurrently: true}
+			handler = NewComponentHandler(logf.Log.WithName("test_utils_logger"), immutableClient, scheme, instance, WithRecreateOnImmutableFieldChange(true))
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{newDep}}
+
+			err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+			Expect(errors.IsConflict(err)).To(BeTrue())
+
+			dep := &apps.Deployment{}
+			Expect(c.Get(ctx, client.ObjectKeyFromObject(oldDep), dep)).NotTo(HaveOccurred())
+			Expect(dep.Spec.Selector).To(Equal(oldSel))
+			Expect(dep.Labels).To(HaveKeyWithValue("modified", "concurrently"))
+		})
+
+		It("doesn't recreate a PersistentVolumeClaim, since that would delete its volume", func() {
+			oldClass, newClass := "standard", "fast"
+			Expect(c.Create(ctx, &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-claim", Namespace: "default"},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &oldClass},
+			})).NotTo(HaveOccurred())
+			handler = NewComponentHandler(logf.Log.WithName("test_utils_logger"), immutableClient, scheme, instance, WithRecreateOnImmutableFieldChange(true))
+			fc := &fakeComponent{supportedOSType: rmeta.OSTypeLinux, objs: []client.Object{&corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "my-claim", Namespace: "default"},
+				Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &newClass},
+			}}}
+
+			err := handler.CreateOrUpdateOrDelete(ctx, fc, sm)
+			Expect(errors.IsInvalid(err)).To(BeTrue())
+
+			pvc := &corev1.PersistentVolumeClaim{}
+			Expect(c.Get(ctx, client.ObjectKey{Name: "my-claim", Namespace: "default"}, pvc)).NotTo(HaveOccurred())
+			Expect(pvc.Spec.StorageClassName).To(Equal(&oldClass))
+		})
+	})
+
 	Context("liveness and readiness probes", func() {
 		It("updates liveness and readiness probe default values", func() {
 			fc := &fakeComponent{
@@ -1886,6 +1996,47 @@ func (c *fakeComponent) SupportedOSType() rmeta.OSType {
 	return c.supportedOSType
 }
 
+// immutableSelectorClient rejects updates that change the selector of a Deployment, like the API server does.
+type immutableSelectorClient struct {
+	client.Client
+
+	// modifiedConcurrently makes the client modify the Deployment when it rejects an update to it, as if another client
+	// changed it in the meantime.
+	modifiedConcurrently bool
+}
+
+func (ic *immutableSelectorClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	switch o := obj.(type) {
+	case *apps.Deployment:
+		cur := &apps.Deployment{}
+		if err := ic.Client.Get(ctx, client.ObjectKeyFromObject(o), cur); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(cur.Spec.Selector, o.Spec.Selector) {
+			if ic.modifiedConcurrently {
+				cur.Labels = map[string]string{"modified": "concurrently"}
+				if err := ic.Client.Update(ctx, cur); err != nil {
+					return err
+				}
+			}
+			return errors.NewInvalid(schema.GroupKind{Group: "apps", Kind: "Deployment"}, o.Name, field.ErrorList{
+				field.Invalid(field.NewPath("spec", "selector"), o.Spec.Selector, "field is immutable"),
+			})
+		}
+	case *corev1.PersistentVolumeClaim:
+		cur := &corev1.PersistentVolumeClaim{}
+		if err := ic.Client.Get(ctx, client.ObjectKeyFromObject(o), cur); err != nil {
+			return err
+		}
+		if !reflect.DeepEqual(cur.Spec.StorageClassName, o.Spec.StorageClassName) {
+			return errors.NewInvalid(schema.GroupKind{Kind: "PersistentVolumeClaim"}, o.Name, field.ErrorList{
+				field.Invalid(field.NewPath("spec"), o.Spec, "spec is immutable after creation except resources.requests for bound claims"),
+			})
+		}
+	}
+	return ic.Client.Update(ctx, obj, opts...)
+}
+
 type mockReturn struct {
 	Method       string
 	Return       interface{}
@@ -22,11 +22,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	kerrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 )
 
 var log = logf.Log.WithName("discovery")
@@ -255,6 +258,24 @@ func SupportsPodSecurityPolicies(c kubernetes.Interface) (bool, error) {
 	return false, nil
 }
 
+// BootstrapConfigMapName is the name of the ConfigMap in the operator namespace that contains cluster-wide
+// configuration for the operator.
+const BootstrapConfigMapName = "operator-bootstrap-config"
+
+// ReadUseExternalElastic reads the bootstrap configuration of the operator from the cluster and returns whether it
+// configures an external elasticsearch cluster. It returns false when there is no bootstrap configuration, so that
+// controllers can follow changes to the configuration without a restart of the operator.
+func ReadUseExternalElastic(ctx context.Context, cli client.Client) (bool, error) {
+	config := &corev1.ConfigMap{}
+	if err := cli.Get(ctx, types.NamespacedName{Name: BootstrapConfigMapName, Namespace: common.OperatorNamespace()}, config); err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return UseExternalElastic(config), nil
+}
+
 // UseExternalElastic returns true if this cluster is configured to use an external elasticsearch cluster,
 // and false otherwise.
 func UseExternalElastic(config *corev1.ConfigMap) bool {
@@ -19,18 +19,33 @@ import (
 	"fmt"
 	"strings"
 
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	operator "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
 )
 
-// ApplyImageSet gets the appropriate ImageSet, validates the ImageSet, and calls ResolveImages
-// passing in the ImageSet on each of the comps.
+const (
+	// DigestAllowlistConfigMapName is the name of the optional ConfigMap, in the operator namespace, that lists the
+	// image digests the operator is allowed to deploy.
+	DigestAllowlistConfigMapName = "tigera-image-digest-allowlist"
+
+	// DigestAllowlistKey is the key of the allowlist ConfigMap holding the whitespace separated digests.
+	DigestAllowlistKey = "digests"
+)
+
+// ApplyImageSet gets the appropriate ImageSet, validates the ImageSet, verifies its digests against the image digest
+// allowlist, if there is one, and calls ResolveImages passing in the ImageSet on each of the comps. Controllers that
+// call it must watch the allowlist, either with AddImageSetWatch or AddDigestAllowlistWatch.
 func ApplyImageSet(ctx context.Context, c client.Client, v operator.ProductVariant, comps ...render.Component) error {
 	imageSet, err := GetImageSet(ctx, c, v)
 	if err != nil {
@@ -41,12 +56,32 @@ func ApplyImageSet(ctx context.Context, c client.Client, v operator.ProductVaria
 		return err
 	}
 
+	if err = VerifyDigests(ctx, c, imageSet); err != nil {
+		return err
+	}
+
 	return ResolveImages(imageSet, comps...)
 }
 
-// Utility function to add a watch on ImageSet resources.
+// Utility function to add a watch on ImageSet resources, and on the image digest allowlist that ApplyImageSet
+// verifies them against.
 func AddImageSetWatch(c controller.Controller) error {
-	return c.Watch(&source.Kind{Type: &operator.ImageSet{}}, &handler.EnqueueRequestForObject{})
+	if err := c.Watch(&source.Kind{Type: &operator.ImageSet{}}, &handler.EnqueueRequestForObject{}); err != nil {
+		return err
+	}
+	// A change to the allowlist is reconciled with an empty request rather than one for the ConfigMap, since some
+	// controllers tell the resources that they reconcile apart by the namespace of the request.
+	return AddDigestAllowlistWatch(c, handler.EnqueueRequestsFromMapFunc(func(client.Object) []reconcile.Request {
+		return []reconcile.Request{{}}
+	}))
+}
+
+// AddDigestAllowlistWatch adds a watch on the image digest allowlist ConfigMap, for controllers that call
+// ApplyImageSet without AddImageSetWatch.
+func AddDigestAllowlistWatch(c controller.Controller, h handler.EventHandler) error {
+	return c.Watch(&source.Kind{Type: &corev1.ConfigMap{}}, h, predicate.NewPredicateFuncs(func(object client.Object) bool {
+		return object.GetName() == DigestAllowlistConfigMapName && object.GetNamespace() == common.OperatorNamespace()
+	}))
 }
 
 func getSetName(v operator.ProductVariant) string {
@@ -147,6 +182,40 @@ func ValidateImageSet(is *operator.ImageSet) error {
 	return fmt.Errorf("ImageSet %s: %s", is.Name, strings.Join(errMsgs, "; "))
 }
 
+// VerifyDigests checks that every digest in the ImageSet is listed in the digest allowlist ConfigMap.
+// When there is no allowlist any digest is accepted. When there is an allowlist but no ImageSet, the
+// images aren't pinned to digests and can't be verified, so that is an error too.
+func VerifyDigests(ctx context.Context, c client.Client, is *operator.ImageSet) error {
+	cm := &corev1.ConfigMap{}
+	err := c.Get(ctx, client.ObjectKey{Name: DigestAllowlistConfigMapName, Namespace: common.OperatorNamespace()}, cm)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get the image digest allowlist: %w", err)
+	}
+
+	if is == nil {
+		return fmt.Errorf("image digest allowlist %s exists but there is no ImageSet to pin the images to digests", DigestAllowlistConfigMapName)
+	}
+
+	allowed := map[string]bool{}
+	for _, digest := range strings.Fields(cm.Data[DigestAllowlistKey]) {
+		allowed[digest] = true
+	}
+
+	rejected := []string{}
+	for _, img := range is.Spec.Images {
+		if !allowed[img.Digest] {
+			rejected = append(rejected, fmt.Sprintf("%s@%s", img.Image, img.Digest))
+		}
+	}
+	if len(rejected) != 0 {
+		return fmt.Errorf("ImageSet %s: digests not in the allowlist: %s", is.Name, strings.Join(rejected, ", "))
+	}
+	return nil
+}
+
 func ResolveImages(is *operator.ImageSet, comps ...render.Component) error {
 	errMsgs := []string{}
 	for _, comp := range comps {
@@ -23,6 +23,7 @@ import (
 	. "github.com/onsi/gomega"
 
 	//"k8s.io/client-go/kubernetes/fake"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	kscheme "k8s.io/client-go/kubernetes/scheme"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +31,7 @@ import (
 
 	operator "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 )
 
@@ -112,4 +114,54 @@ var _ = Describe("imageset tests", func() {
 			Entry("Enterprise variant", operator.TigeraSecureEnterprise),
 		)
 	})
+
+	Context("Test digest allowlist", func() {
+		var is *operator.ImageSet
+		BeforeEach(func() {
+			is = &operator.ImageSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: fmt.Sprintf("enterprise-%s", components.EnterpriseRelease),
+				},
+				Spec: operator.ImageSetSpec{
+					Images: []operator.Image{
+						{Image: "tigera/cni", Digest: "sha256:aaaaaaaaa"},
+						{Image: "tigera/typha", Digest: "sha256:bbbbbbbbb"},
+					},
+				},
+			}
+		})
+
+		allowlist := func(digests string) *corev1.ConfigMap {
+			return &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: DigestAllowlistConfigMapName, Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{DigestAllowlistKey: digests},
+			}
+		}
+
+		It("should accept an ImageSet whose digests are all allowlisted", func() {
+			c := fake.NewClientBuilder().WithScheme(kscheme.Scheme).WithObjects(
+				is, allowlist("sha256:aaaaaaaaa\nsha256:bbbbbbbbb\nsha256:ccccccccc"),
+			).Build()
+			Expect(ApplyImageSet(context.Background(), c, operator.TigeraSecureEnterprise)).To(BeNil())
+		})
+
+		It("should reject an ImageSet with a digest that is not allowlisted", func() {
+			c := fake.NewClientBuilder().WithScheme(kscheme.Scheme).WithObjects(
+				is, allowlist("sha256:aaaaaaaaa"),
+			).Build()
+			err := ApplyImageSet(context.Background(), c, operator.TigeraSecureEnterprise)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("digests not in the allowlist: tigera/typha@sha256:bbbbbbbbb"))
+			Expect(err.Error()).NotTo(ContainSubstring("tigera/cni"))
+		})
+
+		It("should reject images that aren't pinned by an ImageSet when there is an allowlist", func() {
+			c := fake.NewClientBuilder().WithScheme(kscheme.Scheme).WithObjects(
+				allowlist("sha256:aaaaaaaaa"),
+			).Build()
+			err := ApplyImageSet(context.Background(), c, operator.TigeraSecureEnterprise)
+			Expect(err).ToNot(BeNil())
+			Expect(err.Error()).To(ContainSubstring("no ImageSet"))
+		})
+	})
 })
@@ -44,6 +44,17 @@ func OverrideInstallationSpec(cfg, override operatorv1.InstallationSpec) operato
 		inst.Registry = override.Registry
 	}
 
+	switch compareFields(inst.RegistryMirrors, override.RegistryMirrors) {
+	case BOnlySet, Different:
+		inst.RegistryMirrors = make([]string, len(override.RegistryMirrors))
+		copy(inst.RegistryMirrors, override.RegistryMirrors)
+	}
+
+	switch compareFields(inst.PreferredRegistryMirror, override.PreferredRegistryMirror) {
+	case BOnlySet, Different:
+		inst.PreferredRegistryMirror = override.PreferredRegistryMirror
+	}
+
 	switch compareFields(inst.ImagePath, override.ImagePath) {
 	case BOnlySet, Different:
 		inst.ImagePath = override.ImagePath
@@ -78,6 +78,32 @@ var _ = Describe("Installation merge tests", func() {
 		Entry("Both set not matching", "private.registry.com", "other.registry.com", "other.registry.com"),
 	)
 
+	DescribeTable("merge RegistryMirrors", func(main, second, expect []string) {
+		m := opv1.InstallationSpec{RegistryMirrors: main}
+		s := opv1.InstallationSpec{RegistryMirrors: second}
+		inst := OverrideInstallationSpec(m, s)
+		Expect(inst.RegistryMirrors).To(Equal(expect))
+	},
+		Entry("Both unset", nil, nil, nil),
+		Entry("Main only set", []string{"mirror1.io/"}, nil, []string{"mirror1.io/"}),
+		Entry("Second only set", nil, []string{"mirror1.io/"}, []string{"mirror1.io/"}),
+		Entry("Both set equal", []string{"mirror1.io/"}, []string{"mirror1.io/"}, []string{"mirror1.io/"}),
+		Entry("Both set not matching", []string{"mirror1.io/"}, []string{"mirror2.io/", "mirror3.io/"}, []string{"mirror2.io/", "mirror3.io/"}),
+	)
+
+	DescribeTable("merge PreferredRegistryMirror", func(main, second, expect string) {
+		m := opv1.InstallationSpec{PreferredRegistryMirror: main}
+		s := opv1.InstallationSpec{PreferredRegistryMirror: second}
+		inst := OverrideInstallationSpec(m, s)
+		Expect(inst.PreferredRegistryMirror).To(Equal(expect))
+	},
+		Entry("Both unset", "", "", ""),
+		Entry("Main only set", "mirror1.io/", "", "mirror1.io/"),
+		Entry("Second only set", "", "mirror1.io/", "mirror1.io/"),
+		Entry("Both set equal", "mirror1.io/", "mirror1.io/", "mirror1.io/"),
+		Entry("Both set not matching", "mirror1.io/", "mirror2.io/", "mirror2.io/"),
+	)
+
 	DescribeTable("merge ImagePath", func(main, second, expect string) {
 		m := opv1.InstallationSpec{}
 		s := opv1.InstallationSpec{}
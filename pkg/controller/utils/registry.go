@@ -0,0 +1,29 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/components"
+)
+
+// ResolveRegistryMirror replaces the registry of the spec with its preferred mirror, if one is set. Mirrors only apply
+// to a registry that is set explicitly.
+func ResolveRegistryMirror(spec *operatorv1.InstallationSpec) {
+	if spec.Registry == "" || spec.Registry == components.UseDefault {
+		return
+	}
+	spec.Registry = components.SelectRegistry(spec.Registry, spec.RegistryMirrors, spec.PreferredRegistryMirror)
+}
@@ -0,0 +1,80 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	opv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/apis"
+)
+
+var _ = Describe("Registry mirror tests", func() {
+	var (
+		c   client.Client
+		ctx context.Context
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		ctx = context.Background()
+	})
+
+	createInstallation := func(registry, preferred string, mirrors ...string) {
+		Expect(c.Create(ctx, &opv1.Installation{
+			ObjectMeta: metav1.ObjectMeta{Name: "default"},
+			Spec:       opv1.InstallationSpec{Registry: registry, RegistryMirrors: mirrors, PreferredRegistryMirror: preferred},
+		})).NotTo(HaveOccurred())
+	}
+
+	It("should use the registry when there are no mirrors", func() {
+		createInstallation("primary.io/", "")
+		_, spec, err := GetInstallation(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Registry).To(Equal("primary.io/"))
+	})
+
+	It("should use the registry when no mirror is preferred", func() {
+		createInstallation("primary.io/", "", "mirror1.io/", "mirror2.io/")
+		_, spec, err := GetInstallation(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Registry).To(Equal("primary.io/"))
+	})
+
+	It("should use the preferred mirror", func() {
+		createInstallation("primary.io/", "mirror2.io/", "mirror1.io/", "mirror2.io/")
+		_, spec, err := GetInstallation(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Registry).To(Equal("mirror2.io/"))
+		Expect(spec.RegistryMirrors).To(Equal([]string{"mirror1.io/", "mirror2.io/"}))
+	})
+
+	It("should ignore the mirrors when the registry is not set", func() {
+		createInstallation("", "mirror1.io/", "mirror1.io/")
+		_, spec, err := GetInstallation(ctx, c)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(spec.Registry).To(BeEmpty())
+	})
+})
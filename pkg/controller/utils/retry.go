@@ -0,0 +1,37 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+)
+
+// IsTransientError returns true if the given client error is expected to resolve itself without any change to
+// the inputs of the request, such as a conflict or a server side timeout.
+func IsTransientError(err error) bool {
+	return apierrors.IsConflict(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err)
+}
+
+// RetryOnTransientError calls fn until it succeeds, returns an error that is not transient, or the backoff is
+// exhausted. The last error from fn is returned. Since a conflict means the object has changed on the server,
+// fn is responsible for re-reading any object it writes before retrying.
+func RetryOnTransientError(fn func() error) error {
+	return retry.OnError(retry.DefaultBackoff, IsTransientError, fn)
+}
@@ -0,0 +1,66 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package utils
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("RetryOnTransientError", func() {
+	gr := schema.GroupResource{Group: "operator.tigera.io", Resource: "intrusiondetections"}
+
+	It("should retry conflicts and server timeouts until the call succeeds", func() {
+		errs := []error{
+			apierrors.NewConflict(gr, "tigera-secure", fmt.Errorf("object has been modified")),
+			apierrors.NewServerTimeout(gr, "update", 1),
+		}
+		calls := 0
+		err := RetryOnTransientError(func() error {
+			calls++
+			if calls <= len(errs) {
+				return errs[calls-1]
+			}
+			return nil
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(calls).To(Equal(3))
+	})
+
+	It("should not retry permanent errors", func() {
+		calls := 0
+		err := RetryOnTransientError(func() error {
+			calls++
+			return apierrors.NewNotFound(gr, "tigera-secure")
+		})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(calls).To(Equal(1))
+	})
+
+	It("should return the last transient error once the backoff is exhausted", func() {
+		calls := 0
+		err := RetryOnTransientError(func() error {
+			calls++
+			return apierrors.NewConflict(gr, "tigera-secure", fmt.Errorf("object has been modified"))
+		})
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(calls).To(BeNumerically(">", 1))
+	})
+})
@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"reflect"
 	"sort"
 	"strings"
 	"time"
@@ -28,6 +29,7 @@ import (
 	esv1 "github.com/elastic/cloud-on-k8s/v2/pkg/apis/elasticsearch/v1"
 
 	"github.com/go-logr/logr"
+	"golang.org/x/net/http/httpproxy"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	v1 "k8s.io/api/core/v1"
@@ -60,6 +62,19 @@ const (
 	// This is for development and testing purposes only. Do not use this annotation
 	// for production, as this will cause problems with upgrade.
 	unsupportedIgnoreAnnotation = "unsupported.operator.tigera.io/ignore"
+
+	// ProxyConfigMapName is the name of the ConfigMap in the operator namespace that holds the HTTP proxy settings,
+	// under the HTTP_PROXY, HTTPS_PROXY and NO_PROXY keys, for components that need to reach external services.
+	ProxyConfigMapName = "tigera-operator-proxy"
+
+	// PauseAnnotation can be set to "true" on a resource to stop the operator from reconciling the objects it manages,
+	// e.g. so that they can be edited by hand during an incident. Removing the annotation resumes reconciliation.
+	PauseAnnotation = "operator.tigera.io/pause"
+
+	// MaintenanceConfigMapName is the name of the ConfigMap in the operator namespace used to signal a fleet-wide
+	// maintenance window. While its "active" key is set to "true", controllers that honor it stop applying changes.
+	MaintenanceConfigMapName = "tigera-operator-maintenance"
+	maintenanceActiveKey     = "active"
 )
 
 var (
@@ -93,6 +108,11 @@ func IgnoreObject(obj runtime.Object) bool {
 	return false
 }
 
+// IsPaused returns true if the given object has the pause annotation set to "true".
+func IsPaused(obj metav1.Object) bool {
+	return obj.GetAnnotations()[PauseAnnotation] == "true"
+}
+
 func AddInstallationWatch(c controller.Controller) error {
 	return c.Watch(&source.Kind{Type: &operatorv1.Installation{}}, &handler.EnqueueRequestForObject{})
 }
@@ -196,6 +216,33 @@ func WaitToAddLicenseKeyWatch(controller controller.Controller, c kubernetes.Int
 	WaitToAddResourceWatch(controller, c, log, flag, []client.Object{&v3.LicenseKey{TypeMeta: metav1.TypeMeta{Kind: v3.KindLicenseKey}}})
 }
 
+// WaitToAddLicenseKeyWatchWithHandler is like WaitToAddLicenseKeyWatch, but enqueues requests with the given handler.
+// Requests are also enqueued when the features of the license change, which are reported in its status and so don't
+// change its generation.
+func WaitToAddLicenseKeyWatchWithHandler(controller controller.Controller, c kubernetes.Interface, log logr.Logger, flag *ReadyFlag, h handler.EventHandler) {
+	WaitToAddResourceWatchWithHandler(controller, c, log, flag, []client.Object{&v3.LicenseKey{TypeMeta: metav1.TypeMeta{Kind: v3.KindLicenseKey}}}, h, LicenseFeaturesChangedPredicate())
+}
+
+// LicenseFeaturesChangedPredicate matches the updates of a LicenseKey that change its features.
+func LicenseFeaturesChangedPredicate() predicate.Predicate {
+	return predicate.Funcs{
+		CreateFunc:  func(event.CreateEvent) bool { return false },
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldLicense, ok := e.ObjectOld.(*v3.LicenseKey)
+			if !ok {
+				return false
+			}
+			newLicense, ok := e.ObjectNew.(*v3.LicenseKey)
+			if !ok {
+				return false
+			}
+			return !reflect.DeepEqual(oldLicense.Status.Features, newLicense.Status.Features)
+		},
+	}
+}
+
 func WaitToAddPolicyRecommendationScopeWatch(controller controller.Controller, c kubernetes.Interface, log logr.Logger, flag *ReadyFlag) {
 	WaitToAddResourceWatch(controller, c, log, flag, []client.Object{&v3.PolicyRecommendationScope{TypeMeta: metav1.TypeMeta{Kind: v3.KindPolicyRecommendationScope}}})
 }
@@ -349,6 +396,38 @@ func GetK8sServiceEndPoint(client client.Client) (*corev1.ConfigMap, error) {
 	return cm, nil
 }
 
+// IsMaintenanceActive returns true if the maintenance ConfigMap exists in the operator namespace and marks the
+// maintenance window as active.
+func IsMaintenanceActive(ctx context.Context, cli client.Client) (bool, error) {
+	cm := &corev1.ConfigMap{}
+	err := cli.Get(ctx, types.NamespacedName{Name: MaintenanceConfigMapName, Namespace: common.OperatorNamespace()}, cm)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read ConfigMap %q: %w", MaintenanceConfigMapName, err)
+	}
+	return strings.EqualFold(cm.Data[maintenanceActiveKey], "true"), nil
+}
+
+// GetProxyConfig returns the HTTP proxy settings from the proxy ConfigMap in the operator namespace, or nil if the
+// ConfigMap doesn't exist.
+func GetProxyConfig(ctx context.Context, cli client.Client) (*httpproxy.Config, error) {
+	cm := &corev1.ConfigMap{}
+	err := cli.Get(ctx, types.NamespacedName{Name: ProxyConfigMapName, Namespace: common.OperatorNamespace()}, cm)
+	if err != nil {
+		if kerrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read ConfigMap %q: %w", ProxyConfigMapName, err)
+	}
+	return &httpproxy.Config{
+		HTTPProxy:  cm.Data["HTTP_PROXY"],
+		HTTPSProxy: cm.Data["HTTPS_PROXY"],
+		NoProxy:    cm.Data["NO_PROXY"],
+	}, nil
+}
+
 // PopulateK8sServiceEndPoint reads the kubernetes-service-endpoint configmap and pushes
 // KUBERNETES_SERVICE_HOST, KUBERNETES_SERVICE_PORT to calico-node daemonset, typha
 // apiserver deployments
@@ -501,6 +580,7 @@ func GetInstallation(ctx context.Context, client client.Client) (operatorv1.Prod
 	} else {
 		spec = OverrideInstallationSpec(spec, overlay.Spec)
 	}
+	ResolveRegistryMirror(&spec)
 
 	return instance.Status.Variant, &spec, nil
 }
@@ -566,6 +646,12 @@ type resourceWatchContext struct {
 // WaitToAddResourceWatch will check if projectcalico.org APIs are available and if so, it will add a watch for resource
 // The completion of this operation will be signaled on a ready channel
 func WaitToAddResourceWatch(controller controller.Controller, c kubernetes.Interface, log logr.Logger, flag *ReadyFlag, objs []client.Object) {
+	WaitToAddResourceWatchWithHandler(controller, c, log, flag, objs, &handler.EnqueueRequestForObject{})
+}
+
+// WaitToAddResourceWatchWithHandler is like WaitToAddResourceWatch, but enqueues requests with the given handler.
+// Events that match any of the extra predicates are enqueued as well.
+func WaitToAddResourceWatchWithHandler(controller controller.Controller, c kubernetes.Interface, log logr.Logger, flag *ReadyFlag, objs []client.Object, h handler.EventHandler, extra ...predicate.Predicate) {
 	// Track resources left to watch and establish their watch context.
 	resourcesToWatch := map[client.Object]resourceWatchContext{}
 	for _, obj := range objs {
@@ -589,6 +675,9 @@ func WaitToAddResourceWatch(controller controller.Controller, c kubernetes.Inter
 		for obj := range resourcesToWatch {
 			objLog := resourcesToWatch[obj].logger
 			predicateFn := resourcesToWatch[obj].predicate
+			if len(extra) > 0 {
+				predicateFn = predicate.Or(append([]predicate.Predicate{predicateFn}, extra...)...)
+			}
 			if ok, err := isCalicoResourceReady(c, obj.GetObjectKind().GroupVersionKind().Kind); err != nil {
 				msg := "Failed to check if resource is ready - will retry"
 				if errors.IsNotFound(err) {
@@ -598,7 +687,7 @@ func WaitToAddResourceWatch(controller controller.Controller, c kubernetes.Inter
 				}
 			} else if !ok {
 				objLog.Info("Waiting for resource to be ready - will retry")
-			} else if err := controller.Watch(&source.Kind{Type: obj}, &handler.EnqueueRequestForObject{}, predicateFn); err != nil {
+			} else if err := controller.Watch(&source.Kind{Type: obj}, h, predicateFn); err != nil {
 				objLog.WithValues("Error", err).Info("Failed to watch resource - will retry")
 			} else {
 				objLog.V(2).Info("Successfully watching resource")
@@ -432,3 +432,17 @@ var _ = Describe("CreatePredicateForObject", func() {
 		})
 	})
 })
+
+var _ = Describe("LicenseFeaturesChangedPredicate", func() {
+	It("should only match updates that change the features of the license", func() {
+		p := LicenseFeaturesChangedPredicate()
+		withFeatures := func(generation int64, features ...string) *v3.LicenseKey {
+			return &v3.LicenseKey{ObjectMeta: metav1.ObjectMeta{Name: "default", Generation: generation}, Status: v3.LicenseKeyStatus{Features: features}}
+		}
+		Expect(p.Update(event.UpdateEvent{ObjectOld: withFeatures(1), ObjectNew: withFeatures(1, common.ThreatDefenseFeature)})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: withFeatures(1, common.ThreatDefenseFeature), ObjectNew: withFeatures(1)})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: withFeatures(1, common.ThreatDefenseFeature), ObjectNew: withFeatures(2, common.ThreatDefenseFeature)})).To(BeFalse())
+		Expect(p.Create(event.CreateEvent{Object: withFeatures(1, common.ThreatDefenseFeature)})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: withFeatures(1, common.ThreatDefenseFeature)})).To(BeFalse())
+	})
+})
@@ -16,7 +16,9 @@ package elasticsearch
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
+	"strings"
 
 	"github.com/pkg/errors"
 	corev1 "k8s.io/api/core/v1"
@@ -28,14 +30,40 @@ import (
 
 const (
 	ClusterConfigConfigMapName = "tigera-secure-elasticsearch"
+
+	// maxIndexPrefixLength leaves room in the 255 byte limit of Elasticsearch index names for the rest of the name.
+	maxIndexPrefixLength = 100
 )
 
-func NewClusterConfig(clusterName string, replicas int, shards int, flowShards int) *ClusterConfig {
+// indexPrefixRegexp matches the index prefixes that give valid Elasticsearch index names, which must be lowercase and
+// can't start with '-', '_' or '+'.
+var indexPrefixRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]*$`)
+
+// ValidateIndexPrefix returns an error if the prefix can't be prepended to the name of an Elasticsearch index. An empty
+// prefix is valid.
+func ValidateIndexPrefix(prefix string) error {
+	if prefix == "" {
+		return nil
+	}
+	if len(prefix) > maxIndexPrefixLength {
+		return fmt.Errorf("index prefix %q is longer than %d characters", prefix, maxIndexPrefixLength)
+	}
+	if !indexPrefixRegexp.MatchString(prefix) {
+		return fmt.Errorf("index prefix %q must start with a lowercase letter or digit and only contain lowercase letters, digits, '.', '_' and '-'", prefix)
+	}
+	return nil
+}
+
+// NewClusterConfig returns the configuration of the Elasticsearch cluster. The optional awareness attributes are the
+// names of the node attributes Elasticsearch uses for shard allocation awareness, so that the copies of a shard are
+// spread across failure domains.
+func NewClusterConfig(clusterName string, replicas int, shards int, flowShards int, awarenessAttributes ...string) *ClusterConfig {
 	return &ClusterConfig{
-		clusterName: clusterName,
-		replicas:    replicas,
-		shards:      shards,
-		flowShards:  flowShards,
+		clusterName:         clusterName,
+		replicas:            replicas,
+		shards:              shards,
+		flowShards:          flowShards,
+		awarenessAttributes: awarenessAttributes,
 	}
 }
 
@@ -71,14 +99,57 @@ func NewClusterConfigFromConfigMap(configMap *corev1.ConfigMap) (*ClusterConfig,
 		}
 	}
 
-	return NewClusterConfig(configMap.Data["clusterName"], replicas, shards, flowShards), nil
+	var awarenessAttributes []string
+	if configMap.Data["awarenessAttributes"] != "" {
+		awarenessAttributes = strings.Split(configMap.Data["awarenessAttributes"], ",")
+	}
+
+	var totalFieldsLimit, depthLimit int
+	if configMap.Data["totalFieldsLimit"] != "" {
+		if totalFieldsLimit, err = strconv.Atoi(configMap.Data["totalFieldsLimit"]); err != nil {
+			return nil, errors.Wrap(err, "'totalFieldsLimit' must be an integer")
+		}
+	}
+	if configMap.Data["depthLimit"] != "" {
+		if depthLimit, err = strconv.Atoi(configMap.Data["depthLimit"]); err != nil {
+			return nil, errors.Wrap(err, "'depthLimit' must be an integer")
+		}
+	}
+
+	indexPrefix := configMap.Data["indexPrefix"]
+	if err := ValidateIndexPrefix(indexPrefix); err != nil {
+		return nil, errors.Wrap(err, "'indexPrefix' is invalid")
+	}
+
+	return NewClusterConfig(configMap.Data["clusterName"], replicas, shards, flowShards, awarenessAttributes...).
+		WithIndexMappingLimits(totalFieldsLimit, depthLimit).
+		WithIndexPrefix(indexPrefix), nil
 }
 
 type ClusterConfig struct {
-	clusterName string
-	replicas    int
-	shards      int
-	flowShards  int
+	clusterName         string
+	replicas            int
+	shards              int
+	flowShards          int
+	awarenessAttributes []string
+	totalFieldsLimit    int
+	depthLimit          int
+	indexPrefix         string
+}
+
+// WithIndexMappingLimits sets the index.mapping.total_fields.limit and index.mapping.depth.limit settings that the
+// installers apply to the indices they create. Zero keeps the Elasticsearch default.
+func (c *ClusterConfig) WithIndexMappingLimits(totalFields int, depth int) *ClusterConfig {
+	c.totalFieldsLimit = totalFields
+	c.depthLimit = depth
+	return c
+}
+
+// WithIndexPrefix sets the prefix that the installers prepend to the names of the indices they create. An empty prefix
+// keeps the default index names.
+func (c *ClusterConfig) WithIndexPrefix(prefix string) *ClusterConfig {
+	c.indexPrefix = prefix
+	return c
 }
 
 func (c ClusterConfig) ClusterName() string {
@@ -97,12 +168,28 @@ func (c ClusterConfig) FlowShards() int {
 	return c.flowShards
 }
 
+func (c ClusterConfig) AwarenessAttributes() []string {
+	return c.awarenessAttributes
+}
+
+func (c ClusterConfig) TotalFieldsLimit() int {
+	return c.totalFieldsLimit
+}
+
+func (c ClusterConfig) DepthLimit() int {
+	return c.depthLimit
+}
+
+func (c ClusterConfig) IndexPrefix() string {
+	return c.indexPrefix
+}
+
 func (c ClusterConfig) Annotation() string {
 	return rmeta.AnnotationHash(c)
 }
 
 func (c ClusterConfig) ConfigMap() *corev1.ConfigMap {
-	return &corev1.ConfigMap{
+	cm := &corev1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      ClusterConfigConfigMapName,
 			Namespace: common.OperatorNamespace(),
@@ -114,4 +201,17 @@ func (c ClusterConfig) ConfigMap() *corev1.ConfigMap {
 			"flowShards":  strconv.Itoa(c.flowShards),
 		},
 	}
+	if len(c.awarenessAttributes) > 0 {
+		cm.Data["awarenessAttributes"] = strings.Join(c.awarenessAttributes, ",")
+	}
+	if c.totalFieldsLimit > 0 {
+		cm.Data["totalFieldsLimit"] = strconv.Itoa(c.totalFieldsLimit)
+	}
+	if c.depthLimit > 0 {
+		cm.Data["depthLimit"] = strconv.Itoa(c.depthLimit)
+	}
+	if c.indexPrefix != "" {
+		cm.Data["indexPrefix"] = c.indexPrefix
+	}
+	return cm
 }
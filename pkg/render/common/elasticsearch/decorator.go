@@ -77,6 +77,15 @@ func ContainerDecorateIndexCreator(c corev1.Container, replicas, shards int) cor
 	return c
 }
 
+// ContainerDecorateIndexPrefix tells the container the prefix of the index names, so that it reads and writes the same
+// indices as the other components. An empty prefix keeps the default index names.
+func ContainerDecorateIndexPrefix(c corev1.Container, prefix string) corev1.Container {
+	if prefix != "" {
+		c.Env = append(c.Env, corev1.EnvVar{Name: "ELASTIC_INDEX_PREFIX", Value: prefix})
+	}
+	return c
+}
+
 func DecorateEnvironment(c corev1.Container, namespace string, cluster, esUserSecretName, clusterDomain string, osType rmeta.OSType) corev1.Container {
 	certPath := elasticCertPath(osType)
 	esScheme, esHost, esPort, _ := url.ParseEndpoint(GatewayEndpoint(osType, clusterDomain, namespace))
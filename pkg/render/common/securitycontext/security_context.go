@@ -87,3 +87,13 @@ func NewNonRootPodContext() *corev1.PodSecurityContext {
 		},
 	}
 }
+
+// NewRuntimeDefaultPodContext returns a pod security context that only sets the RuntimeDefault seccomp profile, for pods
+// that have containers running as different users.
+func NewRuntimeDefaultPodContext() *corev1.PodSecurityContext {
+	return &corev1.PodSecurityContext{
+		SeccompProfile: &corev1.SeccompProfile{
+			Type: corev1.SeccompProfileTypeRuntimeDefault,
+		},
+	}
+}
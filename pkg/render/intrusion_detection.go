@@ -17,22 +17,28 @@ package render
 import (
 	"crypto/x509"
 	"fmt"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/tigera/operator/pkg/ptr"
+	"golang.org/x/net/http/httpproxy"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	policyv1beta1 "k8s.io/api/policy/v1beta1"
 	rbacv1 "k8s.io/api/rbac/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/render/common/configmap"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rkibana "github.com/tigera/operator/pkg/render/common/kibana"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
@@ -58,12 +64,42 @@ const (
 	IntrusionDetectionControllerPolicyName = networkpolicy.TigeraComponentPolicyPrefix + IntrusionDetectionControllerName
 	IntrusionDetectionInstallerPolicyName  = networkpolicy.TigeraComponentPolicyPrefix + "intrusion-detection-elastic"
 
+	// DetectionRulesMountPath is the directory under which each additional detection rule ConfigMap is mounted.
+	DetectionRulesMountPath = "/etc/tigera/detection-rules"
+
+	// CopiedConfigMapLabel is the label of the ConfigMaps that are copied from the operator namespace into the
+	// intrusion detection namespace, so that the copies that are no longer referenced can be found and deleted.
+	CopiedConfigMapLabel = "operator.tigera.io/intrusion-detection-copy"
+
+	// InstallerCABundlesMountPath is the directory under which each additional CA bundle of the installer is mounted.
+	InstallerCABundlesMountPath = "/etc/pki/installer-ca-bundles"
+
+	// FindingsWebhookAuthMountPath is the directory the Authorization header of the findings webhook is mounted in.
+	FindingsWebhookAuthMountPath  = "/etc/tigera/findings-webhook"
+	findingsWebhookAuthVolumeName = "findings-webhook-auth"
+	findingsWebhookAuthFileName   = "authorization"
+
+	// DefaultInstallerJobTTLSecondsAfterFinished is how long a finished installer Job is kept by default.
+	DefaultInstallerJobTTLSecondsAfterFinished int32 = 24 * 60 * 60
+
+	// InstallerJobHashAnnotation is set on the installer Job to the hash of its pod template, so that the controller can
+	// tell which configuration a completed Job ran with.
+	InstallerJobHashAnnotation = "hash.operator.tigera.io/installer-job"
+
+	// InstallerJobFinalizer holds a deleted installer Job back until the controller has seen whether it completed, so
+	// that a Job whose TTL deletes it right after it completes is not run again.
+	InstallerJobFinalizer = "operator.tigera.io/installer-job-completion"
+
 	ADAPIObjectName                 = "anomaly-detection-api"
 	ADAPIPodSecurityPolicyName      = "anomaly-detection-api"
 	IntrusionDetectionTLSSecretName = "intrusion-detection-tls"
 	DPITLSSecretName                = "deep-packet-inspection-tls"
 	ADAPIPolicyName                 = networkpolicy.TigeraComponentPolicyPrefix + ADAPIObjectName
 
+	// IntrusionDetectionMetricsTLSSecretName is the name of the secret containing the key pair the
+	// intrusion-detection-controller serves its metrics endpoint with, when ControllerMetricsTLS is set.
+	IntrusionDetectionMetricsTLSSecretName = "intrusion-detection-metrics-tls"
+
 	ADPersistentVolumeClaimName = "tigera-anomaly-detection"
 	ADJobPodTemplateBaseName    = "tigera.io.detectors"
 	adDetectorPrefixName        = "tigera.io.detector."
@@ -93,7 +129,8 @@ var adAlgorithms = []string{
 
 // Register secret/certs that need Server and Client Key usage
 var (
-	intrusionDetectionNamespaceSelector = fmt.Sprintf("projectcalico.org/name == '%s'", IntrusionDetectionNamespace)
+	// The selector matches the namespaces of additional IntrusionDetections as well as the default one.
+	intrusionDetectionNamespaceSelector = fmt.Sprintf("projectcalico.org/name == '%s' || projectcalico.org/name starts with '%s-'", IntrusionDetectionNamespace, IntrusionDetectionNamespace)
 	IntrusionDetectionSourceEntityRule  = v3.EntityRule{
 		NamespaceSelector: intrusionDetectionNamespaceSelector,
 		Selector:          fmt.Sprintf("k8s-app == '%s'", IntrusionDetectionControllerName),
@@ -108,6 +145,7 @@ var IntrusionDetectionInstallerSourceEntityRule = v3.EntityRule{
 // Register secret/certs that need Server and Client Key usage
 func init() {
 	certkeyusage.SetCertKeyUsage(DPITLSSecretName, []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth})
+	certkeyusage.SetCertKeyUsage(IntrusionDetectionMetricsTLSSecretName, []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth})
 }
 
 func IntrusionDetection(cfg *IntrusionDetectionConfiguration) Component {
@@ -134,8 +172,51 @@ type IntrusionDetectionConfiguration struct {
 	TrustedCertBundle            certificatemanagement.TrustedBundle
 	IntrusionDetectionCertSecret certificatemanagement.KeyPairInterface
 
+	// MetricsKeyPair is the key pair the intrusion-detection-controller serves its metrics endpoint with, or nil when
+	// the metrics are served in cleartext.
+	MetricsKeyPair certificatemanagement.KeyPairInterface
+
+	// ElasticExternal is whether the cluster uses an Elasticsearch that is managed outside of the operator, whose
+	// indices the installer doesn't set up.
+	ElasticExternal bool
+
+	// CompletedInstallerJobHash is the hash of the pod template of an installer Job that has completed and has since
+	// been deleted, e.g. by its TTL. An installer Job with the same hash is not rendered, so that it isn't recreated
+	// and the installer doesn't run again until its configuration changes.
+	CompletedInstallerJobHash string
+
 	// Whether the cluster supports pod security policies.
 	UsePSP bool
+
+	// ProxyConfig holds the HTTP proxy settings for the intrusion detection pods, or nil if no proxy is configured.
+	ProxyConfig *httpproxy.Config
+
+	// Namespace is the namespace the intrusion detection components are rendered into. Defaults to
+	// IntrusionDetectionNamespace. The controller reads, watches and cleans up the components in the same namespace.
+	Namespace string
+
+	// Instance is the name of an additional IntrusionDetection, or empty for the default one. An additional
+	// IntrusionDetection only renders its own namespaced objects, along with bindings to the cluster wide objects
+	// that are rendered for the default one.
+	Instance string
+
+	// DetectionRuleConfigMaps are the detection rule ConfigMaps from the operator namespace that are copied into the
+	// intrusion detection namespace. The ones that are named in the IntrusionDetection but not listed here are
+	// expected to exist in the intrusion detection namespace already.
+	DetectionRuleConfigMaps []*corev1.ConfigMap
+
+	// InstallerCABundles are the CA bundle ConfigMaps from the operator namespace that are copied into the intrusion
+	// detection namespace and trusted by the installer, in the order of spec.installerCABundles.
+	InstallerCABundles []*corev1.ConfigMap
+
+	// FindingsWebhookAuthSecret is the Secret from the operator namespace that holds the Authorization header of
+	// spec.findingsWebhook, which is copied into the intrusion detection namespace, or nil when the webhook doesn't use
+	// one.
+	FindingsWebhookAuthSecret *corev1.Secret
+
+	// CopiedConfigMaps are the names of the ConfigMaps in the intrusion detection namespace that have
+	// CopiedConfigMapLabel. The ones that are no longer rendered are deleted.
+	CopiedConfigMaps []string
 }
 
 type intrusionDetectionComponent struct {
@@ -149,21 +230,23 @@ func (c *intrusionDetectionComponent) ResolveImages(is *operatorv1.ImageSet) err
 	reg := c.cfg.Installation.Registry
 	path := c.cfg.Installation.ImagePath
 	prefix := c.cfg.Installation.ImagePrefix
+	overrides := c.cfg.IntrusionDetection.Spec.ImageOverrides
+	remappings := c.cfg.IntrusionDetection.Spec.ImagePathRemappings
 	var errMsgs []string
 	var err error
 	if !c.cfg.ManagedCluster {
-		c.jobInstallerImage, err = components.GetReference(components.ComponentElasticTseeInstaller, reg, path, prefix, is)
+		c.jobInstallerImage, err = components.GetReferenceWithRemappings(components.ComponentElasticTseeInstaller, reg, path, prefix, is, overrides, remappings)
 		if err != nil {
 			errMsgs = append(errMsgs, err.Error())
 		}
 	}
 
-	c.controllerImage, err = components.GetReference(components.ComponentIntrusionDetectionController, reg, path, prefix, is)
+	c.controllerImage, err = components.GetReferenceWithRemappings(components.ComponentIntrusionDetectionController, reg, path, prefix, is, overrides, remappings)
 	if err != nil {
 		errMsgs = append(errMsgs, err.Error())
 	}
 
-	c.webhooksProcessorImage, err = components.GetReference(components.ComponentSecurityEventWebhooksProcessor, reg, path, prefix, is)
+	c.webhooksProcessorImage, err = components.GetReferenceWithRemappings(components.ComponentSecurityEventWebhooksProcessor, reg, path, prefix, is, overrides, remappings)
 	if err != nil {
 		errMsgs = append(errMsgs, err.Error())
 	}
@@ -174,68 +257,119 @@ func (c *intrusionDetectionComponent) ResolveImages(is *operatorv1.ImageSet) err
 	return nil
 }
 
+// IntrusionDetectionInstanceNamespace returns the namespace that the components of the additional IntrusionDetection
+// with the given name are installed into.
+func IntrusionDetectionInstanceNamespace(instance string) string {
+	return fmt.Sprintf("%s-%s", IntrusionDetectionNamespace, instance)
+}
+
+// instanceName returns the name of a cluster wide object that is rendered for each IntrusionDetection, so that the
+// objects of additional IntrusionDetections don't collide with those of the default one.
+func (c *intrusionDetectionComponent) instanceName(name string) string {
+	if c.cfg.Instance == "" {
+		return name
+	}
+	return fmt.Sprintf("%s-%s", name, c.cfg.Instance)
+}
+
+// namespace returns the namespace the intrusion detection components are rendered into.
+func (c *intrusionDetectionComponent) namespace() string {
+	if c.cfg.Namespace != "" {
+		return c.cfg.Namespace
+	}
+	return IntrusionDetectionNamespace
+}
+
 func (c *intrusionDetectionComponent) SupportedOSType() rmeta.OSType {
 	return rmeta.OSTypeLinux
 }
 
 func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Object) {
-	// Configure pod security standard. If syslog forwarding is enabled, we
-	// need hostpath volumes which require a privileged PSS.
-	pss := PSSRestricted
-	if c.syslogForwardingIsEnabled() {
-		pss = PSSPrivileged
-	}
-
 	objs := []client.Object{
-		CreateNamespace(IntrusionDetectionNamespace, c.cfg.Installation.KubernetesProvider, PodSecurityStandard(pss)),
+		CreateNamespace(c.namespace(), c.cfg.Installation.KubernetesProvider, c.podSecurityStandard()),
 		c.intrusionDetectionControllerAllowTigeraPolicy(),
-		networkpolicy.AllowTigeraDefaultDeny(IntrusionDetectionNamespace),
+		networkpolicy.AllowTigeraDefaultDeny(c.namespace()),
 	}
-	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(IntrusionDetectionNamespace, c.cfg.PullSecrets...)...)...)
+	objs = append(objs, c.copySecrets(c.cfg.PullSecrets...)...)
 
+	var objsToDelete []client.Object
+
+	// The operator's own ServiceAccounts are replaced by any that the user provides.
+	if c.controllerServiceAccountName() == IntrusionDetectionName {
+		objs = append(objs, c.intrusionDetectionServiceAccount())
+	} else {
+		objsToDelete = append(objsToDelete, c.intrusionDetectionServiceAccount())
+	}
+	if c.installerServiceAccountName() == IntrusionDetectionInstallerJobName {
+		objs = append(objs, c.intrusionDetectionJobServiceAccount())
+	} else {
+		objsToDelete = append(objsToDelete, c.intrusionDetectionJobServiceAccount())
+	}
+
+	if c.cfg.Instance == "" {
+		objs = append(objs, c.intrusionDetectionClusterRole())
+	}
+	// The CSR ClusterRoleBinding that certificate management renders is named after the ServiceAccount alone, so it
+	// is only rendered there for the default IntrusionDetection. An additional one binds its ServiceAccount here
+	// instead, under a name of its own.
+	if c.cfg.Instance != "" {
+		if c.cfg.IntrusionDetectionCertSecret != nil && c.cfg.IntrusionDetectionCertSecret.UseCertificateManagement() {
+			objs = append(objs, c.intrusionDetectionCSRClusterRoleBinding())
+		} else {
+			objsToDelete = append(objsToDelete, c.intrusionDetectionCSRClusterRoleBinding())
+		}
+	}
 	objs = append(objs,
-		c.intrusionDetectionServiceAccount(),
-		c.intrusionDetectionJobServiceAccount(),
-		c.intrusionDetectionClusterRole(),
 		c.intrusionDetectionClusterRoleBinding(),
 		c.intrusionDetectionRole(),
 		c.intrusionDetectionRoleBinding(),
 		c.intrusionDetectionDeployment(),
 	)
+	if c.podDisruptionBudgetEnabled() {
+		objs = append(objs, c.intrusionDetectionPodDisruptionBudget())
+	} else {
+		objsToDelete = append(objsToDelete, c.intrusionDetectionPodDisruptionBudget())
+	}
 
-	objs = append(objs, secret.ToRuntimeObjects(secret.CopyToNamespace(IntrusionDetectionNamespace, c.cfg.ESSecrets...)...)...)
-	objs = append(objs, c.globalAlertTemplates()...)
-
-	var objsToDelete []client.Object
+	objs = append(objs, c.copySecrets(c.cfg.ESSecrets...)...)
+	if c.cfg.FindingsWebhookAuthSecret != nil {
+		objs = append(objs, c.copySecrets(c.cfg.FindingsWebhookAuthSecret)...)
+	}
+	objs = append(objs, c.copyConfigMaps(c.cfg.DetectionRuleConfigMaps...)...)
+	if c.cfg.Instance == "" {
+		objs = append(objs, c.globalAlertTemplates()...)
+	}
 
 	// Anomaly Detection is now EoL; delete all of the GlobalAlertTemplates and corresponding
-	// GlobalAlerts that might exist.
-	for _, alg := range adAlgorithms {
-		objsToDelete = append(objsToDelete,
-			&v3.GlobalAlertTemplate{
-				TypeMeta: metav1.TypeMeta{
-					Kind:       "GlobalAlertTemplate",
-					APIVersion: "projectcalico.org/v3",
-				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name: adDetectorPrefixName + alg,
-				},
-			},
-			&v3.GlobalAlert{
-				TypeMeta: metav1.TypeMeta{
-					Kind:       "GlobalAlert",
-					APIVersion: "projectcalico.org/v3",
+	// GlobalAlerts that might exist. They were only ever created for the default IntrusionDetection.
+	if c.cfg.Instance == "" {
+		for _, alg := range adAlgorithms {
+			objsToDelete = append(objsToDelete,
+				&v3.GlobalAlertTemplate{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "GlobalAlertTemplate",
+						APIVersion: "projectcalico.org/v3",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: adDetectorPrefixName + alg,
+					},
 				},
-				ObjectMeta: metav1.ObjectMeta{
-					Name: adDetectorPrefixName + alg,
+				&v3.GlobalAlert{
+					TypeMeta: metav1.TypeMeta{
+						Kind:       "GlobalAlert",
+						APIVersion: "projectcalico.org/v3",
+					},
+					ObjectMeta: metav1.ObjectMeta{
+						Name: adDetectorPrefixName + alg,
+					},
 				},
-			},
-		)
+			)
+		}
 	}
 
 	// AD Related deployment only for management/standalone cluster
 	// When FIPS mode is enabled, we currently disable our python based images.
-	if !c.cfg.ManagedCluster {
+	if !c.cfg.ManagedCluster && c.cfg.Instance == "" {
 		var adObjs []client.Object
 
 		// Service + Deployment + RBAC for AD API
@@ -273,14 +407,29 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 		objsToDelete = append(objsToDelete, adObjs...)
 	}
 
-	// When FIPS mode is enabled, we currently disable our python based images.
+	// When FIPS mode is enabled, we currently disable our python based images. The installer job is also skipped when
+	// the user manages the Elasticsearch indices themselves, either explicitly or by using an external Elasticsearch.
 	if !c.cfg.ManagedCluster {
-		idsObjs := []client.Object{
-			c.intrusionDetectionElasticsearchAllowTigeraPolicy(),
-			c.intrusionDetectionElasticsearchJob(),
+		idsObjs := []client.Object{c.intrusionDetectionElasticsearchAllowTigeraPolicy()}
+		idsObjs = append(idsObjs, c.copyConfigMaps(c.uniqueInstallerCABundles()...)...)
+		// The installer runs either once as a Job or periodically as a CronJob, and the other one is removed.
+		if c.cfg.IntrusionDetection.Spec.InstallerSchedule != "" {
+			idsObjs = append(idsObjs, c.intrusionDetectionElasticsearchCronJob())
+			objsToDelete = append(objsToDelete, c.intrusionDetectionElasticsearchJob())
+		} else {
+			if job := c.intrusionDetectionElasticsearchJob(); job.Annotations[InstallerJobHashAnnotation] != c.cfg.CompletedInstallerJobHash {
+				idsObjs = append(idsObjs, job)
+			}
+			objsToDelete = append(objsToDelete, c.intrusionDetectionElasticsearchCronJob())
 		}
 
-		if !operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) {
+		spec := c.cfg.IntrusionDetection.Spec
+		skipInstallerJob := c.cfg.ElasticExternal || spec.SkipInstallerJob != nil && *spec.SkipInstallerJob
+		// The installer has nothing left to do when it should neither create the indices nor set up their rollover.
+		if spec.SkipIndexCreation != nil && *spec.SkipIndexCreation && spec.SkipIndexRollover != nil && *spec.SkipIndexRollover {
+			skipInstallerJob = true
+		}
+		if !operatorv1.IsFIPSModeEnabled(c.cfg.Installation.FIPSMode) && !skipInstallerJob {
 			objs = append(objs, idsObjs...)
 		} else {
 			objsToDelete = append(objsToDelete, idsObjs...)
@@ -288,11 +437,13 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 	}
 
 	if c.cfg.UsePSP {
-		objs = append(objs,
-			c.intrusionDetectionPSPClusterRole(),
-			c.intrusionDetectionPSPClusterRoleBinding(),
-			c.intrusionDetectionPodSecurityPolicy(),
-		)
+		if c.cfg.Instance == "" {
+			objs = append(objs, c.intrusionDetectionPSPClusterRole())
+		}
+		objs = append(objs, c.intrusionDetectionPSPClusterRoleBinding())
+		if c.cfg.Instance == "" {
+			objs = append(objs, c.intrusionDetectionPodSecurityPolicy())
+		}
 	}
 
 	if c.cfg.ManagedCluster {
@@ -305,6 +456,8 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 		objsToDelete = append(objsToDelete, c.externalLinseedRoleBinding())
 	}
 
+	objsToDelete = append(objsToDelete, c.staleConfigMapCopies(append(objs, objsToDelete...))...)
+
 	if c.cfg.HasNoLicense {
 		return nil, objs
 	}
@@ -312,11 +465,108 @@ func (c *intrusionDetectionComponent) Objects() ([]client.Object, []client.Objec
 	return objs, objsToDelete
 }
 
+// copyConfigMaps returns copies of the given ConfigMaps in the intrusion detection namespace, labeled with
+// CopiedConfigMapLabel.
+func (c *intrusionDetectionComponent) copyConfigMaps(cms ...*corev1.ConfigMap) []client.Object {
+	copies := configmap.CopyToNamespace(c.namespace(), cms...)
+	for _, cm := range copies {
+		cm.Labels = map[string]string{CopiedConfigMapLabel: "true"}
+	}
+	return configmap.ToRuntimeObjects(copies...)
+}
+
+// staleConfigMapCopies returns the copied ConfigMaps that are not among the given objects, since they are no longer
+// referenced.
+func (c *intrusionDetectionComponent) staleConfigMapCopies(objs []client.Object) []client.Object {
+	rendered := map[string]bool{}
+	for _, obj := range objs {
+		if cm, ok := obj.(*corev1.ConfigMap); ok && cm.Namespace == c.namespace() {
+			rendered[cm.Name] = true
+		}
+	}
+	var stale []client.Object
+	for _, name := range c.cfg.CopiedConfigMaps {
+		if !rendered[name] {
+			stale = append(stale, &corev1.ConfigMap{
+				TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: c.namespace()},
+			})
+		}
+	}
+	return stale
+}
+
+// IntrusionDetectionCleanupObjects returns the objects that must be removed when the IntrusionDetection of the given
+// configuration is deleted, in the order they should be deleted. The controller goes first so that it stops acting on
+// the others. The leftovers of anomaly detection are only removed along with the default IntrusionDetection, which
+// they belong to.
+func IntrusionDetectionCleanupObjects(cfg *IntrusionDetectionConfiguration) []client.Object {
+	c := &intrusionDetectionComponent{cfg: cfg}
+	objs := []client.Object{
+		&appsv1.Deployment{
+			TypeMeta:   metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: IntrusionDetectionName, Namespace: c.namespace()},
+		},
+		&batchv1.Job{
+			TypeMeta:   metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: IntrusionDetectionInstallerJobName, Namespace: c.namespace()},
+		},
+		&batchv1.CronJob{
+			TypeMeta:   metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: IntrusionDetectionInstallerJobName, Namespace: c.namespace()},
+		},
+	}
+	if c.cfg.Instance == "" {
+		objs = append(objs, c.adDetectorPodTemplates()...)
+		objs = append(objs, c.adAPIDeployment())
+	}
+	return objs
+}
+
 func (c *intrusionDetectionComponent) Ready() bool {
 	return true
 }
 
 func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchJob() *batchv1.Job {
+	spec := c.intrusionDetectionElasticsearchJobSpec()
+	spec.Selector = &metav1.LabelSelector{
+		MatchLabels: map[string]string{
+			"job-name": IntrusionDetectionInstallerJobName,
+		},
+	}
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        IntrusionDetectionInstallerJobName,
+			Namespace:   c.namespace(),
+			Annotations: map[string]string{InstallerJobHashAnnotation: rmeta.AnnotationHash(spec.Template)},
+			Finalizers:  []string{InstallerJobFinalizer},
+		},
+		Spec: spec,
+	}
+}
+
+// intrusionDetectionElasticsearchCronJob returns the installer as a CronJob that runs on the InstallerSchedule. The
+// Jobs it creates get a generated selector, while their pods keep the job-name label of the one-shot Job so that the
+// installer policy still applies to them.
+func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchCronJob() *batchv1.CronJob {
+	return &batchv1.CronJob{
+		TypeMeta: metav1.TypeMeta{Kind: "CronJob", APIVersion: "batch/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      IntrusionDetectionInstallerJobName,
+			Namespace: c.namespace(),
+		},
+		Spec: batchv1.CronJobSpec{
+			Schedule:          c.cfg.IntrusionDetection.Spec.InstallerSchedule,
+			ConcurrencyPolicy: batchv1.ForbidConcurrent,
+			JobTemplate: batchv1.JobTemplateSpec{
+				Spec: c.intrusionDetectionElasticsearchJobSpec(),
+			},
+		},
+	}
+}
+
+func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchJobSpec() batchv1.JobSpec {
 	podTemplate := relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Labels: map[string]string{"job-name": IntrusionDetectionInstallerJobName},
@@ -328,39 +578,32 @@ func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchJob() *batc
 			RestartPolicy:    corev1.RestartPolicyNever,
 			ImagePullSecrets: secret.GetReferenceList(c.cfg.PullSecrets),
 			Containers: []corev1.Container{
-				relasticsearch.ContainerDecorate(c.intrusionDetectionJobContainer(), c.cfg.ESClusterConfig.ClusterName(),
+				relasticsearch.ContainerDecorate(relasticsearch.ContainerDecorateIndexPrefix(c.intrusionDetectionJobContainer(), c.cfg.ESClusterConfig.IndexPrefix()), c.cfg.ESClusterConfig.ClusterName(),
 					ElasticsearchIntrusionDetectionJobUserSecret, c.cfg.ClusterDomain, rmeta.OSTypeLinux),
 			},
-			Volumes:            []corev1.Volume{c.cfg.TrustedCertBundle.Volume()},
-			ServiceAccountName: IntrusionDetectionInstallerJobName,
+			Volumes:                      append([]corev1.Volume{c.cfg.TrustedCertBundle.Volume()}, c.installerCABundleVolumes()...),
+			ServiceAccountName:           c.installerServiceAccountName(),
+			AutomountServiceAccountToken: c.installerAutomountServiceAccountToken(),
+			SecurityContext:              c.podSecurityContext(),
+			DNSPolicy:                    c.cfg.IntrusionDetection.Spec.DNSPolicy,
+			DNSConfig:                    c.cfg.IntrusionDetection.Spec.DNSConfig,
 		},
 	}, c.cfg.ESClusterConfig, c.cfg.ESSecrets).(*corev1.PodTemplateSpec)
 
-	return &batchv1.Job{
-		TypeMeta: metav1.TypeMeta{Kind: "Job", APIVersion: "batch/v1"},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      IntrusionDetectionInstallerJobName,
-			Namespace: IntrusionDetectionNamespace,
-		},
-		Spec: batchv1.JobSpec{
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{
-					"job-name": IntrusionDetectionInstallerJobName,
-				},
-			},
-			Template: *podTemplate,
-			// PodFailurePolicy is not available for k8s < 1.26; setting BackoffLimit to a higher number (default is 6)
-			// to lessen the frequency of installation failures when responses from Elastic Search takes more time.
-			BackoffLimit: ptr.Int32ToPtr(30),
-			PodFailurePolicy: &batchv1.PodFailurePolicy{
-				Rules: []batchv1.PodFailurePolicyRule{
-					// We don't want the job to fail, so we keep retrying by ignoring incrementing the backoff.
-					{
-						Action: "Ignore",
-						OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
-							Operator: "NotIn",
-							Values:   []int32{0},
-						},
+	return batchv1.JobSpec{
+		Template:                *podTemplate,
+		TTLSecondsAfterFinished: c.installerJobTTLSecondsAfterFinished(),
+		// PodFailurePolicy is not available for k8s < 1.26; setting BackoffLimit to a higher number (default is 6)
+		// to lessen the frequency of installation failures when responses from Elastic Search takes more time.
+		BackoffLimit: ptr.Int32ToPtr(30),
+		PodFailurePolicy: &batchv1.PodFailurePolicy{
+			Rules: []batchv1.PodFailurePolicyRule{
+				// We don't want the job to fail, so we keep retrying by ignoring incrementing the backoff.
+				{
+					Action: "Ignore",
+					OnExitCodes: &batchv1.PodFailurePolicyOnExitCodesRequirement{
+						Operator: "NotIn",
+						Values:   []int32{0},
 					},
 				},
 			},
@@ -368,52 +611,233 @@ func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchJob() *batc
 	}
 }
 
+// podSecurityContext returns the pod security context of the intrusion-detection-controller and installer pods.
+func (c *intrusionDetectionComponent) podSecurityContext() *corev1.PodSecurityContext {
+	sc := securitycontext.NewRuntimeDefaultPodContext()
+	sc.FSGroup = c.cfg.IntrusionDetection.Spec.FSGroup
+	if sc.FSGroup == nil {
+		// Default to the group of the non-root containers, so that they can read the mounted volumes.
+		sc.FSGroup = securitycontext.NewNonRootContext().RunAsGroup
+	}
+	return sc
+}
+
+// installerJobTTLSecondsAfterFinished returns how long the installer Job is kept once it has finished. Failed pods
+// never finish the Job, since the pod failure policy ignores them, so only a successful Job is cleaned up.
+func (c *intrusionDetectionComponent) installerJobTTLSecondsAfterFinished() *int32 {
+	if ttl := c.cfg.IntrusionDetection.Spec.InstallerJobTTLSecondsAfterFinished; ttl != nil {
+		return ptr.Int32ToPtr(*ttl)
+	}
+	return ptr.Int32ToPtr(DefaultInstallerJobTTLSecondsAfterFinished)
+}
+
 func (c *intrusionDetectionComponent) intrusionDetectionJobContainer() corev1.Container {
 	kScheme, kHost, kPort, _ := url.ParseEndpoint(rkibana.HTTPSEndpoint(c.SupportedOSType(), c.cfg.ClusterDomain))
 	secretName := ElasticsearchIntrusionDetectionJobUserSecret
+	envs := []corev1.EnvVar{
+		{
+			Name:  "KIBANA_HOST",
+			Value: kHost,
+		},
+		{
+			Name:  "KIBANA_PORT",
+			Value: kPort,
+		},
+		{
+			Name:  "KIBANA_SCHEME",
+			Value: kScheme,
+		},
+		{
+			// We no longer need to start the xpack trial from the installer pod. Logstorage
+			// now takes care of this in combination with the ECK operator (v1).
+			Name:  "START_XPACK_TRIAL",
+			Value: "false",
+		},
+		{
+			Name:      "USER",
+			ValueFrom: secret.GetEnvVarSource(secretName, "username", false),
+		},
+		{
+			Name:      "PASSWORD",
+			ValueFrom: secret.GetEnvVarSource(secretName, "password", false),
+		},
+		{
+			Name:  "KB_CA_CERT",
+			Value: c.cfg.TrustedCertBundle.MountPath(),
+		},
+		{
+			Name:  "FIPS_MODE_ENABLED",
+			Value: operatorv1.IsFIPSModeEnabledString(c.cfg.Installation.FIPSMode),
+		},
+	}
+	if attrs := c.cfg.ESClusterConfig.AwarenessAttributes(); len(attrs) > 0 {
+		// Tell the installer which node attributes to use for shard allocation awareness, so that the copies of a
+		// shard are spread across failure domains.
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_ALLOCATION_AWARENESS_ATTRIBUTES", Value: strings.Join(attrs, ",")})
+	}
+	if limit := c.cfg.ESClusterConfig.TotalFieldsLimit(); limit > 0 {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_INDEX_MAPPING_TOTAL_FIELDS_LIMIT", Value: strconv.Itoa(limit)})
+	}
+	if limit := c.cfg.ESClusterConfig.DepthLimit(); limit > 0 {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_INDEX_MAPPING_DEPTH_LIMIT", Value: strconv.Itoa(limit)})
+	}
+	if version := c.cfg.IntrusionDetection.Spec.ElasticsearchVersion; version != "" {
+		// Only set up the indices and templates that are compatible with the pinned version, rather than with the
+		// version that the installer detects.
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_VERSION", Value: version})
+	}
+	// The installer both creates the indices and sets up their rollover by default, so only the steps it should skip
+	// are passed to it.
+	if skip := c.cfg.IntrusionDetection.Spec.SkipIndexCreation; skip != nil && *skip {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_CREATE_INDICES", Value: "false"})
+	}
+	if skip := c.cfg.IntrusionDetection.Spec.SkipIndexRollover; skip != nil && *skip {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_MANAGE_ROLLOVER", Value: "false"})
+	} else {
+		envs = append(envs, indexLifecycleEnvVars(c.cfg.IntrusionDetection.Spec.IndexLifecycle)...)
+	}
+	envs = append(envs, c.proxyEnvVars()...)
+	envs = append(envs, c.ipFamilyEnvVars()...)
+
+	volumeMounts := c.cfg.TrustedCertBundle.VolumeMounts(c.SupportedOSType())
+	if len(c.cfg.InstallerCABundles) > 0 {
+		var caCerts []string
+		for i := range c.cfg.InstallerCABundles {
+			volumeMounts = append(volumeMounts, corev1.VolumeMount{
+				Name:      installerCABundleVolumeName(i),
+				MountPath: installerCABundleMountPath(i),
+				ReadOnly:  true,
+			})
+			caCerts = append(caCerts, path.Join(installerCABundleMountPath(i), installerCABundleFileName))
+		}
+		envs = append(envs, corev1.EnvVar{Name: "EXTRA_CA_CERTS", Value: strings.Join(caCerts, ",")})
+	}
+
 	return corev1.Container{
 		Name:            "elasticsearch-job-installer",
 		Image:           c.jobInstallerImage,
-		ImagePullPolicy: ImagePullPolicy(),
-		Env: []corev1.EnvVar{
-			{
-				Name:  "KIBANA_HOST",
-				Value: kHost,
-			},
-			{
-				Name:  "KIBANA_PORT",
-				Value: kPort,
-			},
-			{
-				Name:  "KIBANA_SCHEME",
-				Value: kScheme,
-			},
-			{
-				// We no longer need to start the xpack trial from the installer pod. Logstorage
-				// now takes care of this in combination with the ECK operator (v1).
-				Name:  "START_XPACK_TRIAL",
-				Value: "false",
-			},
-			{
-				Name:      "USER",
-				ValueFrom: secret.GetEnvVarSource(secretName, "username", false),
-			},
-			{
-				Name:      "PASSWORD",
-				ValueFrom: secret.GetEnvVarSource(secretName, "password", false),
-			},
-			{
-				Name:  "KB_CA_CERT",
-				Value: c.cfg.TrustedCertBundle.MountPath(),
-			},
-			{
-				Name:  "FIPS_MODE_ENABLED",
-				Value: operatorv1.IsFIPSModeEnabledString(c.cfg.Installation.FIPSMode),
-			},
-		},
+		ImagePullPolicy: IntrusionDetectionImagePullPolicy(c.cfg.IntrusionDetection.Spec),
+		Env:             envs,
 		SecurityContext: securitycontext.NewNonRootContext(),
-		VolumeMounts:    c.cfg.TrustedCertBundle.VolumeMounts(c.SupportedOSType()),
+		VolumeMounts:    volumeMounts,
+	}
+}
+
+// installerCABundleFileName is the name of the file that each additional CA bundle of the installer is mounted as.
+const installerCABundleFileName = "ca.crt"
+
+// installerCABundleVolumes returns a volume for each of the additional CA bundles of the installer, which projects
+// the referenced key of the copied ConfigMap to installerCABundleFileName.
+func (c *intrusionDetectionComponent) installerCABundleVolumes() []corev1.Volume {
+	var volumes []corev1.Volume
+	for i, cm := range c.cfg.InstallerCABundles {
+		ref := c.cfg.IntrusionDetection.Spec.InstallerCABundles[i]
+		volumes = append(volumes, corev1.Volume{
+			Name: installerCABundleVolumeName(i),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: cm.Name},
+					Items:                []corev1.KeyToPath{{Key: ref.Key, Path: installerCABundleFileName}},
+				},
+			},
+		})
+	}
+	return volumes
+}
+
+// uniqueInstallerCABundles returns the additional CA bundle ConfigMaps of the installer without duplicates, since
+// several bundles can reference different keys of the same ConfigMap.
+func (c *intrusionDetectionComponent) uniqueInstallerCABundles() []*corev1.ConfigMap {
+	var cms []*corev1.ConfigMap
+	seen := map[string]bool{}
+	for _, cm := range c.cfg.InstallerCABundles {
+		if !seen[cm.Name] {
+			seen[cm.Name] = true
+			cms = append(cms, cm)
+		}
+	}
+	return cms
+}
+
+func installerCABundleVolumeName(i int) string {
+	return fmt.Sprintf("installer-ca-bundle-%d", i)
+}
+
+func installerCABundleMountPath(i int) string {
+	return path.Join(InstallerCABundlesMountPath, strconv.Itoa(i))
+}
+
+// indexLifecycleEnvVars returns the env vars that pass the configured index lifecycle phases to the installer, which
+// uses its defaults for the ones that are not set.
+func indexLifecycleEnvVars(ilm *operatorv1.IntrusionDetectionIndexLifecycle) []corev1.EnvVar {
+	if ilm == nil {
+		return nil
+	}
+	var envs []corev1.EnvVar
+	add := func(name, value string) {
+		if value != "" {
+			envs = append(envs, corev1.EnvVar{Name: name, Value: value})
+		}
+	}
+	add("ELASTIC_ILM_ROLLOVER_MAX_AGE", ilm.RolloverMaxAge)
+	add("ELASTIC_ILM_ROLLOVER_MAX_SIZE", ilm.RolloverMaxSize)
+	for _, phase := range []struct {
+		name  string
+		phase *operatorv1.IndexLifecyclePhase
+	}{{"WARM", ilm.Warm}, {"COLD", ilm.Cold}} {
+		if phase.phase == nil {
+			continue
+		}
+		add(fmt.Sprintf("ELASTIC_ILM_%s_MIN_AGE", phase.name), phase.phase.MinAge)
+		if replicas := phase.phase.Replicas; replicas != nil {
+			add(fmt.Sprintf("ELASTIC_ILM_%s_REPLICAS", phase.name), strconv.Itoa(int(*replicas)))
+		}
+		if segments := phase.phase.ForceMergeMaxSegments; segments != nil {
+			add(fmt.Sprintf("ELASTIC_ILM_%s_FORCEMERGE_MAX_SEGMENTS", phase.name), strconv.Itoa(int(*segments)))
+		}
+	}
+	add("ELASTIC_ILM_DELETE_MIN_AGE", ilm.DeleteMinAge)
+	return envs
+}
+
+// controllerServiceAccountName returns the name of the ServiceAccount the intrusion-detection-controller runs as.
+func (c *intrusionDetectionComponent) controllerServiceAccountName() string {
+	return IntrusionDetectionControllerServiceAccountName(&c.cfg.IntrusionDetection)
+}
+
+// IntrusionDetectionControllerServiceAccountName returns the name of the ServiceAccount the
+// intrusion-detection-controller of the given IntrusionDetection runs as.
+func IntrusionDetectionControllerServiceAccountName(ids *operatorv1.IntrusionDetection) string {
+	if sa := ids.Spec.ServiceAccounts; sa != nil && sa.Controller != "" {
+		return sa.Controller
+	}
+	return IntrusionDetectionName
+}
+
+// installerServiceAccountName returns the name of the ServiceAccount the installer Job runs as.
+func (c *intrusionDetectionComponent) installerServiceAccountName() string {
+	if sa := c.cfg.IntrusionDetection.Spec.ServiceAccounts; sa != nil && sa.Installer != "" {
+		return sa.Installer
+	}
+	return IntrusionDetectionInstallerJobName
+}
+
+// controllerAutomountServiceAccountToken returns the automountServiceAccountToken of the intrusion-detection-controller
+// pods, which is nil to keep the setting of the ServiceAccount.
+func (c *intrusionDetectionComponent) controllerAutomountServiceAccountToken() *bool {
+	if t := c.cfg.IntrusionDetection.Spec.AutomountServiceAccountTokens; t != nil {
+		return t.Controller
 	}
+	return nil
+}
+
+// installerAutomountServiceAccountToken returns the automountServiceAccountToken of the installer Job pods, which is
+// nil to keep the setting of the ServiceAccount.
+func (c *intrusionDetectionComponent) installerAutomountServiceAccountToken() *bool {
+	if t := c.cfg.IntrusionDetection.Spec.AutomountServiceAccountTokens; t != nil {
+		return t.Installer
+	}
+	return nil
 }
 
 func (c *intrusionDetectionComponent) intrusionDetectionServiceAccount() *corev1.ServiceAccount {
@@ -421,7 +845,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionServiceAccount() *corev1
 		TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 	}
 }
@@ -431,7 +855,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionJobServiceAccount() *cor
 		TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionInstallerJobName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 	}
 }
@@ -581,7 +1005,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionClusterRoleBinding() *rb
 	return &rbacv1.ClusterRoleBinding{
 		TypeMeta: metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: IntrusionDetectionName,
+			Name: c.instanceName(IntrusionDetectionName),
 		},
 		RoleRef: rbacv1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
@@ -591,13 +1015,21 @@ func (c *intrusionDetectionComponent) intrusionDetectionClusterRoleBinding() *rb
 		Subjects: []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
-				Name:      IntrusionDetectionName,
-				Namespace: IntrusionDetectionNamespace,
+				Name:      c.controllerServiceAccountName(),
+				Namespace: c.namespace(),
 			},
 		},
 	}
 }
 
+// intrusionDetectionCSRClusterRoleBinding returns the binding that lets the controller of an additional
+// IntrusionDetection issue CSRs when certificate management is used.
+func (c *intrusionDetectionComponent) intrusionDetectionCSRClusterRoleBinding() *rbacv1.ClusterRoleBinding {
+	crb := certificatemanagement.CSRClusterRoleBinding(c.controllerServiceAccountName(), c.namespace())
+	crb.Name = c.instanceName(crb.Name)
+	return crb
+}
+
 func (c *intrusionDetectionComponent) externalLinseedRoleBinding() *rbacv1.RoleBinding {
 	// For managed clusters, we must create a role binding to allow Linseed to manage access token secrets
 	// in our namespace.
@@ -606,7 +1038,7 @@ func (c *intrusionDetectionComponent) externalLinseedRoleBinding() *rbacv1.RoleB
 		TypeMeta: metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      linseed,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 		RoleRef: rbacv1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
@@ -628,7 +1060,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionRole() *rbacv1.Role {
 		TypeMeta: metav1.TypeMeta{Kind: "Role", APIVersion: "rbac.authorization.k8s.io/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 		Rules: []rbacv1.PolicyRule{
 			{
@@ -652,7 +1084,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionRoleBinding() *rbacv1.Ro
 		TypeMeta: metav1.TypeMeta{Kind: "RoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 		RoleRef: rbacv1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
@@ -662,21 +1094,21 @@ func (c *intrusionDetectionComponent) intrusionDetectionRoleBinding() *rbacv1.Ro
 		Subjects: []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
-				Name:      IntrusionDetectionName,
-				Namespace: IntrusionDetectionNamespace,
+				Name:      c.controllerServiceAccountName(),
+				Namespace: c.namespace(),
 			},
 		},
 	}
 }
 
 func (c *intrusionDetectionComponent) intrusionDetectionDeployment() *appsv1.Deployment {
-	var replicas int32 = 1
+	replicas := c.controllerReplicas()
 
 	return &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{Kind: "Deployment", APIVersion: "apps/v1"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 		Spec: appsv1.DeploymentSpec{
 			Replicas: &replicas,
@@ -685,6 +1117,42 @@ func (c *intrusionDetectionComponent) intrusionDetectionDeployment() *appsv1.Dep
 	}
 }
 
+// controllerReplicas returns the number of intrusion-detection-controller replicas.
+func (c *intrusionDetectionComponent) controllerReplicas() int32 {
+	if r := c.cfg.IntrusionDetection.Spec.ControllerReplicas; r != nil {
+		return *r
+	}
+	return 1
+}
+
+// podDisruptionBudgetEnabled returns true if the intrusion-detection-controller needs a PodDisruptionBudget. A single
+// replica doesn't get one, since a budget that keeps it available would block node drains.
+func (c *intrusionDetectionComponent) podDisruptionBudgetEnabled() bool {
+	if skip := c.cfg.IntrusionDetection.Spec.SkipPodDisruptionBudget; skip != nil && *skip {
+		return false
+	}
+	return c.controllerReplicas() > 1
+}
+
+// intrusionDetectionPodDisruptionBudget keeps at least one intrusion-detection-controller replica available during
+// voluntary disruptions, so that detection doesn't stop while nodes are drained.
+func (c *intrusionDetectionComponent) intrusionDetectionPodDisruptionBudget() *policyv1.PodDisruptionBudget {
+	minAvailable := intstr.FromInt(1)
+	return &policyv1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{Kind: "PodDisruptionBudget", APIVersion: "policy/v1"},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      IntrusionDetectionName,
+			Namespace: c.namespace(),
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MinAvailable: &minAvailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"k8s-app": IntrusionDetectionName},
+			},
+		},
+	}
+}
+
 func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplateSpec {
 	var ps []corev1.LocalObjectReference
 	for _, x := range c.cfg.PullSecrets {
@@ -695,6 +1163,9 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 		c.cfg.TrustedCertBundle.Volume(),
 		c.cfg.IntrusionDetectionCertSecret.Volume(),
 	}
+	if c.cfg.MetricsKeyPair != nil {
+		volumes = append(volumes, c.cfg.MetricsKeyPair.Volume())
+	}
 	// If syslog forwarding is enabled then set the necessary hostpath volume to write
 	// logs for Fluentd to access.
 	if c.syslogForwardingIsEnabled() {
@@ -724,7 +1195,7 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 	}
 
 	intrusionDetectionContainer := relasticsearch.ContainerDecorateIndexCreator(
-		relasticsearch.ContainerDecorate(c.intrusionDetectionControllerContainer(), c.cfg.ESClusterConfig.ClusterName(),
+		relasticsearch.ContainerDecorate(relasticsearch.ContainerDecorateIndexPrefix(c.intrusionDetectionControllerContainer(), c.cfg.ESClusterConfig.IndexPrefix()), c.cfg.ESClusterConfig.ClusterName(),
 			ElasticsearchIntrusionDetectionUserSecret, c.cfg.ClusterDomain, rmeta.OSTypeLinux),
 		c.cfg.ESClusterConfig.Replicas(), c.cfg.ESClusterConfig.Shards())
 
@@ -736,9 +1207,25 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 	}
 	var initContainers []corev1.Container
 	if c.cfg.IntrusionDetectionCertSecret != nil && c.cfg.IntrusionDetectionCertSecret.UseCertificateManagement() {
-		initContainers = append(initContainers, c.cfg.IntrusionDetectionCertSecret.InitContainer(IntrusionDetectionNamespace))
+		initContainers = append(initContainers, c.cfg.IntrusionDetectionCertSecret.InitContainer(c.namespace()))
+	}
+	if c.cfg.MetricsKeyPair != nil && c.cfg.MetricsKeyPair.UseCertificateManagement() {
+		initContainers = append(initContainers, c.cfg.MetricsKeyPair.InitContainer(c.namespace()))
 	}
 
+	for i, name := range c.cfg.IntrusionDetection.Spec.AdditionalDetectionRuleConfigMaps {
+		volumes = append(volumes, corev1.Volume{
+			Name: detectionRulesVolumeName(i),
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: name}},
+			},
+		})
+	}
+	if v := c.findingsWebhookAuthVolume(); v != nil {
+		volumes = append(volumes, *v)
+	}
+	volumes = append(volumes, c.cfg.IntrusionDetection.Spec.ControllerVolumes...)
+
 	containers := []corev1.Container{intrusionDetectionContainer}
 	if c.deployWebhooksController() {
 		containers = append(containers, c.webhooksControllerContainer())
@@ -747,21 +1234,316 @@ func (c *intrusionDetectionComponent) deploymentPodTemplate() *corev1.PodTemplat
 	return relasticsearch.DecorateAnnotations(&corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        IntrusionDetectionName,
-			Namespace:   IntrusionDetectionNamespace,
+			Namespace:   c.namespace(),
 			Annotations: c.intrusionDetectionAnnotations(),
 		},
 		Spec: corev1.PodSpec{
-			Tolerations:        c.cfg.Installation.ControlPlaneTolerations,
-			NodeSelector:       c.cfg.Installation.ControlPlaneNodeSelector,
-			ServiceAccountName: IntrusionDetectionName,
-			ImagePullSecrets:   ps,
-			InitContainers:     initContainers,
-			Containers:         containers,
-			Volumes:            volumes,
+			Tolerations:                  c.cfg.Installation.ControlPlaneTolerations,
+			NodeSelector:                 c.cfg.Installation.ControlPlaneNodeSelector,
+			ServiceAccountName:           c.controllerServiceAccountName(),
+			AutomountServiceAccountToken: c.controllerAutomountServiceAccountToken(),
+			ImagePullSecrets:             ps,
+			InitContainers:               initContainers,
+			Containers:                   containers,
+			Volumes:                      volumes,
+			SecurityContext:              c.podSecurityContext(),
+			DNSPolicy:                    c.cfg.IntrusionDetection.Spec.DNSPolicy,
+			DNSConfig:                    c.cfg.IntrusionDetection.Spec.DNSConfig,
+			// The shutdown window covers the preStop hook of the controller, which gives it a chance to flush its
+			// state before it is signalled.
+			TerminationGracePeriodSeconds: c.cfg.IntrusionDetection.Spec.ControllerTerminationGracePeriodSeconds,
 		},
 	}, c.cfg.ESClusterConfig, c.cfg.ESSecrets).(*corev1.PodTemplateSpec)
 }
 
+// detectionRulesVolumeName returns the name of the volume of the i-th additional detection rule ConfigMap. The index is
+// used rather than the ConfigMap name, which may be too long for a volume name.
+func detectionRulesVolumeName(i int) string {
+	return fmt.Sprintf("detection-rules-%d", i)
+}
+
+// findingsWebhookAuthVolume returns the volume of the Authorization header of the findings webhook, or nil when the
+// webhook isn't configured with one.
+func (c *intrusionDetectionComponent) findingsWebhookAuthVolume() *corev1.Volume {
+	webhook := c.cfg.IntrusionDetection.Spec.FindingsWebhook
+	if webhook == nil || webhook.AuthSecret == nil || c.cfg.FindingsWebhookAuthSecret == nil {
+		return nil
+	}
+	return &corev1.Volume{
+		Name: findingsWebhookAuthVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName: c.cfg.FindingsWebhookAuthSecret.Name,
+				Items:      []corev1.KeyToPath{{Key: webhook.AuthSecret.Key, Path: findingsWebhookAuthFileName}},
+			},
+		},
+	}
+}
+
+// findingsWebhookEnvVars returns the env vars that make the intrusion-detection-controller forward security events to
+// the findings webhook, along with the mount of its Authorization header.
+func (c *intrusionDetectionComponent) findingsWebhookEnvVars() ([]corev1.EnvVar, []corev1.VolumeMount) {
+	webhook := c.cfg.IntrusionDetection.Spec.FindingsWebhook
+	if webhook == nil {
+		return nil, nil
+	}
+	envs := []corev1.EnvVar{{Name: "FINDINGS_WEBHOOK_URL", Value: webhook.URL}}
+	if c.findingsWebhookAuthVolume() == nil {
+		return envs, nil
+	}
+	envs = append(envs, corev1.EnvVar{Name: "FINDINGS_WEBHOOK_AUTHORIZATION_FILE", Value: path.Join(FindingsWebhookAuthMountPath, findingsWebhookAuthFileName)})
+	return envs, []corev1.VolumeMount{{Name: findingsWebhookAuthVolumeName, MountPath: FindingsWebhookAuthMountPath, ReadOnly: true}}
+}
+
+// ValidateIntrusionDetectionControllerVolumes returns an error if the additional volumes or volume mounts of the
+// intrusion-detection-controller conflict with each other or with the ones that are managed by the operator.
+func ValidateIntrusionDetectionControllerVolumes(cfg *IntrusionDetectionConfiguration) error {
+	if len(cfg.IntrusionDetection.Spec.ControllerVolumes) == 0 && len(cfg.IntrusionDetection.Spec.ControllerVolumeMounts) == 0 {
+		return nil
+	}
+	c := &intrusionDetectionComponent{cfg: cfg}
+
+	volumes := map[string]bool{}
+	for _, v := range c.deploymentPodTemplate().Spec.Volumes {
+		if volumes[v.Name] {
+			return fmt.Errorf("volume name %q is used more than once", v.Name)
+		}
+		volumes[v.Name] = true
+	}
+
+	paths := map[string]bool{}
+	for _, m := range c.intrusionDetectionControllerContainer().VolumeMounts {
+		if paths[m.MountPath] {
+			return fmt.Errorf("mount path %q is used more than once", m.MountPath)
+		}
+		if !volumes[m.Name] {
+			return fmt.Errorf("volume mount %q does not reference a volume", m.Name)
+		}
+		paths[m.MountPath] = true
+	}
+	return nil
+}
+
+// podSecurityStandard returns the Pod Security Standard that the namespace of the components enforces. Unless one is
+// configured, it is restricted, or privileged when syslog forwarding is enabled, since that needs hostpath volumes.
+func (c *intrusionDetectionComponent) podSecurityStandard() PodSecurityStandard {
+	if pss := c.cfg.IntrusionDetection.Spec.PodSecurityStandard; pss != nil {
+		return PodSecurityStandard(strings.ToLower(string(*pss)))
+	}
+	if c.syslogForwardingIsEnabled() {
+		return PSSPrivileged
+	}
+	return PSSRestricted
+}
+
+// ValidateIntrusionDetectionPodSecurityStandard returns an error if one of the pods that the intrusion detection
+// component renders into its namespace doesn't meet the Pod Security Standard that the namespace enforces, e.g. because
+// of a hostpath volume, since Pod Security admission would otherwise reject the pods long after they are rendered.
+func ValidateIntrusionDetectionPodSecurityStandard(cfg *IntrusionDetectionConfiguration) error {
+	c := &intrusionDetectionComponent{cfg: cfg}
+	pss := c.podSecurityStandard()
+	if pss == PSSPrivileged {
+		return nil
+	}
+
+	toCreate, _ := IntrusionDetection(cfg).Objects()
+	for _, obj := range toCreate {
+		if obj.GetNamespace() != c.namespace() {
+			continue
+		}
+		var template *corev1.PodTemplateSpec
+		switch o := obj.(type) {
+		case *appsv1.Deployment:
+			template = &o.Spec.Template
+		case *appsv1.DaemonSet:
+			template = &o.Spec.Template
+		case *appsv1.StatefulSet:
+			template = &o.Spec.Template
+		case *batchv1.Job:
+			template = &o.Spec.Template
+		case *batchv1.CronJob:
+			template = &o.Spec.JobTemplate.Spec.Template
+		default:
+			continue
+		}
+		if err := checkPodSecurityStandard(template, pss); err != nil {
+			return fmt.Errorf("the %s pods don't meet the %s Pod Security Standard: %w", obj.GetName(), pss, err)
+		}
+	}
+	return nil
+}
+
+// baselineCapabilities are the capabilities that containers may add under the baseline Pod Security Standard.
+var baselineCapabilities = map[corev1.Capability]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true, "KILL": true, "MKNOD": true,
+	"NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true, "SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// baselineSysctls are the sysctls that pods may set under the baseline Pod Security Standard.
+var baselineSysctls = map[string]bool{
+	"kernel.shm_rmid_forced": true, "net.ipv4.ip_local_port_range": true, "net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies": true, "net.ipv4.ping_group_range": true,
+}
+
+// baselineSELinuxTypes are the SELinux types that pods may use under the baseline Pod Security Standard.
+var baselineSELinuxTypes = map[string]bool{"": true, "container_t": true, "container_init_t": true, "container_kvm_t": true}
+
+// checkPodSecurityStandard returns an error for the first control of the given baseline or restricted Pod Security
+// Standard that the pod template violates. It implements the controls of the v1.26 standards that Pod Security admission
+// enforces for the pods of a namespace, see https://kubernetes.io/docs/concepts/security/pod-security-standards/, but
+// unlike admission it doesn't know about the exemptions of the cluster, nor about the controls of later versions.
+func checkPodSecurityStandard(template *corev1.PodTemplateSpec, pss PodSecurityStandard) error {
+	restricted := pss == PSSRestricted
+	spec := &template.Spec
+	if spec.HostNetwork || spec.HostPID || spec.HostIPC {
+		return fmt.Errorf("the pods share a namespace of the host")
+	}
+	for _, v := range spec.Volumes {
+		if v.HostPath != nil {
+			return fmt.Errorf("volume %q is a hostpath volume", v.Name)
+		}
+		if restricted && v.ConfigMap == nil && v.CSI == nil && v.DownwardAPI == nil && v.EmptyDir == nil &&
+			v.Ephemeral == nil && v.PersistentVolumeClaim == nil && v.Projected == nil && v.Secret == nil {
+			return fmt.Errorf("volume %q is of a type that is not allowed", v.Name)
+		}
+	}
+
+	podSC := spec.SecurityContext
+	if podSC == nil {
+		podSC = &corev1.PodSecurityContext{}
+	}
+	for _, sysctl := range podSC.Sysctls {
+		if !baselineSysctls[sysctl.Name] {
+			return fmt.Errorf("the pods set the sysctl %s", sysctl.Name)
+		}
+	}
+	if podSC.WindowsOptions != nil && podSC.WindowsOptions.HostProcess != nil && *podSC.WindowsOptions.HostProcess {
+		return fmt.Errorf("the pods are Windows HostProcess pods")
+	}
+	if se := podSC.SELinuxOptions; se != nil && (se.User != "" || se.Role != "" || !baselineSELinuxTypes[se.Type]) {
+		return fmt.Errorf("the pods set SELinux options that are not allowed")
+	}
+	if podSC.SeccompProfile != nil && podSC.SeccompProfile.Type == corev1.SeccompProfileTypeUnconfined {
+		return fmt.Errorf("the pods have an unconfined seccomp profile")
+	}
+
+	for _, container := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		sc := container.SecurityContext
+		if sc == nil {
+			sc = &corev1.SecurityContext{}
+		}
+		if sc.Privileged != nil && *sc.Privileged {
+			return fmt.Errorf("container %q is privileged", container.Name)
+		}
+		if sc.WindowsOptions != nil && sc.WindowsOptions.HostProcess != nil && *sc.WindowsOptions.HostProcess {
+			return fmt.Errorf("container %q is a Windows HostProcess container", container.Name)
+		}
+		for _, port := range container.Ports {
+			if port.HostPort != 0 {
+				return fmt.Errorf("container %q uses host port %d", container.Name, port.HostPort)
+			}
+		}
+		var added []corev1.Capability
+		if sc.Capabilities != nil {
+			added = sc.Capabilities.Add
+		}
+		for _, capability := range added {
+			if !baselineCapabilities[capability] || restricted && capability != "NET_BIND_SERVICE" {
+				return fmt.Errorf("container %q adds the capability %s", container.Name, capability)
+			}
+		}
+		if profile, ok := template.Annotations[corev1.AppArmorBetaContainerAnnotationKeyPrefix+container.Name]; ok &&
+			profile != corev1.AppArmorBetaProfileRuntimeDefault && !strings.HasPrefix(profile, corev1.AppArmorBetaProfileNamePrefix) {
+			return fmt.Errorf("container %q has the AppArmor profile %s", container.Name, profile)
+		}
+		if se := sc.SELinuxOptions; se != nil && (se.User != "" || se.Role != "" || !baselineSELinuxTypes[se.Type]) {
+			return fmt.Errorf("container %q sets SELinux options that are not allowed", container.Name)
+		}
+		if sc.ProcMount != nil && *sc.ProcMount != corev1.DefaultProcMount {
+			return fmt.Errorf("container %q unmasks /proc", container.Name)
+		}
+		seccomp := podSC.SeccompProfile
+		if sc.SeccompProfile != nil {
+			seccomp = sc.SeccompProfile
+		}
+		if seccomp != nil && seccomp.Type == corev1.SeccompProfileTypeUnconfined {
+			return fmt.Errorf("container %q has an unconfined seccomp profile", container.Name)
+		}
+		if !restricted {
+			continue
+		}
+
+		if sc.AllowPrivilegeEscalation == nil || *sc.AllowPrivilegeEscalation {
+			return fmt.Errorf("container %q allows privilege escalation", container.Name)
+		}
+		if sc.Capabilities == nil || !containsCapability(sc.Capabilities.Drop, "ALL") {
+			return fmt.Errorf("container %q doesn't drop all capabilities", container.Name)
+		}
+		runAsNonRoot := podSC.RunAsNonRoot
+		if sc.RunAsNonRoot != nil {
+			runAsNonRoot = sc.RunAsNonRoot
+		}
+		runAsUser := podSC.RunAsUser
+		if sc.RunAsUser != nil {
+			runAsUser = sc.RunAsUser
+		}
+		if runAsNonRoot == nil || !*runAsNonRoot || runAsUser != nil && *runAsUser == 0 {
+			return fmt.Errorf("container %q may run as root", container.Name)
+		}
+		if seccomp == nil {
+			return fmt.Errorf("container %q has no seccomp profile", container.Name)
+		}
+	}
+	return nil
+}
+
+func containsCapability(capabilities []corev1.Capability, capability corev1.Capability) bool {
+	for _, c := range capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateIntrusionDetectionControllerArgs returns an error if one of the additional arguments of the
+// intrusion-detection-controller is empty, or is a flag for a setting that the operator manages through the environment
+// of the container, e.g. --linseed-url for LINSEED_URL.
+func ValidateIntrusionDetectionControllerArgs(cfg *IntrusionDetectionConfiguration) error {
+	if len(cfg.IntrusionDetection.Spec.ControllerExtraArgs) == 0 {
+		return nil
+	}
+	// Render without the env vars the user adds, which are not managed by the operator.
+	managedCfg := *cfg
+	managedCfg.IntrusionDetection = *cfg.IntrusionDetection.DeepCopy()
+	managedCfg.IntrusionDetection.Spec.ControllerEnv = nil
+	c := &intrusionDetectionComponent{cfg: &managedCfg}
+
+	managed := map[string]string{}
+	for _, container := range c.deploymentPodTemplate().Spec.Containers {
+		if container.Name != "controller" {
+			continue
+		}
+		for _, env := range container.Env {
+			managed[strings.ToLower(strings.ReplaceAll(env.Name, "_", "-"))] = env.Name
+		}
+	}
+
+	for _, arg := range cfg.IntrusionDetection.Spec.ControllerExtraArgs {
+		if strings.TrimSpace(arg) == "" {
+			return fmt.Errorf("arguments must not be empty")
+		}
+		if !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		flag := strings.SplitN(strings.TrimLeft(arg, "-"), "=", 2)[0]
+		if env, ok := managed[strings.ToLower(flag)]; ok {
+			return fmt.Errorf("argument %q conflicts with %s, which is managed by the operator", arg, env)
+		}
+	}
+	return nil
+}
+
 func (c *intrusionDetectionComponent) deployWebhooksController() bool {
 	// deploy webhooks controller container only for managed clusters or stand-alone enterprise clusters
 	return c.cfg.ManagedCluster || !c.cfg.ManagementCluster
@@ -791,6 +1573,8 @@ func (c *intrusionDetectionComponent) webhooksControllerContainer() corev1.Conta
 		},
 	}
 
+	envVars = append(envVars, c.proxyEnvVars()...)
+
 	volumeMounts := c.cfg.TrustedCertBundle.VolumeMounts(c.SupportedOSType())
 	volumeMounts = append(volumeMounts, c.cfg.IntrusionDetectionCertSecret.VolumeMount(c.SupportedOSType()))
 	if c.cfg.ManagedCluster {
@@ -804,7 +1588,7 @@ func (c *intrusionDetectionComponent) webhooksControllerContainer() corev1.Conta
 	return corev1.Container{
 		Name:            "webhooks-processor",
 		Image:           c.webhooksProcessorImage,
-		ImagePullPolicy: ImagePullPolicy(),
+		ImagePullPolicy: IntrusionDetectionImagePullPolicy(c.cfg.IntrusionDetection.Spec),
 		Env:             envVars,
 		SecurityContext: securitycontext.NewNonRootContext(),
 		VolumeMounts:    volumeMounts,
@@ -849,6 +1633,14 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() co
 	// write logs for Fluentd.
 	volumeMounts := c.cfg.TrustedCertBundle.VolumeMounts(c.SupportedOSType())
 	volumeMounts = append(volumeMounts, c.cfg.IntrusionDetectionCertSecret.VolumeMount(c.SupportedOSType()))
+	if c.cfg.MetricsKeyPair != nil {
+		envs = append(envs,
+			corev1.EnvVar{Name: "METRICS_SCHEME", Value: "https"},
+			corev1.EnvVar{Name: "METRICS_CERT_FILE", Value: c.cfg.MetricsKeyPair.VolumeMountCertificateFilePath()},
+			corev1.EnvVar{Name: "METRICS_KEY_FILE", Value: c.cfg.MetricsKeyPair.VolumeMountKeyFilePath()},
+		)
+		volumeMounts = append(volumeMounts, c.cfg.MetricsKeyPair.VolumeMount(c.SupportedOSType()))
+	}
 	if c.syslogForwardingIsEnabled() {
 		envs = append(envs,
 			corev1.EnvVar{Name: "IDS_ENABLE_EVENT_FORWARDING", Value: "true"},
@@ -870,11 +1662,43 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() co
 			})
 	}
 
+	for i, name := range c.cfg.IntrusionDetection.Spec.AdditionalDetectionRuleConfigMaps {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{
+			Name:      detectionRulesVolumeName(i),
+			MountPath: path.Join(DetectionRulesMountPath, name),
+			ReadOnly:  true,
+		})
+	}
+	webhookEnvs, webhookMounts := c.findingsWebhookEnvVars()
+	envs = append(envs, webhookEnvs...)
+	volumeMounts = append(volumeMounts, webhookMounts...)
+	volumeMounts = append(volumeMounts, c.cfg.IntrusionDetection.Spec.ControllerVolumeMounts...)
+
+	var resources corev1.ResourceRequirements
+	if r := IntrusionDetectionComponentResources(c.cfg.IntrusionDetection.Spec.ComponentResources, operatorv1.ComponentNameIntrusionDetectionController); r != nil {
+		resources = *r
+	}
+
+	envs = append(envs, c.proxyEnvVars()...)
+	envs = append(envs, c.ipFamilyEnvVars()...)
+	if timeout := c.cfg.IntrusionDetection.Spec.ElasticsearchQueryTimeout; timeout != "" {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_QUERY_TIMEOUT", Value: timeout})
+	}
+	if size := c.cfg.IntrusionDetection.Spec.ElasticsearchBulkSize; size != nil {
+		envs = append(envs, corev1.EnvVar{Name: "ELASTIC_BULK_SIZE", Value: strconv.Itoa(int(*size))})
+	}
+	if gomaxprocs := c.controllerGOMAXPROCS(resources); gomaxprocs != "" {
+		envs = append(envs, corev1.EnvVar{Name: "GOMAXPROCS", Value: gomaxprocs})
+	}
+	envs = appendUserEnvVars(envs, c.cfg.IntrusionDetection.Spec.ControllerEnv)
+
 	return corev1.Container{
 		Name:            "controller",
 		Image:           c.controllerImage,
-		ImagePullPolicy: ImagePullPolicy(),
+		ImagePullPolicy: IntrusionDetectionImagePullPolicy(c.cfg.IntrusionDetection.Spec),
+		Args:            c.cfg.IntrusionDetection.Spec.ControllerExtraArgs,
 		Env:             envs,
+		Resources:       resources,
 		// Needed for permissions to write to the audit log
 		LivenessProbe: &corev1.Probe{
 			ProbeHandler: corev1.ProbeHandler{
@@ -887,11 +1711,171 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerContainer() co
 			},
 			InitialDelaySeconds: 5,
 		},
+		// The pod only becomes ready, and so the Deployment available, once the controller reports that it is serving.
+		ReadinessProbe: &corev1.Probe{
+			ProbeHandler: corev1.ProbeHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{
+						"/usr/bin/healthz",
+						"readiness",
+					},
+				},
+			},
+			InitialDelaySeconds: 5,
+		},
 		SecurityContext: sc,
 		VolumeMounts:    volumeMounts,
+		Lifecycle:       c.controllerLifecycle(),
 	}
 }
 
+// controllerLifecycle returns the lifecycle hooks of the intrusion-detection-controller container, if any.
+func (c *intrusionDetectionComponent) controllerLifecycle() *corev1.Lifecycle {
+	if preStop := c.cfg.IntrusionDetection.Spec.ControllerPreStop; preStop != nil {
+		return &corev1.Lifecycle{PreStop: preStop.DeepCopy()}
+	}
+	return nil
+}
+
+// controllerGOMAXPROCS returns the GOMAXPROCS for the controller container, which is its CPU limit rounded down to a
+// whole number of CPUs but at least one, or an empty string if it shouldn't be set.
+func (c *intrusionDetectionComponent) controllerGOMAXPROCS(resources corev1.ResourceRequirements) string {
+	if enabled := c.cfg.IntrusionDetection.Spec.ControllerGOMAXPROCSFromCPULimit; enabled != nil && !*enabled {
+		return ""
+	}
+	limit, ok := resources.Limits[corev1.ResourceCPU]
+	if !ok || limit.IsZero() {
+		return ""
+	}
+	cpus := limit.MilliValue() / 1000
+	if cpus < 1 {
+		cpus = 1
+	}
+	return strconv.FormatInt(cpus, 10)
+}
+
+// IntrusionDetectionImagePullPolicy returns the pull policy of the intrusion detection containers, which defaults to
+// the pull policy of all the components.
+func IntrusionDetectionImagePullPolicy(spec operatorv1.IntrusionDetectionSpec) corev1.PullPolicy {
+	if spec.ImagePullPolicy != "" {
+		return spec.ImagePullPolicy
+	}
+	return ImagePullPolicy()
+}
+
+// IntrusionDetectionComponentResources returns the resource requirements in resources for the named intrusion detection
+// component, or nil if there are none.
+func IntrusionDetectionComponentResources(resources []operatorv1.IntrusionDetectionComponentResource, name operatorv1.IntrusionDetectionComponentName) *corev1.ResourceRequirements {
+	for _, cr := range resources {
+		if cr.ComponentName == name {
+			return cr.ResourceRequirements
+		}
+	}
+	return nil
+}
+
+// copySecrets returns copies of the given secrets in the namespace of the components, with the labels and annotations
+// of spec.copiedSecretMetadata added to them.
+func (c *intrusionDetectionComponent) copySecrets(secrets ...*corev1.Secret) []client.Object {
+	copies := secret.CopyToNamespace(c.namespace(), secrets...)
+	if md := c.cfg.IntrusionDetection.Spec.CopiedSecretMetadata; md != nil {
+		for _, s := range copies {
+			// Each copy gets maps of its own, since the labels of the operator are added to them later on.
+			s.Labels = mergeMetadata(md.Labels, s.Labels)
+			s.Annotations = mergeMetadata(md.Annotations, s.Annotations)
+		}
+	}
+	return secret.ToRuntimeObjects(copies...)
+}
+
+// mergeMetadata returns a copy of the given labels or annotations of the user merged with those of the operator, which
+// take precedence.
+func mergeMetadata(user, operator map[string]string) map[string]string {
+	if len(user) == 0 {
+		return operator
+	}
+	merged := map[string]string{}
+	for k, v := range user {
+		merged[k] = v
+	}
+	for k, v := range operator {
+		merged[k] = v
+	}
+	return merged
+}
+
+// IntrusionDetectionPodAnnotations returns the annotations of the pods of the named component, which are the
+// annotations the IntrusionDetection adds to them merged with the given annotations of the operator. The annotations of
+// the operator take precedence, so that they can't be clobbered.
+func IntrusionDetectionPodAnnotations(podAnnotations []operatorv1.IntrusionDetectionComponentPodAnnotations, name operatorv1.IntrusionDetectionComponentName, operatorAnnotations map[string]string) map[string]string {
+	var annotations map[string]string
+	for _, pa := range podAnnotations {
+		if pa.ComponentName == name && len(pa.Annotations) > 0 {
+			annotations = map[string]string{}
+			for k, v := range pa.Annotations {
+				annotations[k] = v
+			}
+		}
+	}
+	if annotations == nil {
+		return operatorAnnotations
+	}
+	for k, v := range operatorAnnotations {
+		annotations[k] = v
+	}
+	return annotations
+}
+
+// proxyEnvVars returns the env vars that route the outbound traffic of a container through the configured HTTP proxy.
+// In-cluster destinations are always added to NO_PROXY so that traffic to other components doesn't leave the cluster.
+func (c *intrusionDetectionComponent) proxyEnvVars() []corev1.EnvVar {
+	if c.cfg.ProxyConfig == nil {
+		return nil
+	}
+
+	var noProxy []string
+	if c.cfg.ProxyConfig.NoProxy != "" {
+		noProxy = append(noProxy, c.cfg.ProxyConfig.NoProxy)
+	}
+	noProxy = append(noProxy, c.cfg.Installation.ServiceCIDRs...)
+	noProxy = append(noProxy, ".svc", fmt.Sprintf(".%s", c.cfg.ClusterDomain))
+
+	var envs []corev1.EnvVar
+	if c.cfg.ProxyConfig.HTTPProxy != "" {
+		envs = append(envs, corev1.EnvVar{Name: "HTTP_PROXY", Value: c.cfg.ProxyConfig.HTTPProxy})
+	}
+	if c.cfg.ProxyConfig.HTTPSProxy != "" {
+		envs = append(envs, corev1.EnvVar{Name: "HTTPS_PROXY", Value: c.cfg.ProxyConfig.HTTPSProxy})
+	}
+	return append(envs, corev1.EnvVar{Name: "NO_PROXY", Value: strings.Join(noProxy, ",")})
+}
+
+// ipFamilyEnvVars returns the env vars that make a container prefer the IPv6 addresses of Elasticsearch and Kibana,
+// when configured. Without them the IPv4 addresses are preferred.
+func (c *intrusionDetectionComponent) ipFamilyEnvVars() []corev1.EnvVar {
+	if prefer := c.cfg.IntrusionDetection.Spec.PreferIPv6; prefer != nil && *prefer {
+		return []corev1.EnvVar{{Name: "PREFER_IPV6", Value: "true"}}
+	}
+	return nil
+}
+
+// appendUserEnvVars appends the user provided env vars to envs, skipping any whose name is already set so that
+// operator managed env vars can't be overridden.
+func appendUserEnvVars(envs []corev1.EnvVar, userEnvs []corev1.EnvVar) []corev1.EnvVar {
+	reserved := make(map[string]bool, len(envs))
+	for _, env := range envs {
+		reserved[env.Name] = true
+	}
+	for _, env := range userEnvs {
+		if reserved[env.Name] {
+			continue
+		}
+		reserved[env.Name] = true
+		envs = append(envs, env)
+	}
+	return envs
+}
+
 // Determine whether this component's configuration has syslog forwarding enabled or not.
 // Look inside LogCollector spec for whether or not Syslog log type SyslogLogIDSEvents
 // exists. If it does, then we need to turn on forwarding for IDS event logs.
@@ -1187,7 +2171,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionPSPClusterRoleBinding()
 	return &rbacv1.ClusterRoleBinding{
 		TypeMeta: metav1.TypeMeta{Kind: "ClusterRoleBinding", APIVersion: "rbac.authorization.k8s.io/v1"},
 		ObjectMeta: metav1.ObjectMeta{
-			Name: "intrusion-detection-psp",
+			Name: c.instanceName("intrusion-detection-psp"),
 		},
 		RoleRef: rbacv1.RoleRef{
 			APIGroup: "rbac.authorization.k8s.io",
@@ -1197,20 +2181,21 @@ func (c *intrusionDetectionComponent) intrusionDetectionPSPClusterRoleBinding()
 		Subjects: []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
-				Name:      IntrusionDetectionName,
-				Namespace: IntrusionDetectionNamespace,
+				Name:      c.controllerServiceAccountName(),
+				Namespace: c.namespace(),
 			},
 			{
 				Kind:      "ServiceAccount",
-				Name:      IntrusionDetectionInstallerJobName,
-				Namespace: IntrusionDetectionNamespace,
+				Name:      c.installerServiceAccountName(),
+				Namespace: c.namespace(),
 			},
 		},
 	}
 }
 
 func (c *intrusionDetectionComponent) intrusionDetectionAnnotations() map[string]string {
-	return c.cfg.TrustedCertBundle.HashAnnotations()
+	return IntrusionDetectionPodAnnotations(c.cfg.IntrusionDetection.Spec.ComponentPodAnnotations,
+		operatorv1.ComponentNameIntrusionDetectionController, c.cfg.TrustedCertBundle.HashAnnotations())
 }
 
 // AD API RBAC for accessing token and subject access reviews for AD Pod token verification
@@ -1414,7 +2399,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionControllerAllowTigeraPol
 		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionControllerPolicyName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 		Spec: v3.NetworkPolicySpec{
 			Order:    &networkpolicy.HighPrecedenceOrder,
@@ -1445,7 +2430,7 @@ func (c *intrusionDetectionComponent) intrusionDetectionElasticsearchAllowTigera
 		TypeMeta: metav1.TypeMeta{Kind: "NetworkPolicy", APIVersion: "projectcalico.org/v3"},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      IntrusionDetectionInstallerPolicyName,
-			Namespace: IntrusionDetectionNamespace,
+			Namespace: c.namespace(),
 		},
 		Spec: v3.NetworkPolicySpec{
 			Order:    &networkpolicy.HighPrecedenceOrder,
@@ -22,11 +22,15 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
+	"golang.org/x/net/http/httpproxy"
 
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -35,8 +39,10 @@ import (
 	v3 "github.com/tigera/api/pkg/apis/projectcalico/v3"
 	operatorv1 "github.com/tigera/operator/api/v1"
 	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rmeta "github.com/tigera/operator/pkg/render/common/meta"
@@ -732,6 +738,7 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 			version string
 			kind    string
 		}{
+			{name: "intrusion-detection-controller", ns: "tigera-intrusion-detection", group: "policy", version: "v1", kind: "PodDisruptionBudget"},
 			{name: "tigera.io.detector.dga", ns: "", group: "projectcalico.org", version: "v3", kind: "GlobalAlertTemplate"},
 			{name: "tigera.io.detector.dga", ns: "", group: "projectcalico.org", version: "v3", kind: "GlobalAlert"},
 			{name: "tigera.io.detector.http-connection-spike", ns: "", group: "projectcalico.org", version: "v3", kind: "GlobalAlertTemplate"},
@@ -783,6 +790,7 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 			{name: "tigera.io.detectors.training", ns: "tigera-intrusion-detection", group: "", version: "v1", kind: "PodTemplate"},
 			{name: "tigera.io.detectors.detection", ns: "tigera-intrusion-detection", group: "", version: "v1", kind: "PodTemplate"},
 			{name: "anomaly-detection-api", ns: "", group: "policy", version: "v1beta1", kind: "PodSecurityPolicy"},
+			{name: "intrusion-detection-es-job-installer", ns: "tigera-intrusion-detection", group: "batch", version: "v1", kind: "CronJob"},
 			{name: "allow-tigera.intrusion-detection-elastic", ns: "tigera-intrusion-detection", group: "projectcalico.org", version: "v3", kind: "NetworkPolicy"},
 			{name: "intrusion-detection-es-job-installer", ns: "tigera-intrusion-detection", group: "batch", version: "v1", kind: "Job"},
 			{name: "tigera-linseed", ns: "tigera-intrusion-detection", group: "rbac.authorization.k8s.io", version: "v1", kind: "RoleBinding"},
@@ -795,6 +803,796 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 		}
 	})
 
+	It("should not render es-job installer when SkipInstallerJob is set", func() {
+		skipInstallerJob := true
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				SkipInstallerJob: &skipInstallerJob,
+			},
+		}
+		component := render.IntrusionDetection(cfg)
+		toCreate, toRemove := component.Objects()
+
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment")).NotTo(BeNil())
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).To(BeNil())
+		Expect(rtest.GetResource(toRemove, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).NotTo(BeNil())
+		Expect(rtest.GetResource(toRemove, "allow-tigera.intrusion-detection-elastic", "tigera-intrusion-detection", "projectcalico.org", "v3", "NetworkPolicy")).NotTo(BeNil())
+	})
+
+	It("should pass the configured index lifecycle phases to the installer", func() {
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				IndexLifecycle: &operatorv1.IntrusionDetectionIndexLifecycle{
+					RolloverMaxAge:  "1d",
+					RolloverMaxSize: "30gb",
+					Warm:            &operatorv1.IndexLifecyclePhase{MinAge: "7d", Replicas: ptr.Int32ToPtr(0), ForceMergeMaxSegments: ptr.Int32ToPtr(1)},
+					Cold:            &operatorv1.IndexLifecyclePhase{MinAge: "30d"},
+					DeleteMinAge:    "90d",
+				},
+			},
+		}
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		env := job.Spec.Template.Spec.Containers[0].Env
+		Expect(env).To(ContainElements(
+			corev1.EnvVar{Name: "ELASTIC_ILM_ROLLOVER_MAX_AGE", Value: "1d"},
+			corev1.EnvVar{Name: "ELASTIC_ILM_ROLLOVER_MAX_SIZE", Value: "30gb"},
+			corev1.EnvVar{Name: "ELASTIC_ILM_WARM_MIN_AGE", Value: "7d"},
+			corev1.EnvVar{Name: "ELASTIC_ILM_WARM_REPLICAS", Value: "0"},
+			corev1.EnvVar{Name: "ELASTIC_ILM_WARM_FORCEMERGE_MAX_SEGMENTS", Value: "1"},
+			corev1.EnvVar{Name: "ELASTIC_ILM_COLD_MIN_AGE", Value: "30d"},
+			corev1.EnvVar{Name: "ELASTIC_ILM_DELETE_MIN_AGE", Value: "90d"},
+		))
+		Expect(env).NotTo(ContainElement(HaveField("Name", "ELASTIC_ILM_COLD_REPLICAS")))
+	})
+
+	It("should make the controller and the installer prefer IPv6 only when configured", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "PREFER_IPV6")))
+
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{PreferIPv6: ptr.BoolToPtr(true)},
+		}
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "PREFER_IPV6", Value: "true"}))
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "PREFER_IPV6", Value: "true"}))
+	})
+
+	It("should mount each of the additional CA bundles into the installer", func() {
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				InstallerCABundles: []corev1.ConfigMapKeySelector{
+					{LocalObjectReference: corev1.LocalObjectReference{Name: "proxy-ca"}, Key: "tls.crt"},
+					{LocalObjectReference: corev1.LocalObjectReference{Name: "corp-ca"}, Key: "bundle.pem"},
+				},
+			},
+		}
+		cfg.InstallerCABundles = []*corev1.ConfigMap{
+			{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "proxy-ca", Namespace: common.OperatorNamespace()}},
+			{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "corp-ca", Namespace: common.OperatorNamespace()}},
+		}
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+
+		for _, name := range []string{"proxy-ca", "corp-ca"} {
+			cm := rtest.GetResource(toCreate, name, "tigera-intrusion-detection", "", "v1", "ConfigMap").(*corev1.ConfigMap)
+			Expect(cm.Labels).To(HaveKey(render.CopiedConfigMapLabel))
+		}
+
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Volumes).To(ContainElements(
+			corev1.Volume{
+				Name: "installer-ca-bundle-0",
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "proxy-ca"},
+					Items:                []corev1.KeyToPath{{Key: "tls.crt", Path: "ca.crt"}},
+				}},
+			},
+			corev1.Volume{
+				Name: "installer-ca-bundle-1",
+				VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "corp-ca"},
+					Items:                []corev1.KeyToPath{{Key: "bundle.pem", Path: "ca.crt"}},
+				}},
+			},
+		))
+		container := job.Spec.Template.Spec.Containers[0]
+		Expect(container.VolumeMounts).To(ContainElements(
+			corev1.VolumeMount{Name: "installer-ca-bundle-0", MountPath: "/etc/pki/installer-ca-bundles/0", ReadOnly: true},
+			corev1.VolumeMount{Name: "installer-ca-bundle-1", MountPath: "/etc/pki/installer-ca-bundles/1", ReadOnly: true},
+		))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{
+			Name:  "EXTRA_CA_CERTS",
+			Value: "/etc/pki/installer-ca-bundles/0/ca.crt,/etc/pki/installer-ca-bundles/1/ca.crt",
+		}))
+	})
+
+	It("should render the controller preStop hook and termination grace period as configured", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNil())
+		Expect(deploy.Spec.Template.Spec.Containers[0].Lifecycle).To(BeNil())
+
+		preStop := &corev1.LifecycleHandler{Exec: &corev1.ExecAction{Command: []string{"/usr/bin/healthz", "flush"}}}
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				ControllerTerminationGracePeriodSeconds: ptr.Int64ToPtr(120),
+				ControllerPreStop:                       preStop,
+			},
+		}
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(ptr.Int64ToPtr(120)))
+		Expect(deploy.Spec.Template.Spec.Containers[0].Name).To(Equal("controller"))
+		Expect(deploy.Spec.Template.Spec.Containers[0].Lifecycle).To(Equal(&corev1.Lifecycle{PreStop: preStop}))
+	})
+
+	It("should append user env vars to the controller without overriding operator managed ones", func() {
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				ControllerEnv: []corev1.EnvVar{
+					{Name: "DEBUG_FEATURE", Value: "true"},
+					{Name: "LINSEED_URL", Value: "https://example.com"},
+				},
+			},
+		}
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		envs := deploy.Spec.Template.Spec.Containers[0].Env
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "DEBUG_FEATURE", Value: "true"}))
+		Expect(envs).To(ContainElement(corev1.EnvVar{Name: "LINSEED_URL", Value: "https://tigera-linseed.tigera-elasticsearch.svc"}))
+		Expect(envs).NotTo(ContainElement(corev1.EnvVar{Name: "LINSEED_URL", Value: "https://example.com"}))
+	})
+
+	It("should apply the configured image pull policy to all containers", func() {
+		resources, _ := render.IntrusionDetection(cfg).Objects()
+		idc := rtest.GetResource(resources, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(idc.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+
+		cfg.IntrusionDetection.Spec.ImagePullPolicy = corev1.PullAlways
+		resources, _ = render.IntrusionDetection(cfg).Objects()
+
+		idc = rtest.GetResource(resources, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(idc.Spec.Template.Spec.Containers).To(HaveLen(2))
+		for _, container := range idc.Spec.Template.Spec.Containers {
+			Expect(container.ImagePullPolicy).To(Equal(corev1.PullAlways), container.Name)
+		}
+		job := rtest.GetResource(resources, render.IntrusionDetectionInstallerJobName, render.IntrusionDetectionNamespace, "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+	})
+
+	It("should mount the additional detection rule ConfigMaps into the controller", func() {
+		cfg.IntrusionDetection.Spec.AdditionalDetectionRuleConfigMaps = []string{"custom-rules", "more-rules"}
+		cfg.DetectionRuleConfigMaps = []*corev1.ConfigMap{
+			{
+				TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+				ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: common.OperatorNamespace()},
+				Data:       map[string]string{"rules.yaml": "rules"},
+			},
+		}
+		resources, _ := render.IntrusionDetection(cfg).Objects()
+
+		cm := rtest.GetResource(resources, "custom-rules", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap").(*corev1.ConfigMap)
+		Expect(cm.Data).To(Equal(map[string]string{"rules.yaml": "rules"}))
+		Expect(cm.Labels).To(HaveKey(render.CopiedConfigMapLabel))
+		Expect(rtest.GetResource(resources, "more-rules", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap")).To(BeNil())
+
+		idc := rtest.GetResource(resources, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(idc.Spec.Template.Spec.Volumes).To(ContainElements(
+			corev1.Volume{Name: "detection-rules-0", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "custom-rules"}}}},
+			corev1.Volume{Name: "detection-rules-1", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "more-rules"}}}},
+		))
+		Expect(idc.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElements(
+			corev1.VolumeMount{Name: "detection-rules-0", MountPath: "/etc/tigera/detection-rules/custom-rules", ReadOnly: true},
+			corev1.VolumeMount{Name: "detection-rules-1", MountPath: "/etc/tigera/detection-rules/more-rules", ReadOnly: true},
+		))
+	})
+
+	It("should delete the copied ConfigMaps that are no longer rendered", func() {
+		cfg.DetectionRuleConfigMaps = []*corev1.ConfigMap{
+			{TypeMeta: metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"}, ObjectMeta: metav1.ObjectMeta{Name: "custom-rules", Namespace: common.OperatorNamespace()}},
+		}
+		cfg.CopiedConfigMaps = []string{"custom-rules", "old-rules"}
+		toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+
+		Expect(rtest.GetResource(toCreate, "custom-rules", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, "custom-rules", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "old-rules", render.IntrusionDetectionNamespace, "", "v1", "ConfigMap")).NotTo(BeNil())
+	})
+
+	It("should set GOMAXPROCS on the controller to the floor of its CPU limit", func() {
+		controllerResources := &corev1.ResourceRequirements{
+			Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2500m")},
+		}
+		cfg.IntrusionDetection.Spec.ComponentResources = []operatorv1.IntrusionDetectionComponentResource{
+			{ComponentName: operatorv1.ComponentNameIntrusionDetectionController, ResourceRequirements: controllerResources},
+		}
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		controller := deploy.Spec.Template.Spec.Containers[0]
+		Expect(controller.Resources).To(Equal(*controllerResources))
+		Expect(controller.Env).To(ContainElement(corev1.EnvVar{Name: "GOMAXPROCS", Value: "2"}))
+
+		By("not setting it when disabled")
+		disabled := false
+		cfg.IntrusionDetection.Spec.ControllerGOMAXPROCSFromCPULimit = &disabled
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "GOMAXPROCS")))
+	})
+
+	It("should not set GOMAXPROCS on the controller when it has no CPU limit", func() {
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "GOMAXPROCS")))
+	})
+
+	It("should serve the controller metrics over TLS when a metrics key pair is set", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "METRICS_SCHEME")))
+		Expect(deploy.Spec.Template.Spec.Volumes).NotTo(ContainElement(HaveField("Name", render.IntrusionDetectionMetricsTLSSecretName)))
+
+		metricsSecret, err := certificatemanagement.CreateSelfSignedSecret(render.IntrusionDetectionMetricsTLSSecretName, "", "", nil)
+		Expect(err).NotTo(HaveOccurred())
+		cfg.MetricsKeyPair = certificatemanagement.NewKeyPair(metricsSecret, []string{""}, "")
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+
+		controller := deploy.Spec.Template.Spec.Containers[0]
+		Expect(controller.Env).To(ContainElements(
+			corev1.EnvVar{Name: "METRICS_SCHEME", Value: "https"},
+			corev1.EnvVar{Name: "METRICS_CERT_FILE", Value: cfg.MetricsKeyPair.VolumeMountCertificateFilePath()},
+			corev1.EnvVar{Name: "METRICS_KEY_FILE", Value: cfg.MetricsKeyPair.VolumeMountKeyFilePath()},
+		))
+		Expect(controller.VolumeMounts).To(ContainElement(cfg.MetricsKeyPair.VolumeMount(rmeta.OSTypeLinux)))
+		Expect(deploy.Spec.Template.Spec.Volumes).To(ContainElement(cfg.MetricsKeyPair.Volume()))
+	})
+
+	It("should use user provided service accounts instead of creating them", func() {
+		cfg.IntrusionDetection = operatorv1.IntrusionDetection{
+			Spec: operatorv1.IntrusionDetectionSpec{
+				ServiceAccounts: &operatorv1.IntrusionDetectionServiceAccounts{
+					Controller: "controller-irsa",
+					Installer:  "installer-irsa",
+				},
+			},
+		}
+		component := render.IntrusionDetection(cfg)
+		toCreate, toDelete := component.Objects()
+
+		for _, name := range []string{"intrusion-detection-controller", "intrusion-detection-es-job-installer"} {
+			Expect(rtest.GetResource(toCreate, name, "tigera-intrusion-detection", "", "v1", "ServiceAccount")).To(BeNil())
+			Expect(rtest.GetResource(toDelete, name, "tigera-intrusion-detection", "", "v1", "ServiceAccount")).NotTo(BeNil())
+		}
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.ServiceAccountName).To(Equal("controller-irsa"))
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.ServiceAccountName).To(Equal("installer-irsa"))
+
+		crb := rtest.GetResource(toCreate, "intrusion-detection-controller", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding").(*rbacv1.ClusterRoleBinding)
+		Expect(crb.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Name: "controller-irsa", Namespace: "tigera-intrusion-detection"}))
+		rb := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "rbac.authorization.k8s.io", "v1", "RoleBinding").(*rbacv1.RoleBinding)
+		Expect(rb.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Name: "controller-irsa", Namespace: "tigera-intrusion-detection"}))
+	})
+
+	It("should forward findings to the configured webhook with its Authorization header mounted", func() {
+		cfg.IntrusionDetection.Spec.FindingsWebhook = &operatorv1.IntrusionDetectionFindingsWebhook{
+			URL: "https://siem.example.com/ingest",
+			AuthSecret: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: "siem-auth"},
+				Key:                  "header",
+			},
+		}
+		cfg.FindingsWebhookAuthSecret = &corev1.Secret{
+			TypeMeta:   metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "siem-auth", Namespace: common.OperatorNamespace()},
+			Data:       map[string][]byte{"header": []byte("Bearer token")},
+		}
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+
+		Expect(rtest.GetResource(toCreate, "siem-auth", "tigera-intrusion-detection", "", "v1", "Secret")).NotTo(BeNil())
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: "findings-webhook-auth",
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{
+				SecretName: "siem-auth",
+				Items:      []corev1.KeyToPath{{Key: "header", Path: "authorization"}},
+			}},
+		}))
+		controller := deploy.Spec.Template.Spec.Containers[0]
+		Expect(controller.Env).To(ContainElements(
+			corev1.EnvVar{Name: "FINDINGS_WEBHOOK_URL", Value: "https://siem.example.com/ingest"},
+			corev1.EnvVar{Name: "FINDINGS_WEBHOOK_AUTHORIZATION_FILE", Value: "/etc/tigera/findings-webhook/authorization"},
+		))
+		Expect(controller.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: "findings-webhook-auth", MountPath: render.FindingsWebhookAuthMountPath, ReadOnly: true}))
+		Expect(render.ValidateIntrusionDetectionControllerVolumes(cfg)).To(Succeed())
+	})
+
+	It("should set automountServiceAccountToken on the pods only when configured", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+
+		cfg.IntrusionDetection.Spec.AutomountServiceAccountTokens = &operatorv1.IntrusionDetectionAutomountServiceAccountTokens{
+			Controller: ptr.BoolToPtr(false),
+			Installer:  ptr.BoolToPtr(true),
+		}
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.AutomountServiceAccountToken).To(Equal(ptr.BoolToPtr(false)))
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.AutomountServiceAccountToken).To(Equal(ptr.BoolToPtr(true)))
+	})
+
+	It("should render every object into the configured namespace", func() {
+		cfg.Namespace = "tenant-a"
+		secretType := metav1.TypeMeta{Kind: "Secret", APIVersion: "v1"}
+		cfg.PullSecrets = []*corev1.Secret{{TypeMeta: secretType, ObjectMeta: metav1.ObjectMeta{Name: "pull-secret", Namespace: "tigera-operator"}}}
+		cfg.ESSecrets = []*corev1.Secret{{TypeMeta: secretType, ObjectMeta: metav1.ObjectMeta{Name: render.ElasticsearchIntrusionDetectionUserSecret, Namespace: "tigera-operator"}}}
+		cfg.IntrusionDetection.Spec.ServiceAccounts = &operatorv1.IntrusionDetectionServiceAccounts{Controller: "controller-irsa"}
+		component := render.IntrusionDetection(cfg)
+		toCreate, toDelete := component.Objects()
+
+		ns := rtest.GetResource(toCreate, "tenant-a", "", "", "v1", "Namespace")
+		Expect(ns).NotTo(BeNil())
+		for _, obj := range toCreate {
+			if obj.GetNamespace() != "" {
+				Expect(obj.GetNamespace()).To(Equal("tenant-a"), fmt.Sprintf("%T %s", obj, obj.GetName()))
+			}
+			var subjects []rbacv1.Subject
+			switch o := obj.(type) {
+			case *rbacv1.RoleBinding:
+				subjects = o.Subjects
+			case *rbacv1.ClusterRoleBinding:
+				subjects = o.Subjects
+			}
+			for _, subject := range subjects {
+				Expect(subject.Namespace).To(Equal("tenant-a"), fmt.Sprintf("%T %s", obj, obj.GetName()))
+			}
+		}
+		Expect(rtest.GetResource(toCreate, "pull-secret", "tenant-a", "", "v1", "Secret")).NotTo(BeNil())
+		Expect(rtest.GetResource(toCreate, render.ElasticsearchIntrusionDetectionUserSecret, "tenant-a", "", "v1", "Secret")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, render.IntrusionDetectionName, "tenant-a", "", "v1", "ServiceAccount")).NotTo(BeNil())
+
+		for _, obj := range render.IntrusionDetectionCleanupObjects(cfg)[:3] {
+			Expect(obj.GetNamespace()).To(Equal("tenant-a"))
+		}
+	})
+
+	It("should only render the namespaced objects and bindings of an additional instance", func() {
+		cfg.Namespace = "tigera-intrusion-detection-tenant-a"
+		cfg.Instance = "tenant-a"
+		component := render.IntrusionDetection(cfg)
+		toCreate, toDelete := component.Objects()
+
+		crb := rtest.GetResource(toCreate, "intrusion-detection-controller-tenant-a", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding").(*rbacv1.ClusterRoleBinding)
+		Expect(crb.RoleRef.Name).To(Equal("intrusion-detection-controller"))
+		Expect(crb.Subjects[0].Namespace).To(Equal("tigera-intrusion-detection-tenant-a"))
+		pspBinding := rtest.GetResource(toCreate, "intrusion-detection-psp-tenant-a", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding").(*rbacv1.ClusterRoleBinding)
+		Expect(pspBinding.RoleRef.Name).To(Equal("intrusion-detection-psp"))
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection-tenant-a", "apps", "v1", "Deployment")).NotTo(BeNil())
+
+		for _, obj := range append(toCreate, toDelete...) {
+			switch obj.(type) {
+			case *rbacv1.ClusterRole, *v3.GlobalAlertTemplate, *v3.GlobalAlert:
+				Fail(fmt.Sprintf("unexpected cluster wide object %T %s", obj, obj.GetName()))
+			}
+			if obj.GetNamespace() != "" {
+				Expect(obj.GetNamespace()).To(Equal("tigera-intrusion-detection-tenant-a"), fmt.Sprintf("%T %s", obj, obj.GetName()))
+			}
+		}
+
+		By("only cleaning up the objects in the namespace of the additional instance")
+		cleanup := render.IntrusionDetectionCleanupObjects(cfg)
+		Expect(cleanup).To(HaveLen(3))
+		for _, obj := range cleanup {
+			Expect(obj.GetNamespace()).To(Equal("tigera-intrusion-detection-tenant-a"), fmt.Sprintf("%T %s", obj, obj.GetName()))
+		}
+	})
+
+	It("should add the additional controller volumes and volume mounts", func() {
+		geoip := corev1.Volume{Name: "geoip", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}
+		geoipMount := corev1.VolumeMount{Name: "geoip", MountPath: "/usr/share/GeoIP", ReadOnly: true}
+		cfg.IntrusionDetection.Spec.ControllerVolumes = []corev1.Volume{geoip}
+		cfg.IntrusionDetection.Spec.ControllerVolumeMounts = []corev1.VolumeMount{geoipMount}
+		Expect(render.ValidateIntrusionDetectionControllerVolumes(cfg)).NotTo(HaveOccurred())
+
+		component := render.IntrusionDetection(cfg)
+		resources, _ := component.Objects()
+		idc := rtest.GetResource(resources, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(idc.Spec.Template.Spec.Volumes).To(ContainElement(geoip))
+		Expect(idc.Spec.Template.Spec.Containers[0].VolumeMounts).To(ContainElement(geoipMount))
+	})
+
+	It("should reject additional controller volumes that conflict with the operator managed volumes", func() {
+		cfg.IntrusionDetection.Spec.ControllerVolumes = []corev1.Volume{{Name: "tigera-ca-bundle"}}
+		Expect(render.ValidateIntrusionDetectionControllerVolumes(cfg)).To(MatchError(ContainSubstring("tigera-ca-bundle")))
+
+		cfg.IntrusionDetection.Spec.ControllerVolumes = []corev1.Volume{{Name: "geoip"}}
+		cfg.IntrusionDetection.Spec.ControllerVolumeMounts = []corev1.VolumeMount{{Name: "geoip", MountPath: "/etc/pki/tls/certs"}}
+		Expect(render.ValidateIntrusionDetectionControllerVolumes(cfg)).To(MatchError(ContainSubstring("/etc/pki/tls/certs")))
+
+		cfg.IntrusionDetection.Spec.ControllerVolumes = nil
+		cfg.IntrusionDetection.Spec.ControllerVolumeMounts = []corev1.VolumeMount{{Name: "geoip", MountPath: "/usr/share/GeoIP"}}
+		Expect(render.ValidateIntrusionDetectionControllerVolumes(cfg)).To(MatchError(ContainSubstring("does not reference a volume")))
+	})
+
+	It("should pass the extra arguments to the controller in order", func() {
+		cfg.IntrusionDetection.Spec.ControllerExtraArgs = []string{"--log-level=debug", "--pprof", "--pprof-port", "6060"}
+		Expect(render.ValidateIntrusionDetectionControllerArgs(cfg)).NotTo(HaveOccurred())
+
+		resources, _ := render.IntrusionDetection(cfg).Objects()
+		idc := rtest.GetResource(resources, "intrusion-detection-controller", render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		controller := idc.Spec.Template.Spec.Containers[0]
+		Expect(controller.Command).To(BeEmpty())
+		Expect(controller.Args).To(Equal([]string{"--log-level=debug", "--pprof", "--pprof-port", "6060"}))
+	})
+
+	It("should reject extra controller arguments that conflict with the operator managed settings", func() {
+		cfg.IntrusionDetection.Spec.ControllerExtraArgs = []string{"--linseed-url=https://elsewhere:443"}
+		Expect(render.ValidateIntrusionDetectionControllerArgs(cfg)).To(MatchError(ContainSubstring("LINSEED_URL")))
+
+		cfg.IntrusionDetection.Spec.ControllerExtraArgs = []string{"-FIPS-MODE-ENABLED", "true"}
+		Expect(render.ValidateIntrusionDetectionControllerArgs(cfg)).To(MatchError(ContainSubstring("FIPS_MODE_ENABLED")))
+
+		cfg.IntrusionDetection.Spec.ControllerExtraArgs = []string{" "}
+		Expect(render.ValidateIntrusionDetectionControllerArgs(cfg)).To(MatchError(ContainSubstring("must not be empty")))
+
+		By("allowing flags for the env vars the user adds")
+		cfg.IntrusionDetection.Spec.ControllerEnv = []corev1.EnvVar{{Name: "GEOIP_DB", Value: "/usr/share/GeoIP"}}
+		cfg.IntrusionDetection.Spec.ControllerExtraArgs = []string{"--geoip-db=/tmp/GeoIP"}
+		Expect(render.ValidateIntrusionDetectionControllerArgs(cfg)).NotTo(HaveOccurred())
+	})
+
+	It("should set the RuntimeDefault seccomp profile on the controller and installer pods", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		runtimeDefault := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.SecurityContext.SeccompProfile).To(Equal(runtimeDefault))
+		for _, container := range deploy.Spec.Template.Spec.Containers {
+			Expect(container.SecurityContext.SeccompProfile).To(Equal(runtimeDefault), container.Name)
+		}
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.SecurityContext.SeccompProfile).To(Equal(runtimeDefault))
+		Expect(job.Spec.Template.Spec.Containers[0].SecurityContext.SeccompProfile).To(Equal(runtimeDefault))
+	})
+
+	It("should set the fsGroup of the controller and installer pods", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(ptr.Int64ToPtr(10001)))
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(ptr.Int64ToPtr(10001)))
+
+		cfg.IntrusionDetection.Spec.FSGroup = ptr.Int64ToPtr(2000)
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(ptr.Int64ToPtr(2000)))
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(ptr.Int64ToPtr(2000)))
+	})
+
+	It("should not render a PodDisruptionBudget for a single controller replica", func() {
+		toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(*deploy.Spec.Replicas).To(Equal(int32(1)))
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-controller", "tigera-intrusion-detection", "policy", "v1", "PodDisruptionBudget")).NotTo(BeNil())
+	})
+
+	It("should render a PodDisruptionBudget for multiple controller replicas unless it is skipped", func() {
+		cfg.IntrusionDetection.Spec.ControllerReplicas = ptr.Int32ToPtr(2)
+		toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(*deploy.Spec.Replicas).To(Equal(int32(2)))
+		pdb := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "policy", "v1", "PodDisruptionBudget").(*policyv1.PodDisruptionBudget)
+		Expect(pdb.Spec.MinAvailable.IntValue()).To(Equal(1))
+		Expect(pdb.Spec.Selector.MatchLabels).To(Equal(map[string]string{"k8s-app": "intrusion-detection-controller"}))
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-controller", "tigera-intrusion-detection", "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+
+		cfg.IntrusionDetection.Spec.SkipPodDisruptionBudget = ptr.BoolToPtr(true)
+		toCreate, toDelete = render.IntrusionDetection(cfg).Objects()
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "policy", "v1", "PodDisruptionBudget")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-controller", "tigera-intrusion-detection", "policy", "v1", "PodDisruptionBudget")).NotTo(BeNil())
+	})
+
+	It("should pass the Elasticsearch bulk size to the controller", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "ELASTIC_BULK_SIZE")))
+
+		cfg.IntrusionDetection.Spec.ElasticsearchBulkSize = ptr.Int32ToPtr(5000)
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_BULK_SIZE", Value: "5000"}))
+	})
+
+	It("should pass the Elasticsearch query timeout to the controller", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		for _, env := range deploy.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(Equal("ELASTIC_QUERY_TIMEOUT"))
+		}
+
+		cfg.IntrusionDetection.Spec.ElasticsearchQueryTimeout = "90s"
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_QUERY_TIMEOUT", Value: "90s"}))
+	})
+
+	It("should set the DNS configuration of the controller and installer pods", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.DNSPolicy).To(BeEmpty())
+		Expect(deploy.Spec.Template.Spec.DNSConfig).To(BeNil())
+
+		ndots := "1"
+		dnsConfig := &corev1.PodDNSConfig{Options: []corev1.PodDNSConfigOption{{Name: "ndots", Value: &ndots}}}
+		cfg.IntrusionDetection.Spec.DNSPolicy = corev1.DNSDefault
+		cfg.IntrusionDetection.Spec.DNSConfig = dnsConfig
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSDefault))
+		Expect(deploy.Spec.Template.Spec.DNSConfig).To(Equal(dnsConfig))
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.DNSPolicy).To(Equal(corev1.DNSDefault))
+		Expect(job.Spec.Template.Spec.DNSConfig).To(Equal(dnsConfig))
+	})
+
+	It("should clean up the installer Job after it has finished", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.TTLSecondsAfterFinished).To(Equal(ptr.Int32ToPtr(render.DefaultInstallerJobTTLSecondsAfterFinished)))
+
+		cfg.IntrusionDetection.Spec.InstallerJobTTLSecondsAfterFinished = ptr.Int32ToPtr(600)
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.TTLSecondsAfterFinished).To(Equal(ptr.Int32ToPtr(600)))
+	})
+
+	It("should not recreate an installer Job that has completed with the same configuration", func() {
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		hash := job.Annotations[render.InstallerJobHashAnnotation]
+		Expect(hash).NotTo(BeEmpty())
+
+		cfg.CompletedInstallerJobHash = hash
+		toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).To(BeNil())
+
+		By("rendering the Job again once the installer configuration changes")
+		cfg.IntrusionDetection.Spec.ImagePullPolicy = corev1.PullAlways
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Annotations[render.InstallerJobHashAnnotation]).NotTo(Equal(hash))
+	})
+
+	It("should run the installer as a CronJob when a schedule is set", func() {
+		toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "CronJob")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "CronJob")).NotTo(BeNil())
+
+		cfg.IntrusionDetection.Spec.InstallerSchedule = "0 */6 * * *"
+		toCreate, toDelete = render.IntrusionDetection(cfg).Objects()
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).NotTo(BeNil())
+		cronJob := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "CronJob").(*batchv1.CronJob)
+		Expect(cronJob.Spec.Schedule).To(Equal("0 */6 * * *"))
+		Expect(cronJob.Spec.ConcurrencyPolicy).To(Equal(batchv1.ForbidConcurrent))
+
+		// The runs use the same pod as the one-shot Job, but leave the selector to the Job controller.
+		jobSpec := cronJob.Spec.JobTemplate.Spec
+		Expect(jobSpec.Selector).To(BeNil())
+		Expect(jobSpec.Template).To(Equal(job.Spec.Template))
+		Expect(jobSpec.TTLSecondsAfterFinished).To(Equal(job.Spec.TTLSecondsAfterFinished))
+		Expect(jobSpec.PodFailurePolicy).To(Equal(job.Spec.PodFailurePolicy))
+	})
+
+	It("should add the user supplied pod annotations to the controller without clobbering those of the operator", func() {
+		cfg.IntrusionDetection.Spec.ComponentPodAnnotations = []operatorv1.IntrusionDetectionComponentPodAnnotations{{
+			ComponentName: operatorv1.ComponentNameIntrusionDetectionController,
+			Annotations: map[string]string{
+				"sidecar.istio.io/inject":                         "true",
+				"hash.operator.tigera.io/elasticsearch-configmap": "clobbered",
+			},
+		}}
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "true"))
+		Expect(deploy.Spec.Template.Annotations).To(HaveKeyWithValue("hash.operator.tigera.io/elasticsearch-configmap", cfg.ESClusterConfig.Annotation()))
+	})
+
+	It("should use the image overrides of the intrusion detection components", func() {
+		cfg.IntrusionDetection.Spec.ImageOverrides = map[string]string{
+			"tigera/intrusion-detection-controller":    "hotfix.io/tigera/intrusion-detection-controller:hotfix",
+			"tigera/intrusion-detection-job-installer": "hotfix.io/tigera/intrusion-detection-job-installer:hotfix",
+		}
+		component := render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(nil)).To(Succeed())
+		toCreate, _ := component.Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		controller := deploy.Spec.Template.Spec.Containers[0]
+		Expect(controller.Image).To(Equal("hotfix.io/tigera/intrusion-detection-controller:hotfix"))
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("hotfix.io/tigera/intrusion-detection-job-installer:hotfix"))
+
+		By("preferring the digests pinned by an ImageSet")
+		is := &operatorv1.ImageSet{Spec: operatorv1.ImageSetSpec{Images: []operatorv1.Image{
+			{Image: "tigera/intrusion-detection-controller", Digest: "sha256:controllerhash"},
+			{Image: "tigera/webhooks-processor", Digest: "sha256:webhookshash"},
+		}}}
+		component = render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(is)).To(Succeed())
+		toCreate, _ = component.Objects()
+		deploy = rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		controller = deploy.Spec.Template.Spec.Containers[0]
+		Expect(controller.Image).To(HaveSuffix("tigera/intrusion-detection-controller@sha256:controllerhash"))
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal("hotfix.io/tigera/intrusion-detection-job-installer:hotfix"))
+	})
+
+	It("should remap the image paths of the intrusion detection components", func() {
+		cfg.Installation.Registry = "mirror.io/"
+		cfg.IntrusionDetection.Spec.ImagePathRemappings = map[string]string{
+			"tigera/intrusion-detection-controller": "mirror/security/idc",
+		}
+		component := render.IntrusionDetection(cfg)
+		Expect(component.ResolveImages(nil)).To(Succeed())
+		toCreate, _ := component.Objects()
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(Equal(
+			fmt.Sprintf("mirror.io/mirror/security/idc:%s", components.ComponentIntrusionDetectionController.Version)))
+
+		By("keeping the default path of the images that aren't remapped")
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Image).To(Equal(
+			fmt.Sprintf("mirror.io/tigera/intrusion-detection-job-installer:%s", components.ComponentElasticTseeInstaller.Version)))
+	})
+
+	It("should remove the installer CronJob when the installer is skipped", func() {
+		cfg.IntrusionDetection.Spec.InstallerSchedule = "0 */6 * * *"
+		cfg.IntrusionDetection.Spec.SkipInstallerJob = ptr.BoolToPtr(true)
+		toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+		Expect(rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "CronJob")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "CronJob")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).NotTo(BeNil())
+	})
+
+	DescribeTable("should tell the installer which index setup steps to skip",
+		func(skipCreation, skipRollover bool, expectedEnvs []corev1.EnvVar) {
+			cfg.IntrusionDetection.Spec.SkipIndexCreation = &skipCreation
+			cfg.IntrusionDetection.Spec.SkipIndexRollover = &skipRollover
+			toCreate, toDelete := render.IntrusionDetection(cfg).Objects()
+
+			if skipCreation && skipRollover {
+				Expect(rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).To(BeNil())
+				Expect(rtest.GetResource(toDelete, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job")).NotTo(BeNil())
+				return
+			}
+			job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+			var envs []corev1.EnvVar
+			for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+				if env.Name == "ELASTIC_CREATE_INDICES" || env.Name == "ELASTIC_MANAGE_ROLLOVER" {
+					envs = append(envs, env)
+				}
+			}
+			Expect(envs).To(Equal(expectedEnvs))
+		},
+		Entry("both steps", false, false, nil),
+		Entry("rollover only", true, false, []corev1.EnvVar{{Name: "ELASTIC_CREATE_INDICES", Value: "false"}}),
+		Entry("index creation only", false, true, []corev1.EnvVar{{Name: "ELASTIC_MANAGE_ROLLOVER", Value: "false"}}),
+		Entry("neither step", true, true, nil),
+	)
+
+	It("should pass the index mapping limits to the installer only when they are set", func() {
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(HavePrefix("ELASTIC_INDEX_MAPPING_"))
+		}
+
+		cm := relasticsearch.NewClusterConfig("clusterTestName", 1, 1, 1).WithIndexMappingLimits(5000, 40).ConfigMap()
+		esClusterConfig, err := relasticsearch.NewClusterConfigFromConfigMap(cm)
+		Expect(err).NotTo(HaveOccurred())
+		cfg.ESClusterConfig = esClusterConfig
+		component = render.IntrusionDetection(cfg)
+		toCreate, _ = component.Objects()
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "ELASTIC_INDEX_MAPPING_TOTAL_FIELDS_LIMIT", Value: "5000"},
+			corev1.EnvVar{Name: "ELASTIC_INDEX_MAPPING_DEPTH_LIMIT", Value: "40"},
+		))
+	})
+
+	It("should pass the index prefix from the cluster config to the installer and the controller", func() {
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		for _, env := range job.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(Equal("ELASTIC_INDEX_PREFIX"))
+		}
+
+		cm := relasticsearch.NewClusterConfig("clusterTestName", 1, 1, 1).WithIndexPrefix("cluster-a").ConfigMap()
+		esClusterConfig, err := relasticsearch.NewClusterConfigFromConfigMap(cm)
+		Expect(err).NotTo(HaveOccurred())
+		cfg.ESClusterConfig = esClusterConfig
+		toCreate, _ = render.IntrusionDetection(cfg).Objects()
+		job = rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		Expect(job.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_INDEX_PREFIX", Value: "cluster-a"}))
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_INDEX_PREFIX", Value: "cluster-a"}))
+
+		By("rejecting a prefix that is not a valid index name")
+		cm.Data["indexPrefix"] = "_Cluster"
+		_, err = relasticsearch.NewClusterConfigFromConfigMap(cm)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should render a readiness probe that checks the controller health endpoint", func() {
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		controller := deploy.Spec.Template.Spec.Containers[0]
+		Expect(controller.Name).To(Equal("controller"))
+		Expect(controller.ReadinessProbe).NotTo(BeNil())
+		Expect(controller.ReadinessProbe.Exec.Command).To(Equal([]string{"/usr/bin/healthz", "readiness"}))
+	})
+
+	It("should not render proxy env vars when no proxy is configured", func() {
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		for _, container := range append(deploy.Spec.Template.Spec.Containers, job.Spec.Template.Spec.Containers...) {
+			for _, env := range container.Env {
+				Expect(env.Name).NotTo(BeElementOf("HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY"))
+			}
+		}
+	})
+
+	It("should render proxy env vars into all containers when a proxy is configured", func() {
+		cfg.Installation.ServiceCIDRs = []string{"10.96.0.0/12"}
+		cfg.ProxyConfig = &httpproxy.Config{
+			HTTPProxy:  "http://proxy.example.com:3128",
+			HTTPSProxy: "https://proxy.example.com:3129",
+			NoProxy:    "internal.example.com",
+		}
+		component := render.IntrusionDetection(cfg)
+		toCreate, _ := component.Objects()
+
+		deploy := rtest.GetResource(toCreate, "intrusion-detection-controller", "tigera-intrusion-detection", "apps", "v1", "Deployment").(*appsv1.Deployment)
+		job := rtest.GetResource(toCreate, "intrusion-detection-es-job-installer", "tigera-intrusion-detection", "batch", "v1", "Job").(*batchv1.Job)
+		containers := append(deploy.Spec.Template.Spec.Containers, job.Spec.Template.Spec.Containers...)
+		Expect(containers).To(HaveLen(3))
+		for _, container := range containers {
+			Expect(container.Env).To(ContainElements(
+				corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"},
+				corev1.EnvVar{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3129"},
+				corev1.EnvVar{Name: "NO_PROXY", Value: "internal.example.com,10.96.0.0/12,.svc,.cluster.local"},
+			))
+		}
+	})
+
 	It("should render an init container for pods when certificate management is enabled", func() {
 		ca, _ := tls.MakeCA(rmeta.DefaultOperatorCASignerName())
 		cert, _, _ := ca.Config.GetPEMBytes() // create a valid pem block
@@ -815,8 +1613,115 @@ var _ = Describe("Intrusion Detection rendering tests", func() {
 		csrInitContainer := intrusionDetectionDeploy.Spec.Template.Spec.InitContainers[0]
 		Expect(csrInitContainer.Name).To(Equal(fmt.Sprintf("%v-key-cert-provisioner", render.IntrusionDetectionTLSSecretName)))
 	})
+
+	It("should bind the ServiceAccount of an additional instance to issue CSRs when certificate management is enabled", func() {
+		ca, _ := tls.MakeCA(rmeta.DefaultOperatorCASignerName())
+		cert, _, _ := ca.Config.GetPEMBytes() // create a valid pem block
+		cfg.Installation.CertificateManagement = &operatorv1.CertificateManagement{CACert: cert}
+
+		certificateManager, err := certificatemanager.Create(cli, cfg.Installation, clusterDomain, common.OperatorNamespace(), certificatemanager.AllowCACreation())
+		Expect(err).NotTo(HaveOccurred())
+		cfg.IntrusionDetectionCertSecret, err = certificateManager.GetOrCreateKeyPair(cli, render.IntrusionDetectionTLSSecretName, common.OperatorNamespace(), []string{""})
+		Expect(err).NotTo(HaveOccurred())
+		cfg.Namespace = "tigera-intrusion-detection-tenant-a"
+		cfg.Instance = "tenant-a"
+
+		toCreate, _ := render.IntrusionDetection(cfg).Objects()
+		crb := rtest.GetResource(toCreate, "intrusion-detection-controller:csr-creator-tenant-a", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding").(*rbacv1.ClusterRoleBinding)
+		Expect(crb.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Name: "intrusion-detection-controller", Namespace: "tigera-intrusion-detection-tenant-a"}))
+	})
+
+	It("should check the rendered pods against the Pod Security Standard of the namespace", func() {
+		Expect(render.ValidateIntrusionDetectionPodSecurityStandard(cfg)).To(Succeed())
+
+		cfg.IntrusionDetection.Spec.ComponentPodAnnotations = []operatorv1.IntrusionDetectionComponentPodAnnotations{{
+			ComponentName: operatorv1.ComponentNameIntrusionDetectionController,
+			Annotations:   map[string]string{"container.apparmor.security.beta.kubernetes.io/controller": "unconfined"},
+		}}
+		Expect(render.ValidateIntrusionDetectionPodSecurityStandard(cfg)).To(MatchError(ContainSubstring(`container "controller" has the AppArmor profile unconfined`)))
+
+		cfg.IntrusionDetection.Spec.PodSecurityStandard = podSecurityStandardPtr(operatorv1.PodSecurityStandardPrivileged)
+		Expect(render.ValidateIntrusionDetectionPodSecurityStandard(cfg)).To(Succeed())
+	})
+
+	DescribeTable("should render pods that meet the restricted Pod Security Standard",
+		func(configure func()) {
+			configure()
+			cfg.IntrusionDetection.Spec.PodSecurityStandard = podSecurityStandardPtr(operatorv1.PodSecurityStandardRestricted)
+			toCreate, _ := render.IntrusionDetection(cfg).Objects()
+
+			ns := rtest.GetResource(toCreate, "tigera-intrusion-detection", "", "", "v1", "Namespace").(*corev1.Namespace)
+			Expect(ns.Labels).To(HaveKeyWithValue("pod-security.kubernetes.io/enforce", "restricted"))
+
+			var pods int
+			for _, obj := range toCreate {
+				var spec *corev1.PodSpec
+				switch o := obj.(type) {
+				case *appsv1.Deployment:
+					spec = &o.Spec.Template.Spec
+				case *batchv1.Job:
+					spec = &o.Spec.Template.Spec
+				case *batchv1.CronJob:
+					spec = &o.Spec.JobTemplate.Spec.Template.Spec
+				default:
+					continue
+				}
+				pods++
+				expectRestrictedPodSpec(spec, fmt.Sprintf("%T %s", obj, obj.GetName()))
+			}
+			Expect(pods).To(BeNumerically(">", 0))
+		},
+		Entry("default", func() {}),
+		Entry("managed cluster", func() { cfg.ManagedCluster = managedCluster }),
+		Entry("installer CronJob", func() { cfg.IntrusionDetection.Spec.InstallerSchedule = "@daily" }),
+		Entry("certificate management", func() {
+			ca, _ := tls.MakeCA(rmeta.DefaultOperatorCASignerName())
+			cert, _, _ := ca.Config.GetPEMBytes()
+			cfg.Installation.CertificateManagement = &operatorv1.CertificateManagement{CACert: cert}
+			certificateManager, err := certificatemanager.Create(cli, cfg.Installation, clusterDomain, common.OperatorNamespace(), certificatemanager.AllowCACreation())
+			Expect(err).NotTo(HaveOccurred())
+			cfg.IntrusionDetectionCertSecret, err = certificateManager.GetOrCreateKeyPair(cli, render.IntrusionDetectionTLSSecretName, common.OperatorNamespace(), []string{""})
+			Expect(err).NotTo(HaveOccurred())
+		}),
+	)
 })
 
+func podSecurityStandardPtr(pss operatorv1.PodSecurityStandard) *operatorv1.PodSecurityStandard {
+	return &pss
+}
+
+// expectRestrictedPodSpec asserts that the given pod spec meets the constraints of the restricted Pod Security Standard.
+func expectRestrictedPodSpec(spec *corev1.PodSpec, desc string) {
+	Expect(spec.HostNetwork).To(BeFalse(), desc)
+	Expect(spec.HostPID).To(BeFalse(), desc)
+	Expect(spec.HostIPC).To(BeFalse(), desc)
+	for _, v := range spec.Volumes {
+		Expect(v.HostPath).To(BeNil(), "%s volume %s", desc, v.Name)
+	}
+	podSeccomp := spec.SecurityContext != nil && spec.SecurityContext.SeccompProfile != nil
+	podNonRoot := spec.SecurityContext != nil && spec.SecurityContext.RunAsNonRoot != nil && *spec.SecurityContext.RunAsNonRoot
+	for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		cdesc := fmt.Sprintf("%s container %s", desc, c.Name)
+		sc := c.SecurityContext
+		Expect(sc).NotTo(BeNil(), cdesc)
+		Expect(sc.Privileged).NotTo(Equal(ptr.BoolToPtr(true)), cdesc)
+		Expect(sc.AllowPrivilegeEscalation).To(Equal(ptr.BoolToPtr(false)), cdesc)
+		Expect(podNonRoot || sc.RunAsNonRoot != nil && *sc.RunAsNonRoot).To(BeTrue(), cdesc)
+		if sc.RunAsUser != nil {
+			Expect(*sc.RunAsUser).NotTo(BeZero(), cdesc)
+		}
+		Expect(podSeccomp || sc.SeccompProfile != nil).To(BeTrue(), cdesc)
+		Expect(sc.Capabilities).NotTo(BeNil(), cdesc)
+		Expect(sc.Capabilities.Drop).To(ContainElement(corev1.Capability("ALL")), cdesc)
+		for _, capability := range sc.Capabilities.Add {
+			Expect(capability).To(Equal(corev1.Capability("NET_BIND_SERVICE")), cdesc)
+		}
+		for _, port := range c.Ports {
+			Expect(port.HostPort).To(BeZero(), cdesc)
+		}
+	}
+}
+
 func assertEnvVarlistMatch(envVars []corev1.EnvVar, expectedEnvVars []expectedEnvVar) {
 	for _, expected := range expectedEnvVars {
 		if expected.val != "" {
@@ -16,11 +16,14 @@ package dpi
 
 import (
 	"fmt"
+	"path"
+	"strconv"
 
 	"github.com/tigera/operator/pkg/tls/certificatemanagement"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -30,6 +33,7 @@ import (
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/components"
 	"github.com/tigera/operator/pkg/render"
+	"github.com/tigera/operator/pkg/render/common/configmap"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	"github.com/tigera/operator/pkg/render/common/meta"
 	"github.com/tigera/operator/pkg/render/common/networkpolicy"
@@ -46,8 +50,26 @@ const (
 	DefaultCPULimit                     = "1"
 	DefaultCPURequest                   = "100m"
 	DeepPacketInspectionLinseedRBACName = "tigera-dpi-linseed-permissions"
+
+	// TyphaCABundleVolumeName and TyphaCABundleMountPath are the volume and the directory of the custom CA bundle that
+	// DeepPacketInspection trusts when it connects to Typha.
+	TyphaCABundleVolumeName = "typha-ca-bundle"
+	TyphaCABundleMountPath  = "/etc/pki/typha-ca"
+	typhaCABundleFileName   = "ca.crt"
+
+	// WaitForTyphaContainerName is the name of the init container that holds DeepPacketInspection back until Typha is
+	// ready.
+	WaitForTyphaContainerName = "wait-for-typha"
 )
 
+// CPUPinningAnnotations are set on the DeepPacketInspection pods when they run pinned to dedicated CPUs, and tell
+// runtimes that honor them to keep other work off those CPUs.
+var CPUPinningAnnotations = map[string]string{
+	"cpu-load-balancing.crio.io": "disable",
+	"cpu-quota.crio.io":          "disable",
+	"irq-load-balancing.crio.io": "disable",
+}
+
 type DPIConfig struct {
 	IntrusionDetection *operatorv1.IntrusionDetection
 	Installation       *operatorv1.InstallationSpec
@@ -61,6 +83,15 @@ type DPIConfig struct {
 	ESClusterConfig    *relasticsearch.ClusterConfig
 	ClusterDomain      string
 	DPICertSecret      certificatemanagement.KeyPairInterface
+
+	// TyphaCABundle is the ConfigMap from the operator namespace that holds the CA bundle DeepPacketInspection trusts
+	// when it connects to Typha, as referenced by the IntrusionDetection. It is copied into the DeepPacketInspection
+	// namespace. When nil, the operator managed bundle of TyphaNodeTLS is trusted.
+	TyphaCABundle *corev1.ConfigMap
+
+	// StaleProfiles are the names of the DeepPacketInspection profiles that have a DaemonSet but are no longer in the
+	// IntrusionDetection. Their DaemonSets are deleted.
+	StaleProfiles []string
 }
 
 func DPI(cfg *DPIConfig) render.Component {
@@ -74,12 +105,14 @@ type dpiComponent struct {
 
 func (d *dpiComponent) ResolveImages(is *operatorv1.ImageSet) error {
 	var err error
-	d.dpiImage, err = components.GetReference(
+	d.dpiImage, err = components.GetReferenceWithRemappings(
 		components.ComponentDeepPacketInspection,
 		d.cfg.Installation.Registry,
 		d.cfg.Installation.ImagePath,
 		d.cfg.Installation.ImagePrefix,
-		is)
+		is,
+		d.cfg.IntrusionDetection.Spec.ImageOverrides,
+		d.cfg.IntrusionDetection.Spec.ImagePathRemappings)
 	if err != nil {
 		return err
 	}
@@ -100,22 +133,37 @@ func (d *dpiComponent) Objects() (objsToCreate, objsToDelete []client.Object) {
 			ObjectMeta: metav1.ObjectMeta{Name: relasticsearch.PublicCertSecret, Namespace: DeepPacketInspectionNamespace},
 		})
 		toDelete = append(toDelete, secret.ToRuntimeObjects(secret.CopyToNamespace(DeepPacketInspectionNamespace, d.cfg.PullSecrets...)...)...)
+		if d.cfg.TyphaCABundle != nil {
+			toDelete = append(toDelete, configmap.ToRuntimeObjects(configmap.CopyToNamespace(DeepPacketInspectionNamespace, d.cfg.TyphaCABundle)...)...)
+		}
 		toDelete = append(toDelete,
 			d.dpiServiceAccount(),
 			d.dpiClusterRole(),
 			d.dpiClusterRoleBinding(),
 			d.dpiDaemonset(),
 		)
+		toDelete = append(toDelete, d.profileDaemonsets()...)
+		toDelete = append(toDelete, d.staleProfileDaemonsets()...)
 	} else {
 		toCreate = append(toCreate, d.dpiAllowTigeraPolicy())
 		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(DeepPacketInspectionNamespace)...)...)
 		toCreate = append(toCreate, secret.ToRuntimeObjects(secret.CopyToNamespace(DeepPacketInspectionNamespace, d.cfg.PullSecrets...)...)...)
+		if d.cfg.TyphaCABundle != nil {
+			toCreate = append(toCreate, configmap.ToRuntimeObjects(configmap.CopyToNamespace(DeepPacketInspectionNamespace, d.cfg.TyphaCABundle)...)...)
+		}
+		// The operator's own ServiceAccount is replaced by one that the user provides.
+		if d.serviceAccountName() == DeepPacketInspectionName {
+			toCreate = append(toCreate, d.dpiServiceAccount())
+		} else {
+			toDelete = append(toDelete, d.dpiServiceAccount())
+		}
 		toCreate = append(toCreate,
-			d.dpiServiceAccount(),
 			d.dpiClusterRole(),
 			d.dpiClusterRoleBinding(),
 			d.dpiDaemonset(),
 		)
+		toCreate = append(toCreate, d.profileDaemonsets()...)
+		toDelete = append(toDelete, d.staleProfileDaemonsets()...)
 	}
 	if d.cfg.ManagementCluster {
 		// We always want to create these permissions when a management
@@ -158,27 +206,46 @@ func (d *dpiComponent) SupportedOSType() meta.OSType {
 }
 
 func (d *dpiComponent) dpiDaemonset() *appsv1.DaemonSet {
-	var terminationGracePeriod int64 = 0
 	var initContainers []corev1.Container
 	if d.cfg.TyphaNodeTLS.NodeSecret.UseCertificateManagement() {
 		initContainers = append(initContainers, d.cfg.TyphaNodeTLS.NodeSecret.InitContainer(DeepPacketInspectionNamespace))
 	}
+	if d.waitForTypha() {
+		initContainers = append(initContainers, d.waitForTyphaContainer())
+	}
+	container := d.dpiContainer()
+	if d.cpuPinning() {
+		// The pod only has the Guaranteed QoS class when its init containers have it too. Since init containers run
+		// before the DeepPacketInspection container, giving them its resources doesn't raise those of the pod.
+		for i := range initContainers {
+			initContainers[i].Resources = *container.Resources.DeepCopy()
+		}
+	}
 
 	podTemplate := &corev1.PodTemplateSpec{
 		ObjectMeta: metav1.ObjectMeta{
-			Annotations: d.dpiAnnotations(),
+			Annotations: render.IntrusionDetectionPodAnnotations(d.cfg.IntrusionDetection.Spec.ComponentPodAnnotations,
+				operatorv1.ComponentNameDeepPacketInspection, d.podAnnotations()),
 		},
 		Spec: corev1.PodSpec{
 			Tolerations:                   meta.TolerateAll,
+			Affinity:                      d.defaultNodeAffinity(),
 			ImagePullSecrets:              secret.GetReferenceList(d.cfg.PullSecrets),
-			ServiceAccountName:            DeepPacketInspectionName,
-			TerminationGracePeriodSeconds: &terminationGracePeriod,
+			ServiceAccountName:            d.serviceAccountName(),
+			AutomountServiceAccountToken:  d.automountServiceAccountToken(),
+			TerminationGracePeriodSeconds: d.cfg.IntrusionDetection.Spec.DPITerminationGracePeriodSeconds,
 			HostNetwork:                   true,
 			// Adjust DNS policy so we can access in-cluster services.
-			DNSPolicy:      corev1.DNSClusterFirstWithHostNet,
-			InitContainers: initContainers,
-			Containers:     []corev1.Container{d.dpiContainer()},
-			Volumes:        d.dpiVolumes(),
+			DNSPolicy:         corev1.DNSClusterFirstWithHostNet,
+			RuntimeClassName:  d.cfg.IntrusionDetection.Spec.DPIRuntimeClassName,
+			PriorityClassName: d.priorityClassName(),
+			InitContainers:    initContainers,
+			Containers:        []corev1.Container{container},
+			Volumes:           d.dpiVolumes(),
+			SecurityContext: &corev1.PodSecurityContext{
+				SeccompProfile: d.seccompProfile(),
+				Sysctls:        podSysctls(d.cfg.IntrusionDetection),
+			},
 		},
 	}
 	return &appsv1.DaemonSet{
@@ -193,17 +260,34 @@ func (d *dpiComponent) dpiDaemonset() *appsv1.DaemonSet {
 	}
 }
 
+// priorityClassName returns the PriorityClass of the DeepPacketInspection pods, or an empty string to leave them with
+// the default priority.
+func (d *dpiComponent) priorityClassName() string {
+	if name := d.cfg.IntrusionDetection.Spec.DPIPriorityClassName; name != nil {
+		return *name
+	}
+	return ""
+}
+
 func (d *dpiComponent) dpiContainer() corev1.Container {
 	sc := securitycontext.NewRootContext(d.cfg.Openshift)
 	sc.Capabilities.Add = []corev1.Capability{
 		"NET_ADMIN",
 		"NET_RAW",
 	}
+	sc.SeccompProfile = d.seccompProfile()
+	var resources corev1.ResourceRequirements
+	if r := render.IntrusionDetectionComponentResources(d.cfg.IntrusionDetection.Spec.ComponentResources, operatorv1.ComponentNameDeepPacketInspection); r != nil {
+		resources = *r
+	}
+	if d.cpuPinning() {
+		resources = guaranteedResources(resources)
+	}
 	dpiContainer := corev1.Container{
 		Name:            DeepPacketInspectionName,
 		Image:           d.dpiImage,
-		ImagePullPolicy: render.ImagePullPolicy(),
-		Resources:       *d.cfg.IntrusionDetection.Spec.ComponentResources[0].ResourceRequirements,
+		ImagePullPolicy: render.IntrusionDetectionImagePullPolicy(d.cfg.IntrusionDetection.Spec),
+		Resources:       resources,
 		Env:             d.dpiEnvVars(),
 		VolumeMounts:    d.dpiVolumeMounts(),
 		// On OpenShift Snort needs privileged access to access host network
@@ -214,6 +298,45 @@ func (d *dpiComponent) dpiContainer() corev1.Container {
 	return dpiContainer
 }
 
+// waitForTypha returns whether the DeepPacketInspection pods wait for Typha before they start.
+func (d *dpiComponent) waitForTypha() bool {
+	wait := d.cfg.IntrusionDetection.Spec.DPIWaitForTypha
+	return wait == nil || *wait
+}
+
+// waitForTyphaContainer returns an init container that blocks until the Typha service accepts connections, which it
+// only does once a Typha pod is ready.
+func (d *dpiComponent) waitForTyphaContainer() corev1.Container {
+	typha := fmt.Sprintf("%s.%s.svc.%s/%d", render.TyphaServiceName, common.CalicoNamespace, d.cfg.ClusterDomain, render.TyphaPort)
+	return corev1.Container{
+		Name:            WaitForTyphaContainerName,
+		Image:           d.dpiImage,
+		ImagePullPolicy: render.IntrusionDetectionImagePullPolicy(d.cfg.IntrusionDetection.Spec),
+		Command: []string{
+			"/bin/bash", "-c",
+			fmt.Sprintf("until timeout 5 bash -c '> /dev/tcp/%s'; do echo 'Waiting for Typha at %s'; sleep 5; done", typha, typha),
+		},
+		SecurityContext: securitycontext.NewNonRootContext(),
+	}
+}
+
+// seccompProfile returns the seccomp profile of the DeepPacketInspection pods, which is also set on the container so
+// that it is not overridden by the container's default profile.
+func (d *dpiComponent) seccompProfile() *corev1.SeccompProfile {
+	if p := d.cfg.IntrusionDetection.Spec.DPISeccompProfile; p != nil {
+		return p.DeepCopy()
+	}
+	return &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+}
+
+// typhaCAFile returns the path of the CA bundle DeepPacketInspection trusts when it connects to Typha.
+func (d *dpiComponent) typhaCAFile() string {
+	if d.cfg.TyphaCABundle != nil {
+		return path.Join(TyphaCABundleMountPath, typhaCABundleFileName)
+	}
+	return d.cfg.TyphaNodeTLS.TrustedBundle.MountPath()
+}
+
 func (d *dpiComponent) dpiVolumes() []corev1.Volume {
 	dirOrCreate := corev1.HostPathDirectoryOrCreate
 
@@ -232,6 +355,18 @@ func (d *dpiComponent) dpiVolumes() []corev1.Volume {
 		},
 	}
 
+	if d.cfg.TyphaCABundle != nil {
+		volumes = append(volumes, corev1.Volume{
+			Name: TyphaCABundleVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: d.cfg.TyphaCABundle.Name},
+					Items:                []corev1.KeyToPath{{Key: d.cfg.IntrusionDetection.Spec.DPITyphaCABundle.Key, Path: typhaCABundleFileName}},
+				},
+			},
+		})
+	}
+
 	if d.cfg.ManagedCluster {
 		volumes = append(volumes,
 			corev1.Volume{
@@ -258,7 +393,7 @@ func (d *dpiComponent) dpiEnvVars() []corev1.EnvVar {
 		},
 		{Name: "DPI_TYPHAK8SNAMESPACE", Value: common.CalicoNamespace},
 		{Name: "DPI_TYPHAK8SSERVICENAME", Value: render.TyphaServiceName},
-		{Name: "DPI_TYPHACAFILE", Value: d.cfg.TyphaNodeTLS.TrustedBundle.MountPath()},
+		{Name: "DPI_TYPHACAFILE", Value: d.typhaCAFile()},
 		{Name: "DPI_TYPHACERTFILE", Value: d.cfg.TyphaNodeTLS.NodeSecret.VolumeMountCertificateFilePath()},
 		{Name: "DPI_TYPHAKEYFILE", Value: d.cfg.TyphaNodeTLS.NodeSecret.VolumeMountKeyFilePath()},
 		{Name: "LINSEED_CLIENT_CERT", Value: d.cfg.DPICertSecret.VolumeMountCertificateFilePath()},
@@ -275,6 +410,24 @@ func (d *dpiComponent) dpiEnvVars() []corev1.EnvVar {
 	if d.cfg.TyphaNodeTLS.TyphaURISAN != "" {
 		env = append(env, corev1.EnvVar{Name: "DPI_TYPHAURISAN", Value: d.cfg.TyphaNodeTLS.TyphaURISAN})
 	}
+	if severity := d.cfg.IntrusionDetection.Spec.DPILogSeverity; severity != nil {
+		env = append(env, corev1.EnvVar{Name: "DPI_LOGSEVERITYSCREEN", Value: string(*severity)})
+	}
+	if size := d.cfg.IntrusionDetection.Spec.DPIPacketBufferSize; size != "" {
+		// The size is validated by the controller, so only valid quantities are expected here.
+		if q, err := resource.ParseQuantity(size); err == nil {
+			env = append(env, corev1.EnvVar{Name: "DPI_PACKETBUFFERSIZE", Value: strconv.FormatInt(q.Value(), 10)})
+		}
+	}
+	if regex := d.cfg.IntrusionDetection.Spec.DPIInterfaceRegex; regex != nil {
+		env = append(env, corev1.EnvVar{Name: "DPI_INTERFACEREGEX", Value: *regex})
+	}
+	if filter := d.cfg.IntrusionDetection.Spec.DPIBPFFilter; filter != nil {
+		env = append(env, corev1.EnvVar{Name: "DPI_BPFFILTER", Value: *filter})
+	}
+	if threads := d.cfg.IntrusionDetection.Spec.DPIWorkerThreads; threads != nil {
+		env = append(env, corev1.EnvVar{Name: "DPI_WORKERTHREADS", Value: strconv.Itoa(int(*threads))})
+	}
 	return env
 }
 
@@ -285,6 +438,9 @@ func (d *dpiComponent) dpiVolumeMounts() []corev1.VolumeMount {
 		corev1.VolumeMount{MountPath: "/var/log/calico/snort-alerts", Name: "log-snort-alters"},
 		d.cfg.DPICertSecret.VolumeMount(d.SupportedOSType()),
 	)
+	if d.cfg.TyphaCABundle != nil {
+		volumeMounts = append(volumeMounts, corev1.VolumeMount{Name: TyphaCABundleVolumeName, MountPath: TyphaCABundleMountPath, ReadOnly: true})
+	}
 	if d.cfg.ManagedCluster {
 		volumeMounts = append(volumeMounts,
 			corev1.VolumeMount{
@@ -310,6 +466,33 @@ func (d *dpiComponent) dpiReadinessProbes() *corev1.Probe {
 	}
 }
 
+// serviceAccountName returns the name of the ServiceAccount DeepPacketInspection runs as.
+func (d *dpiComponent) serviceAccountName() string {
+	return ServiceAccountName(d.cfg.IntrusionDetection)
+}
+
+// ServiceAccountName returns the name of the ServiceAccount DeepPacketInspection runs as for the given
+// IntrusionDetection, which may be nil.
+func ServiceAccountName(ids *operatorv1.IntrusionDetection) string {
+	if ids != nil {
+		if sa := ids.Spec.ServiceAccounts; sa != nil && sa.DeepPacketInspection != "" {
+			return sa.DeepPacketInspection
+		}
+	}
+	return DeepPacketInspectionName
+}
+
+// automountServiceAccountToken returns the automountServiceAccountToken of the DeepPacketInspection pods, which is nil
+// to keep the setting of the ServiceAccount.
+func (d *dpiComponent) automountServiceAccountToken() *bool {
+	if d.cfg.IntrusionDetection != nil {
+		if t := d.cfg.IntrusionDetection.Spec.AutomountServiceAccountTokens; t != nil {
+			return t.DeepPacketInspection
+		}
+	}
+	return nil
+}
+
 func (d *dpiComponent) dpiServiceAccount() *corev1.ServiceAccount {
 	return &corev1.ServiceAccount{
 		TypeMeta: metav1.TypeMeta{Kind: "ServiceAccount", APIVersion: "v1"},
@@ -335,7 +518,7 @@ func (d *dpiComponent) dpiClusterRoleBinding() *rbacv1.ClusterRoleBinding {
 		Subjects: []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
-				Name:      DeepPacketInspectionName,
+				Name:      d.serviceAccountName(),
 				Namespace: DeepPacketInspectionNamespace,
 			},
 		},
@@ -357,7 +540,7 @@ func (d *dpiComponent) dpiLinseedAccessClusterRoleBinding() *rbacv1.ClusterRoleB
 		Subjects: []rbacv1.Subject{
 			{
 				Kind:      "ServiceAccount",
-				Name:      DeepPacketInspectionName,
+				Name:      d.serviceAccountName(),
 				Namespace: DeepPacketInspectionNamespace,
 			},
 		},
@@ -425,6 +608,51 @@ func (d *dpiComponent) dpiLinseedAccessClusterRole() *rbacv1.ClusterRole {
 	}
 }
 
+// cpuPinning returns whether DeepPacketInspection runs pinned to dedicated CPUs.
+func (d *dpiComponent) cpuPinning() bool {
+	pinning := d.cfg.IntrusionDetection.Spec.DPICPUPinning
+	return pinning != nil && *pinning
+}
+
+// guaranteedResources returns the given resource requirements with the requests of CPU and memory equal to their
+// limits, which gives the pods the Guaranteed QoS class. A resource that only has a request is limited to it.
+func guaranteedResources(resources corev1.ResourceRequirements) corev1.ResourceRequirements {
+	resources = *resources.DeepCopy()
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		q, ok := resources.Limits[name]
+		if !ok {
+			if q, ok = resources.Requests[name]; !ok {
+				continue
+			}
+		}
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		resources.Limits[name] = q.DeepCopy()
+		resources.Requests[name] = q.DeepCopy()
+	}
+	return resources
+}
+
+// podAnnotations returns the annotations of the DeepPacketInspection pods, which include the CPU pinning hints for
+// the runtime when DeepPacketInspection runs pinned to dedicated CPUs.
+func (d *dpiComponent) podAnnotations() map[string]string {
+	annotations := d.dpiAnnotations()
+	if !d.cpuPinning() {
+		return annotations
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	for k, v := range CPUPinningAnnotations {
+		annotations[k] = v
+	}
+	return annotations
+}
+
 func (d *dpiComponent) dpiAnnotations() map[string]string {
 	if d.cfg.HasNoDPIResource || d.cfg.HasNoLicense {
 		return nil
@@ -494,7 +722,7 @@ func (d *dpiComponent) dpiAllowTigeraPolicy() *v3.NetworkPolicy {
 		Spec: v3.NetworkPolicySpec{
 			Order:    &networkpolicy.HighPrecedenceOrder,
 			Tier:     networkpolicy.TigeraComponentTierName,
-			Selector: networkpolicy.KubernetesAppSelector(DeepPacketInspectionName),
+			Selector: networkpolicy.KubernetesAppSelector(d.daemonSetNames()...),
 			Types:    []v3.PolicyType{v3.PolicyTypeEgress},
 			Egress:   egressRules,
 		},
@@ -15,6 +15,8 @@
 package dpi_test
 
 import (
+	"fmt"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
 	. "github.com/onsi/gomega"
@@ -36,6 +38,7 @@ import (
 	"github.com/tigera/operator/pkg/common"
 	"github.com/tigera/operator/pkg/controller/certificatemanager"
 	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/ptr"
 	"github.com/tigera/operator/pkg/render"
 	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
 	rtest "github.com/tigera/operator/pkg/render/common/test"
@@ -448,6 +451,388 @@ var _ = Describe("DPI rendering tests", func() {
 		validateDPIComponents(resources, true)
 	})
 
+	It("should render the configured termination grace period on the DPI daemonset", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNil())
+
+		var gracePeriod int64 = 60
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPITerminationGracePeriodSeconds = &gracePeriod
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(*ds.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(gracePeriod))
+	})
+
+	It("should render the packet buffer size env var when configured", func() {
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIPacketBufferSize = "64Mi"
+		cfg.IntrusionDetection = ids2
+
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "DPI_PACKETBUFFERSIZE", Value: "67108864"}))
+	})
+
+	It("should restrict the capture to the configured interfaces and BPF filter", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		for _, env := range ds.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(BeElementOf("DPI_INTERFACEREGEX", "DPI_BPFFILTER"))
+		}
+
+		regex, filter := "^eth[0-9]+$", "tcp port 80 or tcp port 443"
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIInterfaceRegex = &regex
+		ids2.Spec.DPIBPFFilter = &filter
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "DPI_INTERFACEREGEX", Value: "^eth[0-9]+$"},
+			corev1.EnvVar{Name: "DPI_BPFFILTER", Value: "tcp port 80 or tcp port 443"},
+		))
+	})
+
+	It("should set the number of worker threads only when configured", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).NotTo(ContainElement(HaveField("Name", "DPI_WORKERTHREADS")))
+
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIWorkerThreads = ptr.Int32ToPtr(8)
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "DPI_WORKERTHREADS", Value: "8"}))
+	})
+
+	It("should render a Guaranteed QoS DaemonSet with the CPU pinning annotations when CPU pinning is on", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).NotTo(HaveKey("cpu-quota.crio.io"))
+
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPICPUPinning = ptr.BoolToPtr(true)
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.InitContainers).NotTo(BeEmpty())
+		for _, c := range append(ds.Spec.Template.Spec.InitContainers, ds.Spec.Template.Spec.Containers...) {
+			Expect(c.Resources.Requests).To(Equal(c.Resources.Limits))
+			Expect(c.Resources.Limits).To(HaveKey(corev1.ResourceCPU))
+			Expect(c.Resources.Limits).To(HaveKey(corev1.ResourceMemory))
+		}
+		Expect(*ds.Spec.Template.Spec.Containers[0].Resources.Requests.Memory()).To(Equal(resource.MustParse(dpi.DefaultMemoryLimit)))
+		for k, v := range dpi.CPUPinningAnnotations {
+			Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue(k, v))
+		}
+	})
+
+	It("should render the log severity env var only when configured", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		for _, env := range ds.Spec.Template.Spec.Containers[0].Env {
+			Expect(env.Name).NotTo(Equal("DPI_LOGSEVERITYSCREEN"))
+		}
+
+		severity := operatorv1.LogLevelDebug
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPILogSeverity = &severity
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "DPI_LOGSEVERITYSCREEN", Value: "Debug"}))
+	})
+
+	It("should render the runtime class name only when configured", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.RuntimeClassName).To(BeNil())
+
+		runtimeClass := "runc"
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIRuntimeClassName = &runtimeClass
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.RuntimeClassName).To(Equal(&runtimeClass))
+	})
+
+	It("should render the DeepPacketInspection priority class only when configured", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.PriorityClassName).To(BeEmpty())
+
+		priorityClass := "dpi-critical"
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIPriorityClassName = &priorityClass
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.PriorityClassName).To(Equal(priorityClass))
+	})
+
+	It("should render the configured sysctls on the pod spec", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.SecurityContext.Sysctls).To(BeEmpty())
+
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPISysctls = []corev1.Sysctl{{Name: "kernel.shm_rmid_forced", Value: "1"}}
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.SecurityContext.Sysctls).To(Equal([]corev1.Sysctl{{Name: "kernel.shm_rmid_forced", Value: "1"}}))
+		for _, c := range ds.Spec.Template.Spec.InitContainers {
+			if c.SecurityContext != nil {
+				Expect(c.SecurityContext.Privileged).NotTo(Equal(ptr.BoolToPtr(true)))
+			}
+		}
+	})
+
+	It("should only accept the safe sysctls outside the network namespace", func() {
+		Expect(dpi.ValidateSysctl("kernel.shm_rmid_forced")).To(Succeed())
+		Expect(dpi.ValidateSysctl("net.core.rmem_max")).To(MatchError(ContainSubstring("network namespace")))
+		Expect(dpi.ValidateSysctl("net/ipv4/tcp_rmem")).To(MatchError(ContainSubstring("network namespace")))
+		Expect(dpi.ValidateSysctl("kernel.msgmax")).To(MatchError(ContainSubstring("not a sysctl that Kubernetes considers safe")))
+	})
+
+	It("should render a DaemonSet for each profile and keep the default DaemonSet off their nodes", func() {
+		severity := operatorv1.LogLevelDebug
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIWorkerThreads = ptr.Int32ToPtr(2)
+		ids2.Spec.DPIProfiles = []operatorv1.DPIProfile{
+			{
+				Name:          "core",
+				NodeSelector:  map[string]string{"node-role": "core"},
+				WorkerThreads: ptr.Int32ToPtr(16),
+				ResourceRequirements: &corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+				},
+			},
+			{
+				Name:         "edge",
+				NodeSelector: map[string]string{"node-role": "edge", "zone": "far"},
+				LogSeverity:  &severity,
+			},
+		}
+		cfg.IntrusionDetection = ids2
+		cfg.StaleProfiles = []string{"old"}
+
+		createResources, deleteResources := dpi.DPI(cfg).Objects()
+
+		core := rtest.GetResource(createResources, "tigera-dpi-core", dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(core.Labels).To(Equal(map[string]string{dpi.ProfileLabel: "core"}))
+		Expect(core.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"node-role": "core"}))
+		Expect(core.Spec.Template.Spec.Affinity).To(BeNil())
+		Expect(core.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "DPI_WORKERTHREADS", Value: "16"}))
+		Expect(core.Spec.Template.Spec.Containers[0].Resources.Limits.Cpu().String()).To(Equal("8"))
+
+		edge := rtest.GetResource(createResources, "tigera-dpi-edge", dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(edge.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"node-role": "edge", "zone": "far"}))
+		Expect(edge.Spec.Template.Spec.Containers[0].Env).To(ContainElements(
+			corev1.EnvVar{Name: "DPI_WORKERTHREADS", Value: "2"},
+			corev1.EnvVar{Name: "DPI_LOGSEVERITYSCREEN", Value: "Debug"},
+		))
+
+		// The default DaemonSet runs on the nodes that aren't core nodes, and that aren't edge nodes in the far zone.
+		ds := rtest.GetResource(createResources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.NodeSelector).To(BeEmpty())
+		Expect(ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms).To(ConsistOf(
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "node-role", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"core", "edge"}},
+			}},
+			corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{
+				{Key: "node-role", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"core"}},
+				{Key: "zone", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"far"}},
+			}},
+		))
+		Expect(ds.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "DPI_WORKERTHREADS", Value: "2"}))
+
+		Expect(rtest.GetResource(deleteResources, "tigera-dpi-old", dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet")).NotTo(BeNil())
+	})
+
+	It("should keep a single node affinity term for profiles that select nodes by the same label", func() {
+		ids2 := ids.DeepCopy()
+		for i := 0; i < 40; i++ {
+			name := fmt.Sprintf("rack-%d", i)
+			ids2.Spec.DPIProfiles = append(ids2.Spec.DPIProfiles, operatorv1.DPIProfile{Name: name, NodeSelector: map[string]string{"rack": name}})
+		}
+		Expect(dpi.ValidateProfileNodeSelectors(ids2.Spec.DPIProfiles)).To(Succeed())
+		cfg.IntrusionDetection = ids2
+
+		createResources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(createResources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		terms := ds.Spec.Template.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		Expect(terms).To(HaveLen(1))
+		Expect(terms[0].MatchExpressions).To(HaveLen(1))
+		Expect(terms[0].MatchExpressions[0].Values).To(HaveLen(40))
+	})
+
+	It("should reject profiles whose node selectors need too many node affinity terms", func() {
+		var profiles []operatorv1.DPIProfile
+		for i := 0; i < 6; i++ {
+			profiles = append(profiles, operatorv1.DPIProfile{
+				Name:         fmt.Sprintf("profile-%d", i),
+				NodeSelector: map[string]string{fmt.Sprintf("a-%d", i): "true", fmt.Sprintf("b-%d", i): "true"},
+			})
+		}
+		Expect(dpi.ValidateProfileNodeSelectors(profiles)).To(MatchError(ContainSubstring("more than 32 node affinity terms")))
+		Expect(dpi.ValidateProfileNodeSelectors(profiles[:5])).To(Succeed())
+	})
+
+	It("should wait for Typha before starting unless disabled", func() {
+		component := dpi.DPI(cfg)
+		Expect(component.ResolveImages(nil)).To(Succeed())
+		resources, _ := component.Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.InitContainers).To(HaveLen(1))
+		initContainer := ds.Spec.Template.Spec.InitContainers[0]
+		Expect(initContainer.Name).To(Equal(dpi.WaitForTyphaContainerName))
+		Expect(initContainer.Image).To(Equal(ds.Spec.Template.Spec.Containers[0].Image))
+		Expect(initContainer.Command).To(HaveLen(3))
+		Expect(initContainer.Command[2]).To(ContainSubstring("/dev/tcp/calico-typha.calico-system.svc.cluster.local/5473"))
+
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPIWaitForTypha = ptr.BoolToPtr(false)
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.InitContainers).To(BeEmpty())
+	})
+
+	It("should add the user supplied pod annotations to the DPI DaemonSet template", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		operatorAnnotations := ds.Spec.Template.Annotations
+		Expect(operatorAnnotations).NotTo(BeEmpty())
+
+		ids2 := ids.DeepCopy()
+		ids2.Spec.ComponentPodAnnotations = []operatorv1.IntrusionDetectionComponentPodAnnotations{
+			{
+				ComponentName: operatorv1.ComponentNameDeepPacketInspection,
+				Annotations:   map[string]string{"sidecar.istio.io/inject": "false"},
+			},
+			{
+				ComponentName: operatorv1.ComponentNameIntrusionDetectionController,
+				Annotations:   map[string]string{"sidecar.istio.io/inject": "true"},
+			},
+		}
+		// An annotation of the operator can't be clobbered.
+		for k := range operatorAnnotations {
+			ids2.Spec.ComponentPodAnnotations[0].Annotations[k] = "clobbered"
+		}
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue("sidecar.istio.io/inject", "false"))
+		for k, v := range operatorAnnotations {
+			Expect(ds.Spec.Template.Annotations).To(HaveKeyWithValue(k, v))
+		}
+		Expect(ds.Spec.Template.Annotations).To(HaveLen(len(operatorAnnotations) + 1))
+	})
+
+	It("should apply the configured image pull policy", func() {
+		ids2 := ids.DeepCopy()
+		ids2.Spec.ImagePullPolicy = corev1.PullAlways
+		cfg.IntrusionDetection = ids2
+
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].ImagePullPolicy).To(Equal(corev1.PullAlways))
+	})
+
+	It("should render the RuntimeDefault seccomp profile unless a profile is configured", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		runtimeDefault := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+		Expect(ds.Spec.Template.Spec.SecurityContext.SeccompProfile).To(Equal(runtimeDefault))
+		Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.SeccompProfile).To(Equal(runtimeDefault))
+
+		profile := "profiles/dpi.json"
+		localhost := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost, LocalhostProfile: &profile}
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPISeccompProfile = localhost
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.SecurityContext.SeccompProfile).To(Equal(localhost))
+		Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.SeccompProfile).To(Equal(localhost))
+		Expect(ds.Spec.Template.Spec.Containers[0].SecurityContext.Capabilities.Add).To(ConsistOf(corev1.Capability("NET_ADMIN"), corev1.Capability("NET_RAW")))
+	})
+
+	It("should set automountServiceAccountToken on the pods only when configured", func() {
+		resources, _ := dpi.DPI(cfg).Objects()
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+
+		ids2 := ids.DeepCopy()
+		ids2.Spec.AutomountServiceAccountTokens = &operatorv1.IntrusionDetectionAutomountServiceAccountTokens{DeepPacketInspection: ptr.BoolToPtr(false)}
+		cfg.IntrusionDetection = ids2
+
+		resources, _ = dpi.DPI(cfg).Objects()
+		ds = rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.AutomountServiceAccountToken).To(Equal(ptr.BoolToPtr(false)))
+	})
+
+	It("should mount a custom Typha CA bundle and trust it for Typha", func() {
+		ids2 := ids.DeepCopy()
+		ids2.Spec.DPITyphaCABundle = &corev1.ConfigMapKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "typha-custom-ca"},
+			Key:                  "bundle.pem",
+		}
+		cfg.IntrusionDetection = ids2
+		cfg.TyphaCABundle = &corev1.ConfigMap{
+			TypeMeta:   metav1.TypeMeta{Kind: "ConfigMap", APIVersion: "v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: "typha-custom-ca", Namespace: common.OperatorNamespace()},
+			Data:       map[string]string{"bundle.pem": "ca"},
+		}
+
+		resources, _ := dpi.DPI(cfg).Objects()
+		Expect(rtest.GetResource(resources, "typha-custom-ca", dpi.DeepPacketInspectionNamespace, "", "v1", "ConfigMap")).NotTo(BeNil())
+		ds := rtest.GetResource(resources, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Volumes).To(ContainElement(corev1.Volume{
+			Name: dpi.TyphaCABundleVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "typha-custom-ca"},
+					Items:                []corev1.KeyToPath{{Key: "bundle.pem", Path: "ca.crt"}},
+				},
+			},
+		}))
+		container := ds.Spec.Template.Spec.Containers[0]
+		Expect(container.VolumeMounts).To(ContainElement(corev1.VolumeMount{Name: dpi.TyphaCABundleVolumeName, MountPath: dpi.TyphaCABundleMountPath, ReadOnly: true}))
+		Expect(container.Env).To(ContainElement(corev1.EnvVar{Name: "DPI_TYPHACAFILE", Value: "/etc/pki/typha-ca/ca.crt"}))
+	})
+
+	It("should use a user provided service account instead of creating one", func() {
+		ids2 := ids.DeepCopy()
+		ids2.Spec.ServiceAccounts = &operatorv1.IntrusionDetectionServiceAccounts{DeepPacketInspection: "dpi-irsa"}
+		cfg.IntrusionDetection = ids2
+
+		toCreate, toDelete := dpi.DPI(cfg).Objects()
+		Expect(rtest.GetResource(toCreate, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "", "v1", "ServiceAccount")).To(BeNil())
+		Expect(rtest.GetResource(toDelete, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "", "v1", "ServiceAccount")).NotTo(BeNil())
+
+		ds := rtest.GetResource(toCreate, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.ServiceAccountName).To(Equal("dpi-irsa"))
+		crb := rtest.GetResource(toCreate, dpi.DeepPacketInspectionName, "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding").(*rbacv1.ClusterRoleBinding)
+		Expect(crb.Subjects).To(ConsistOf(rbacv1.Subject{Kind: "ServiceAccount", Name: "dpi-irsa", Namespace: dpi.DeepPacketInspectionNamespace}))
+	})
+
 	It("should delete resources for deep packet inspection if there is no valid product license", func() {
 		cfg.HasNoLicense = true
 		component := dpi.DPI(cfg)
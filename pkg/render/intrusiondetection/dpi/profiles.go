@@ -0,0 +1,230 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dpi
+
+import (
+	"fmt"
+	"sort"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// ProfileLabel is the label of the DaemonSets of the DeepPacketInspection profiles, whose value is the name of the
+// profile.
+const ProfileLabel = "operator.tigera.io/dpi-profile"
+
+// ProfileDaemonSetName returns the name of the DaemonSet of the DeepPacketInspection profile with the given name.
+func ProfileDaemonSetName(profile string) string {
+	return DeepPacketInspectionName + "-" + profile
+}
+
+// profileDaemonsets returns a DaemonSet for each of the DeepPacketInspection profiles of the IntrusionDetection.
+func (d *dpiComponent) profileDaemonsets() []client.Object {
+	var objs []client.Object
+	for i := range d.cfg.IntrusionDetection.Spec.DPIProfiles {
+		objs = append(objs, d.profileDaemonset(&d.cfg.IntrusionDetection.Spec.DPIProfiles[i]))
+	}
+	return objs
+}
+
+// staleProfileDaemonsets returns the DaemonSets of the profiles that were removed from the IntrusionDetection.
+func (d *dpiComponent) staleProfileDaemonsets() []client.Object {
+	var objs []client.Object
+	for _, name := range d.cfg.StaleProfiles {
+		objs = append(objs, &appsv1.DaemonSet{
+			TypeMeta:   metav1.TypeMeta{Kind: "DaemonSet", APIVersion: "apps/v1"},
+			ObjectMeta: metav1.ObjectMeta{Name: ProfileDaemonSetName(name), Namespace: DeepPacketInspectionNamespace},
+		})
+	}
+	return objs
+}
+
+// profileDaemonset returns the DaemonSet of a DeepPacketInspection profile. It is the default DaemonSet rendered with
+// the settings of the profile in place of those of the IntrusionDetection, and restricted to the nodes the profile
+// selects.
+func (d *dpiComponent) profileDaemonset(profile *operatorv1.DPIProfile) *appsv1.DaemonSet {
+	ids := d.cfg.IntrusionDetection.DeepCopy()
+	ids.Spec.DPIProfiles = nil
+	if profile.ResourceRequirements != nil {
+		resources := []operatorv1.IntrusionDetectionComponentResource{{
+			ComponentName:        operatorv1.ComponentNameDeepPacketInspection,
+			ResourceRequirements: profile.ResourceRequirements.DeepCopy(),
+		}}
+		for _, cr := range ids.Spec.ComponentResources {
+			if cr.ComponentName != operatorv1.ComponentNameDeepPacketInspection {
+				resources = append(resources, cr)
+			}
+		}
+		ids.Spec.ComponentResources = resources
+	}
+	if profile.WorkerThreads != nil {
+		ids.Spec.DPIWorkerThreads = profile.WorkerThreads
+	}
+	if profile.InterfaceRegex != nil {
+		ids.Spec.DPIInterfaceRegex = profile.InterfaceRegex
+	}
+	if profile.BPFFilter != nil {
+		ids.Spec.DPIBPFFilter = profile.BPFFilter
+	}
+	if profile.LogSeverity != nil {
+		ids.Spec.DPILogSeverity = profile.LogSeverity
+	}
+
+	cfg := *d.cfg
+	cfg.IntrusionDetection = ids
+	pd := &dpiComponent{cfg: &cfg, dpiImage: d.dpiImage}
+
+	ds := pd.dpiDaemonset()
+	ds.Name = ProfileDaemonSetName(profile.Name)
+	if ds.Labels == nil {
+		ds.Labels = map[string]string{}
+	}
+	ds.Labels[ProfileLabel] = profile.Name
+	if ds.Spec.Template.Spec.NodeSelector == nil {
+		ds.Spec.Template.Spec.NodeSelector = map[string]string{}
+	}
+	for k, v := range profile.NodeSelector {
+		ds.Spec.Template.Spec.NodeSelector[k] = v
+	}
+	return ds
+}
+
+// maxDefaultNodeAffinityTerms bounds the terms of the node affinity of the default DaemonSet. Their number can grow
+// with the product of the numbers of labels of the profiles, so profiles that would need more are rejected.
+const maxDefaultNodeAffinityTerms = 32
+
+// ValidateProfileNodeSelectors returns an error when the node selectors of the profiles would need a node affinity
+// with more than maxDefaultNodeAffinityTerms terms to keep the default DaemonSet off their nodes.
+func ValidateProfileNodeSelectors(profiles []operatorv1.DPIProfile) error {
+	_, err := defaultNodeSelectorTerms(profiles)
+	return err
+}
+
+// defaultNodeAffinity returns the node affinity that keeps the default DaemonSet off the nodes that the profiles
+// select, or nil when there are no profiles.
+func (d *dpiComponent) defaultNodeAffinity() *corev1.Affinity {
+	profiles := d.cfg.IntrusionDetection.Spec.DPIProfiles
+	if len(profiles) == 0 {
+		return nil
+	}
+	// The profiles are validated before they are rendered, so the terms are within bounds.
+	terms, _ := defaultNodeSelectorTerms(profiles)
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{NodeSelectorTerms: terms},
+		},
+	}
+}
+
+// notInTerm is a node selector term made of NotIn expressions, mapping each label to the values it must not have.
+type notInTerm map[string]map[string]bool
+
+// subsumes returns whether every node that matches other also matches t, which makes other redundant next to t.
+func (t notInTerm) subsumes(other notInTerm) bool {
+	for k, values := range t {
+		for v := range values {
+			if !other[k][v] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// defaultNodeSelectorTerms returns the node selector terms that keep the default DaemonSet off the nodes that the
+// profiles select. A node is excluded when it has all the labels of a profile, so the terms require, for every
+// profile, one of its labels to be missing or different. Since the terms of a node affinity are ORed and the
+// expressions of a term ANDed, this takes a term for every combination of a label of each profile. Expressions on the
+// same label are merged into one, and terms that another term already covers are dropped, so profiles that select
+// nodes by the same label only need a single term. It returns an error once more than maxDefaultNodeAffinityTerms
+// terms would be needed.
+func defaultNodeSelectorTerms(profiles []operatorv1.DPIProfile) ([]corev1.NodeSelectorTerm, error) {
+	terms := []notInTerm{{}}
+	for _, profile := range profiles {
+		var next []notInTerm
+		for _, term := range terms {
+			for k, v := range profile.NodeSelector {
+				t := notInTerm{}
+				for tk, values := range term {
+					t[tk] = map[string]bool{}
+					for tv := range values {
+						t[tk][tv] = true
+					}
+				}
+				if t[k] == nil {
+					t[k] = map[string]bool{}
+				}
+				t[k][v] = true
+				next = append(next, t)
+			}
+		}
+
+		terms = nil
+		for i, t := range next {
+			redundant := false
+			for j, other := range next {
+				// Of two terms that cover each other, only the first is kept.
+				if i != j && other.subsumes(t) && (!t.subsumes(other) || j < i) {
+					redundant = true
+					break
+				}
+			}
+			if !redundant {
+				terms = append(terms, t)
+			}
+		}
+		if len(terms) > maxDefaultNodeAffinityTerms {
+			return nil, fmt.Errorf("node selectors would need more than %d node affinity terms to keep the default DaemonSet off their nodes; select the nodes of the profiles by fewer or shared labels", maxDefaultNodeAffinityTerms)
+		}
+	}
+
+	selectorTerms := make([]corev1.NodeSelectorTerm, 0, len(terms))
+	for _, t := range terms {
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		var expressions []corev1.NodeSelectorRequirement
+		for _, k := range keys {
+			values := make([]string, 0, len(t[k]))
+			for v := range t[k] {
+				values = append(values, v)
+			}
+			sort.Strings(values)
+			expressions = append(expressions, corev1.NodeSelectorRequirement{Key: k, Operator: corev1.NodeSelectorOpNotIn, Values: values})
+		}
+		selectorTerms = append(selectorTerms, corev1.NodeSelectorTerm{MatchExpressions: expressions})
+	}
+	sort.Slice(selectorTerms, func(i, j int) bool {
+		return fmt.Sprint(selectorTerms[i].MatchExpressions) < fmt.Sprint(selectorTerms[j].MatchExpressions)
+	})
+	return selectorTerms, nil
+}
+
+// daemonSetNames returns the names of the default DaemonSet and of the DaemonSets of the profiles, which are also the
+// k8s-app labels of their pods.
+func (d *dpiComponent) daemonSetNames() []string {
+	names := []string{DeepPacketInspectionName}
+	for _, profile := range d.cfg.IntrusionDetection.Spec.DPIProfiles {
+		names = append(names, ProfileDaemonSetName(profile.Name))
+	}
+	return names
+}
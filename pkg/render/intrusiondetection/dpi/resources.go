@@ -0,0 +1,75 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dpi
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	// The share of the allocatable resources of a node that DeepPacketInspection requests, and is limited to, when its
+	// resources are scaled with the node size.
+	scaledRequestPercent = 5
+	scaledLimitPercent   = 25
+
+	// The bounds of the scaled resources.
+	maxScaledCPURequest    = "1"
+	maxScaledMemoryRequest = "1Gi"
+	maxScaledCPULimit      = "4"
+	maxScaledMemoryLimit   = "4Gi"
+
+	mebibyte = 1024 * 1024
+)
+
+// ScaledResourceRequirements returns the DeepPacketInspection resource requirements for a node with the given allocatable
+// resources. The requests and limits are a share of the allocatable resources, bounded below by the default requests
+// and above by fixed maximums. Limits are never lower than requests.
+func ScaledResourceRequirements(allocatable corev1.ResourceList) corev1.ResourceRequirements {
+	cpu := allocatable.Cpu().MilliValue()
+	cpuRequest := clamp(resource.NewMilliQuantity(cpu*scaledRequestPercent/100, resource.DecimalSI),
+		resource.MustParse(DefaultCPURequest), resource.MustParse(maxScaledCPURequest))
+	cpuLimit := clamp(resource.NewMilliQuantity(cpu*scaledLimitPercent/100, resource.DecimalSI),
+		cpuRequest, resource.MustParse(maxScaledCPULimit))
+
+	// Memory is rounded down to whole Mi so that the values are readable in the IntrusionDetection resource.
+	memoryMi := allocatable.Memory().Value() / mebibyte
+	memoryRequest := clamp(resource.NewQuantity(memoryMi*scaledRequestPercent/100*mebibyte, resource.BinarySI),
+		resource.MustParse(DefaultMemoryRequest), resource.MustParse(maxScaledMemoryRequest))
+	memoryLimit := clamp(resource.NewQuantity(memoryMi*scaledLimitPercent/100*mebibyte, resource.BinarySI),
+		memoryRequest, resource.MustParse(maxScaledMemoryLimit))
+
+	return corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    cpuLimit,
+			corev1.ResourceMemory: memoryLimit,
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    cpuRequest,
+			corev1.ResourceMemory: memoryRequest,
+		},
+	}
+}
+
+// clamp returns q bounded to [min, max].
+func clamp(q *resource.Quantity, min, max resource.Quantity) resource.Quantity {
+	if q.Cmp(min) < 0 {
+		return min
+	}
+	if q.Cmp(max) > 0 {
+		return max
+	}
+	return *q
+}
@@ -0,0 +1,41 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dpi_test
+
+import (
+	. "github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
+)
+
+var _ = DescribeTable("Scaled DPI resource requirements",
+	func(cpu, memory, cpuRequest, memoryRequest, cpuLimit, memoryLimit string) {
+		resources := dpi.ScaledResourceRequirements(corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse(memory),
+		})
+		Expect(resources.Requests.Cpu().Cmp(resource.MustParse(cpuRequest))).To(Equal(0), resources.Requests.Cpu().String())
+		Expect(resources.Requests.Memory().Cmp(resource.MustParse(memoryRequest))).To(Equal(0), resources.Requests.Memory().String())
+		Expect(resources.Limits.Cpu().Cmp(resource.MustParse(cpuLimit))).To(Equal(0), resources.Limits.Cpu().String())
+		Expect(resources.Limits.Memory().Cmp(resource.MustParse(memoryLimit))).To(Equal(0), resources.Limits.Memory().String())
+	},
+	Entry("tiny node, bounded below by the default requests", "1", "1Gi", "100m", "100Mi", "250m", "256Mi"),
+	Entry("small node", "4", "8Gi", "200m", "409Mi", "1", "2Gi"),
+	Entry("large node, bounded above by the maximums", "64", "256Gi", "1", "1Gi", "4", "4Gi"),
+)
@@ -0,0 +1,54 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dpi
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+)
+
+// safeSysctls are the sysctls outside the network namespace that Kubernetes considers safe, which the kubelet accepts
+// without them being allowed with --allowed-unsafe-sysctls.
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced": true,
+}
+
+// isNetworkSysctl returns whether the sysctl with the given name is one of the network namespace, which the kubelet
+// rejects for pods that use the host network.
+func isNetworkSysctl(name string) bool {
+	return strings.HasPrefix(name, "net.") || strings.HasPrefix(name, "net/")
+}
+
+// ValidateSysctl returns an error when the kubelet would not run the DeepPacketInspection pods with the sysctl of the
+// given name in their security context.
+func ValidateSysctl(name string) error {
+	if isNetworkSysctl(name) {
+		return fmt.Errorf("%q is a sysctl of the network namespace, which cannot be set on the DeepPacketInspection pods since they use the host network", name)
+	}
+	if !safeSysctls[name] {
+		return fmt.Errorf("%q is not a sysctl that Kubernetes considers safe, which the kubelet does not accept by default", name)
+	}
+	return nil
+}
+
+// podSysctls returns the sysctls of the IntrusionDetection that are set in the security context of the
+// DeepPacketInspection pods.
+func podSysctls(ids *operatorv1.IntrusionDetection) []corev1.Sysctl {
+	return ids.Spec.DPISysctls
+}
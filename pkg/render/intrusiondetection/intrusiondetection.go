@@ -0,0 +1,155 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package intrusiondetection renders all of the components that make up intrusion detection, so that the operator and
+// external tooling produce the same objects for the same configuration.
+package intrusiondetection
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/render"
+	rcertificatemanagement "github.com/tigera/operator/pkg/render/certificatemanagement"
+	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
+)
+
+// Config is the configuration of all of the intrusion detection components of an IntrusionDetection.
+type Config struct {
+	// IntrusionDetection is the configuration of the intrusion detection controller and installer.
+	IntrusionDetection *render.IntrusionDetectionConfiguration
+
+	// DPI is the configuration of DeepPacketInspection. DeepPacketInspection runs cluster wide, so only the default
+	// IntrusionDetection manages it and this is nil for the others.
+	DPI *dpi.DPIConfig
+}
+
+// Components returns the components that make up intrusion detection for the given configuration, in the order the
+// operator applies them.
+func Components(cfg *Config) []render.Component {
+	// The key pairs in the operator namespace and the CSR ClusterRoleBinding are shared by all IntrusionDetections,
+	// so only the default one renders them. An additional one renders its own binding instead.
+	defaultInstance := cfg.IntrusionDetection.Instance == ""
+	var serviceAccounts []string
+	if defaultInstance {
+		serviceAccounts = []string{render.IntrusionDetectionControllerServiceAccountName(&cfg.IntrusionDetection.IntrusionDetection)}
+	}
+	keyPairOptions := []rcertificatemanagement.KeyPairOption{
+		rcertificatemanagement.NewKeyPairOption(cfg.IntrusionDetection.IntrusionDetectionCertSecret, defaultInstance, true),
+	}
+	if cfg.IntrusionDetection.MetricsKeyPair != nil {
+		keyPairOptions = append(keyPairOptions, rcertificatemanagement.NewKeyPairOption(cfg.IntrusionDetection.MetricsKeyPair, defaultInstance, true))
+	}
+	components := []render.Component{
+		render.IntrusionDetection(cfg.IntrusionDetection),
+		rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
+			Namespace:       intrusionDetectionNamespace(cfg.IntrusionDetection),
+			ServiceAccounts: serviceAccounts,
+			KeyPairOptions:  keyPairOptions,
+			TrustedBundle:   cfg.IntrusionDetection.TrustedCertBundle,
+		}),
+	}
+
+	if cfg.DPI != nil {
+		components = append(components,
+			dpi.DPI(cfg.DPI),
+			rcertificatemanagement.CertificateManagement(&rcertificatemanagement.Config{
+				Namespace:       dpi.DeepPacketInspectionNamespace,
+				ServiceAccounts: []string{dpi.ServiceAccountName(cfg.DPI.IntrusionDetection)},
+				KeyPairOptions: []rcertificatemanagement.KeyPairOption{
+					rcertificatemanagement.NewKeyPairOption(cfg.DPI.TyphaNodeTLS.NodeSecret, false, true),
+					rcertificatemanagement.NewKeyPairOption(cfg.DPI.DPICertSecret, true, true),
+				},
+				TrustedBundle: cfg.DPI.TyphaNodeTLS.TrustedBundle,
+			}),
+		)
+	}
+	return components
+}
+
+// Objects returns the objects that the operator creates and deletes for the given configuration, with images resolved
+// from the given ImageSet, or the default images when it is nil. It does not read any cluster state, so tooling can
+// render the objects of an IntrusionDetection without running the operator and diff them against a cluster.
+func Objects(cfg *Config, is *operatorv1.ImageSet) (toCreate, toDelete []client.Object, err error) {
+	var errMsgs []string
+	components := Components(cfg)
+	for _, comp := range components {
+		if err := comp.ResolveImages(is); err != nil {
+			errMsgs = append(errMsgs, err.Error())
+		}
+	}
+	if len(errMsgs) > 0 {
+		return nil, nil, fmt.Errorf("failed to resolve the intrusion detection images: %s", strings.Join(errMsgs, ", "))
+	}
+
+	for _, comp := range components {
+		create, del := comp.Objects()
+		toCreate = append(toCreate, create...)
+		toDelete = append(toDelete, del...)
+	}
+	return toCreate, toDelete, nil
+}
+
+// RedactedValue replaces the value of every key of the Secrets exported by ObjectsYAML.
+const RedactedValue = "<redacted>"
+
+// ObjectsYAML returns the objects that the operator creates for the given configuration as a multi-document YAML
+// stream, for inclusion in support bundles. The data of Secrets is redacted, keeping only the keys.
+func ObjectsYAML(cfg *Config, is *operatorv1.ImageSet) ([]byte, error) {
+	toCreate, _, err := Objects(cfg, is)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	for i, obj := range toCreate {
+		if s, ok := obj.(*corev1.Secret); ok {
+			obj = redactSecret(s)
+		}
+		b, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal %s %s: %w", obj.GetObjectKind().GroupVersionKind().Kind, client.ObjectKeyFromObject(obj), err)
+		}
+		if i > 0 {
+			buf.WriteString("---\n")
+		}
+		buf.Write(b)
+	}
+	return buf.Bytes(), nil
+}
+
+// redactSecret returns a copy of the Secret with the values of all of its keys replaced by RedactedValue.
+func redactSecret(s *corev1.Secret) *corev1.Secret {
+	redacted := s.DeepCopy()
+	for k := range redacted.Data {
+		redacted.Data[k] = []byte(RedactedValue)
+	}
+	for k := range redacted.StringData {
+		redacted.StringData[k] = RedactedValue
+	}
+	return redacted
+}
+
+func intrusionDetectionNamespace(cfg *render.IntrusionDetectionConfiguration) string {
+	if cfg.Namespace != "" {
+		return cfg.Namespace
+	}
+	return render.IntrusionDetectionNamespace
+}
@@ -0,0 +1,187 @@
+// Copyright (c) 2023 Tigera, Inc. All rights reserved.
+
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package intrusiondetection_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	operatorv1 "github.com/tigera/operator/api/v1"
+	"github.com/tigera/operator/pkg/apis"
+	"github.com/tigera/operator/pkg/common"
+	"github.com/tigera/operator/pkg/components"
+	"github.com/tigera/operator/pkg/controller/certificatemanager"
+	"github.com/tigera/operator/pkg/dns"
+	"github.com/tigera/operator/pkg/render"
+	relasticsearch "github.com/tigera/operator/pkg/render/common/elasticsearch"
+	rtest "github.com/tigera/operator/pkg/render/common/test"
+	"github.com/tigera/operator/pkg/render/intrusiondetection"
+	"github.com/tigera/operator/pkg/render/intrusiondetection/dpi"
+)
+
+var _ = Describe("Intrusion detection components", func() {
+	var cfg *intrusiondetection.Config
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(apis.AddToScheme(scheme)).NotTo(HaveOccurred())
+		cli := fake.NewClientBuilder().WithScheme(scheme).Build()
+		certificateManager, err := certificatemanager.Create(cli, nil, dns.DefaultClusterDomain, common.OperatorNamespace(), certificatemanager.AllowCACreation())
+		Expect(err).NotTo(HaveOccurred())
+
+		idsKeyPair, err := certificateManager.GetOrCreateKeyPair(cli, render.IntrusionDetectionTLSSecretName, common.OperatorNamespace(), []string{render.IntrusionDetectionTLSSecretName})
+		Expect(err).NotTo(HaveOccurred())
+		dpiKeyPair, err := certificateManager.GetOrCreateKeyPair(cli, render.DPITLSSecretName, common.OperatorNamespace(), []string{render.IntrusionDetectionTLSSecretName})
+		Expect(err).NotTo(HaveOccurred())
+		nodeKeyPair, err := certificateManager.GetOrCreateKeyPair(cli, render.NodeTLSSecretName, common.OperatorNamespace(), []string{render.FelixCommonName})
+		Expect(err).NotTo(HaveOccurred())
+		typhaKeyPair, err := certificateManager.GetOrCreateKeyPair(cli, render.TyphaTLSSecretName, common.OperatorNamespace(), []string{render.FelixCommonName})
+		Expect(err).NotTo(HaveOccurred())
+
+		ids := &operatorv1.IntrusionDetection{ObjectMeta: metav1.ObjectMeta{Name: "tigera-secure"}}
+		installation := &operatorv1.InstallationSpec{Registry: "testregistry.com/"}
+		esClusterConfig := relasticsearch.NewClusterConfig("clusterTestName", 1, 1, 1)
+		cfg = &intrusiondetection.Config{
+			IntrusionDetection: &render.IntrusionDetectionConfiguration{
+				IntrusionDetection:           *ids,
+				Installation:                 installation,
+				ESClusterConfig:              esClusterConfig,
+				ClusterDomain:                dns.DefaultClusterDomain,
+				ESLicenseType:                render.ElasticsearchLicenseTypeUnknown,
+				TrustedCertBundle:            certificateManager.CreateTrustedBundle(),
+				IntrusionDetectionCertSecret: idsKeyPair,
+			},
+			DPI: &dpi.DPIConfig{
+				IntrusionDetection: ids,
+				Installation:       installation,
+				TyphaNodeTLS: &render.TyphaNodeTLS{
+					TyphaSecret:   typhaKeyPair,
+					NodeSecret:    nodeKeyPair,
+					TrustedBundle: certificateManager.CreateTrustedBundle(nodeKeyPair, typhaKeyPair),
+				},
+				ESClusterConfig: esClusterConfig,
+				ClusterDomain:   dns.DefaultClusterDomain,
+				DPICertSecret:   dpiKeyPair,
+			},
+		}
+	})
+
+	It("should render the same objects every time", func() {
+		toCreate, toDelete, err := intrusiondetection.Objects(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(toCreate).NotTo(BeEmpty())
+
+		for i := 0; i < 3; i++ {
+			again, againDelete, err := intrusiondetection.Objects(cfg, nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(again).To(Equal(toCreate))
+			Expect(againDelete).To(Equal(toDelete))
+		}
+	})
+
+	It("should resolve the images and only render DeepPacketInspection when it is configured", func() {
+		toCreate, _, err := intrusiondetection.Objects(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+		deploy := rtest.GetResource(toCreate, render.IntrusionDetectionName, render.IntrusionDetectionNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+		Expect(deploy.Spec.Template.Spec.Containers[0].Image).To(ContainSubstring(components.ComponentIntrusionDetectionController.Image))
+		ds := rtest.GetResource(toCreate, dpi.DeepPacketInspectionName, dpi.DeepPacketInspectionNamespace, "apps", "v1", "DaemonSet").(*appsv1.DaemonSet)
+		Expect(ds.Spec.Template.Spec.Containers[0].Image).To(ContainSubstring(components.ComponentDeepPacketInspection.Image))
+
+		cfg.DPI = nil
+		toCreate, toDelete, err := intrusiondetection.Objects(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+		for _, obj := range append(toCreate, toDelete...) {
+			Expect(obj.GetNamespace()).NotTo(Equal(dpi.DeepPacketInspectionNamespace), client.ObjectKeyFromObject(obj).String())
+		}
+	})
+
+	It("should leave the shared key pair and CSR binding to the default IntrusionDetection", func() {
+		cfg.IntrusionDetection.Instance = "tenant-a"
+		cfg.IntrusionDetection.Namespace = "tigera-intrusion-detection-tenant-a"
+		cfg.DPI = nil
+		toCreate, _, err := intrusiondetection.Objects(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(rtest.GetResource(toCreate, render.IntrusionDetectionTLSSecretName, common.OperatorNamespace(), "", "v1", "Secret")).To(BeNil())
+		Expect(rtest.GetResource(toCreate, render.IntrusionDetectionTLSSecretName, "tigera-intrusion-detection-tenant-a", "", "v1", "Secret")).NotTo(BeNil())
+		for _, obj := range toCreate {
+			Expect(obj.GetName()).NotTo(Equal(render.IntrusionDetectionName+":csr-creator"), client.ObjectKeyFromObject(obj).String())
+		}
+	})
+
+	It("should bind the configured ServiceAccounts to the CSR ClusterRole", func() {
+		ids := cfg.IntrusionDetection.IntrusionDetection.DeepCopy()
+		ids.Spec.ServiceAccounts = &operatorv1.IntrusionDetectionServiceAccounts{Controller: "ids-controller", DeepPacketInspection: "ids-dpi"}
+		cfg.IntrusionDetection.IntrusionDetection = *ids
+		cfg.DPI.IntrusionDetection = ids
+		_, toDelete, err := intrusiondetection.Objects(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(rtest.GetResource(toDelete, "ids-controller:csr-creator", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, "ids-dpi:csr-creator", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding")).NotTo(BeNil())
+		Expect(rtest.GetResource(toDelete, render.IntrusionDetectionName+":csr-creator", "", "rbac.authorization.k8s.io", "v1", "ClusterRoleBinding")).To(BeNil())
+	})
+
+	It("should export the objects as YAML that decodes back into the same objects with the Secret data redacted", func() {
+		toCreate, _, err := intrusiondetection.Objects(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+		out, err := intrusiondetection.ObjectsYAML(cfg, nil)
+		Expect(err).NotTo(HaveOccurred())
+
+		var decoded []*unstructured.Unstructured
+		decoder := yaml.NewYAMLOrJSONDecoder(bytes.NewReader(out), 4096)
+		for {
+			obj := &unstructured.Unstructured{}
+			if err := decoder.Decode(&obj.Object); err != nil {
+				Expect(errors.Is(err, io.EOF)).To(BeTrue(), err.Error())
+				break
+			}
+			decoded = append(decoded, obj)
+		}
+		Expect(decoded).To(HaveLen(len(toCreate)))
+
+		secrets := 0
+		for i, obj := range toCreate {
+			gvk := obj.GetObjectKind().GroupVersionKind()
+			Expect(decoded[i].GroupVersionKind()).To(Equal(gvk))
+			Expect(decoded[i].GetName()).To(Equal(obj.GetName()))
+			Expect(decoded[i].GetNamespace()).To(Equal(obj.GetNamespace()))
+			if gvk.Kind != "Secret" {
+				continue
+			}
+			secrets++
+			data, _, err := unstructured.NestedStringMap(decoded[i].Object, "data")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).NotTo(BeEmpty())
+			for k, v := range data {
+				// Secret data is base64 encoded.
+				Expect(v).To(Equal("PHJlZGFjdGVkPg=="), k)
+			}
+		}
+		Expect(secrets).NotTo(BeZero())
+	})
+})
@@ -384,6 +384,11 @@ func (l *linseed) linseedDeployment() *appsv1.Deployment {
 		envVars = append(envVars, corev1.EnvVar{Name: "ELASTIC_CLIENT_CERT", Value: "/certs/elasticsearch/mtls/client.crt"})
 	}
 
+	if prefix := l.cfg.ESClusterConfig.IndexPrefix(); prefix != "" {
+		// Read and write the same indices as the other components of the cluster.
+		envVars = append(envVars, corev1.EnvVar{Name: "ELASTIC_INDEX_PREFIX", Value: prefix})
+	}
+
 	if l.cfg.ManagementCluster {
 		envVars = append(envVars,
 			corev1.EnvVar{Name: "MANAGEMENT_OPERATOR_NS", Value: common.OperatorNamespace()},
@@ -334,6 +334,27 @@ var _ = Describe("Linseed rendering tests", func() {
 			Expect(ok).To(BeTrue(), "Deployment not found")
 			Expect(d.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "LINSEED_FIPS_MODE_ENABLED", Value: "true"}))
 		})
+
+		It("should set the index prefix of the cluster config", func() {
+			kp, tokenKP, bundle := getTLS(installation)
+			component := Linseed(&Config{
+				Installation:    installation,
+				KeyPair:         kp,
+				TokenKeyPair:    tokenKP,
+				TrustedBundle:   bundle,
+				ClusterDomain:   clusterDomain,
+				ESClusterConfig: relasticsearch.NewClusterConfig("", 1, 1, 1).WithIndexPrefix("cluster-a"),
+				Namespace:       render.ElasticsearchNamespace,
+				BindNamespaces:  []string{render.ElasticsearchNamespace},
+				ElasticHost:     "tigera-secure-es-http.tigera-elasticsearch.svc",
+				ElasticPort:     "9200",
+			})
+
+			resources, _ := component.Objects()
+			d, ok := rtest.GetResource(resources, DeploymentName, render.ElasticsearchNamespace, "apps", "v1", "Deployment").(*appsv1.Deployment)
+			Expect(ok).To(BeTrue(), "Deployment not found")
+			Expect(d.Spec.Template.Spec.Containers[0].Env).To(ContainElement(corev1.EnvVar{Name: "ELASTIC_INDEX_PREFIX", Value: "cluster-a"}))
+		})
 	})
 
 	Context("multi-tenant rendering", func() {
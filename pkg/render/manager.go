@@ -279,6 +279,8 @@ func (c *managerComponent) managerDeployment() *appsv1.Deployment {
 		// If we're running in multi-tenant mode, we don't need ES credentials as these are used for Kibana login. Otherwise, add them.
 		managerContainer = relasticsearch.ContainerDecorate(managerContainer, c.cfg.ClusterConfig.ClusterName(), ElasticsearchManagerUserSecret, c.cfg.ClusterDomain, c.SupportedOSType())
 		esProxyContainer = relasticsearch.ContainerDecorate(esProxyContainer, c.cfg.ClusterConfig.ClusterName(), ElasticsearchManagerUserSecret, c.cfg.ClusterDomain, c.SupportedOSType())
+		managerContainer = relasticsearch.ContainerDecorateIndexPrefix(managerContainer, c.cfg.ClusterConfig.IndexPrefix())
+		esProxyContainer = relasticsearch.ContainerDecorateIndexPrefix(esProxyContainer, c.cfg.ClusterConfig.IndexPrefix())
 	}
 	if c.cfg.InternalTLSKeyPair != nil && c.cfg.InternalTLSKeyPair.UseCertificateManagement() {
 		initContainers = append(initContainers, c.cfg.InternalTLSKeyPair.InitContainer(ManagerNamespace))
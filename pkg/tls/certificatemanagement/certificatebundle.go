@@ -16,6 +16,8 @@ package certificatemanagement
 
 import (
 	"bytes"
+	"crypto/x509"
+	"encoding/pem"
 	"fmt"
 	"os"
 	"path"
@@ -202,6 +204,33 @@ func (t *trustedBundle) ConfigMap(namespace string) *corev1.ConfigMap {
 	}
 }
 
+// ParseCertificateBundle splits a PEM encoded bundle into its certificates, so that they can be added to a trusted
+// bundle. The certificates are named after the bundle and their position in it. An error is returned if the bundle
+// contains no certificates, or if any of its PEM blocks is not a valid certificate.
+func ParseCertificateBundle(name, ns string, bundle []byte) ([]CertificateInterface, error) {
+	var certificates []CertificateInterface
+	rest := bundle
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			return nil, fmt.Errorf("bundle %s/%s contains an unexpected PEM block of type %q", ns, name, block.Type)
+		}
+		if _, err := x509.ParseCertificate(block.Bytes); err != nil {
+			return nil, fmt.Errorf("bundle %s/%s contains an invalid certificate: %w", ns, name, err)
+		}
+		certName := fmt.Sprintf("%s-%d", name, len(certificates))
+		certificates = append(certificates, NewCertificate(certName, ns, pem.EncodeToMemory(block), nil))
+	}
+	if len(certificates) == 0 {
+		return nil, fmt.Errorf("bundle %s/%s: %w", ns, name, ErrInvalidCertNoPEMData)
+	}
+	return certificates, nil
+}
+
 // NewCertificate creates a new certificate.
 func NewCertificate(name, ns string, pem []byte, issuer CertificateInterface) CertificateInterface {
 	return &certificate{name: name, namespace: ns, pem: pem, issuer: issuer}
@@ -2,7 +2,9 @@ package url
 
 import (
 	"fmt"
+	"net"
 	"net/url"
+	"strconv"
 	"strings"
 )
 
@@ -18,3 +20,51 @@ func ParseEndpoint(endpoint string) (string, string, string, error) {
 	}
 	return url.Scheme, splits[0], splits[1], nil
 }
+
+// NormalizeURL validates an endpoint given either as host:port or as a full URL, and returns it in the canonical form
+// scheme://host:port[/path]. An endpoint without a scheme is assumed to use https, and an endpoint without a port uses
+// the default port of its scheme.
+func NormalizeURL(endpoint string) (string, error) {
+	endpoint = strings.TrimSpace(endpoint)
+	if endpoint == "" {
+		return "", fmt.Errorf("endpoint is empty")
+	}
+	if !strings.Contains(endpoint, "://") {
+		endpoint = "https://" + endpoint
+	}
+
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid endpoint %q: %w", endpoint, err)
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	var defaultPort string
+	switch scheme {
+	case "https":
+		defaultPort = "443"
+	case "http":
+		defaultPort = "80"
+	default:
+		return "", fmt.Errorf("invalid endpoint %q: scheme must be http or https", endpoint)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("invalid endpoint %q: host is missing", endpoint)
+	}
+	if u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid endpoint %q: credentials, queries and fragments are not supported", endpoint)
+	}
+
+	port := u.Port()
+	if port == "" {
+		if strings.HasSuffix(u.Host, ":") {
+			return "", fmt.Errorf("invalid endpoint %q: port is empty", endpoint)
+		}
+		port = defaultPort
+	}
+	if p, err := strconv.Atoi(port); err != nil || p < 1 || p > 65535 {
+		return "", fmt.Errorf("invalid endpoint %q: port must be between 1 and 65535", endpoint)
+	}
+
+	return fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort(strings.ToLower(u.Hostname()), port), strings.TrimSuffix(u.Path, "/")), nil
+}